@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/kavehjamshidi/fidibo-challenge/service"
+)
+
+const maxBookParamLength = 200
+
+type BookController interface {
+	GetByID(c *gin.Context)
+	GetBySlug(c *gin.Context)
+}
+
+type bookController struct {
+	svc    service.BookService
+	maxAge *live.Duration
+}
+
+func (b *bookController) GetByID(c *gin.Context) {
+	id, fieldErr := validatePathParam("id", c.Param("id"), maxBookParamLength)
+	if fieldErr != nil {
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{Message: invalidRequestMessage, Errors: []domain.FieldError{*fieldErr}})
+		return
+	}
+
+	b.get(c, domain.BookLookup{ID: id})
+}
+
+func (b *bookController) GetBySlug(c *gin.Context) {
+	slug, fieldErr := validatePathParam("slug", c.Param("slug"), maxBookParamLength)
+	if fieldErr != nil {
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{Message: invalidRequestMessage, Errors: []domain.FieldError{*fieldErr}})
+		return
+	}
+
+	b.get(c, domain.BookLookup{Slug: slug})
+}
+
+func (b *bookController) get(c *gin.Context, lookup domain.BookLookup) {
+	book, err := b.svc.Get(c, lookup)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	writeCacheableJSON(c, book, time.Time{}, b.maxAge.Load())
+}
+
+func NewBookController(svc service.BookService, maxAge *live.Duration) BookController {
+	return &bookController{
+		svc:    svc,
+		maxAge: maxAge,
+	}
+}
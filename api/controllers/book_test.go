@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch"
+	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBook(t *testing.T) {
+	t.Run("by id", func(t *testing.T) {
+		svcMock := &mocks.BookService{}
+		bookController := NewBookController(svcMock, live.NewDuration(10*time.Minute))
+
+		expectedBook := domain.Book{ID: "123", Title: "test title", Slug: "test"}
+		expectedJSONResponse, err := json.Marshal(expectedBook)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/books/123", nil)
+		c.Params = gin.Params{{Key: "id", Value: "123"}}
+
+		svcMock.On("Get", c, domain.BookLookup{ID: "123"}).Return(expectedBook, nil)
+
+		bookController.GetByID(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "private, max-age=600", w.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		assert.JSONEq(t, string(expectedJSONResponse), string(res))
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("by slug", func(t *testing.T) {
+		svcMock := &mocks.BookService{}
+		bookController := NewBookController(svcMock, live.NewDuration(10*time.Minute))
+
+		expectedBook := domain.Book{ID: "123", Title: "test title", Slug: "test"}
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/books/by-slug/test", nil)
+		c.Params = gin.Params{{Key: "slug", Value: "test"}}
+
+		svcMock.On("Get", c, domain.BookLookup{Slug: "test"}).Return(expectedBook, nil)
+
+		bookController.GetBySlug(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("invalid parameter", func(t *testing.T) {
+		for _, id := range []string{" ", "12\x003", strings.Repeat("1", 201)} {
+			svcMock := &mocks.BookService{}
+			bookController := NewBookController(svcMock, live.NewDuration(10*time.Minute))
+
+			w := httptest.NewRecorder()
+
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/books/x", nil)
+			c.Params = gin.Params{{Key: "id", Value: id}}
+
+			bookController.GetByID(c)
+
+			res, err := io.ReadAll(w.Body)
+			assert.NoError(t, err)
+
+			response := domain.ErrorResponse{}
+			err = json.Unmarshal(res, &response)
+			assert.NoError(t, err)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, "id", response.Errors[0].Field)
+			svcMock.AssertExpectations(t)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			err        error
+			statusCode int
+			retryAfter string
+		}{
+			{domain.ErrBookNotFound, http.StatusNotFound, ""},
+			{fmt.Errorf("service unavailable: %w", &fidibosearch.CircuitOpenError{RetryAfter: 5 * time.Second}), http.StatusServiceUnavailable, "5"},
+			{fmt.Errorf("service unavailable: %w", &fidibosearch.UpstreamTimeoutError{}), http.StatusGatewayTimeout, ""},
+		}
+
+		for _, tt := range tests {
+			svcMock := &mocks.BookService{}
+			bookController := NewBookController(svcMock, live.NewDuration(10*time.Minute))
+
+			w := httptest.NewRecorder()
+
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/books/123", nil)
+			c.Params = gin.Params{{Key: "id", Value: "123"}}
+
+			svcMock.On("Get", c, domain.BookLookup{ID: "123"}).Return(domain.Book{}, tt.err)
+
+			bookController.GetByID(c)
+
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+			svcMock.AssertExpectations(t)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/kavehjamshidi/fidibo-challenge/service"
+)
+
+const maxCatalogNameLength = 100
+
+type CatalogController interface {
+	AuthorBooks(c *gin.Context)
+	PublisherBooks(c *gin.Context)
+}
+
+type catalogController struct {
+	svc    service.CatalogService
+	maxAge *live.Duration
+}
+
+func (cc *catalogController) AuthorBooks(c *gin.Context) {
+	req, ok := cc.bindRequest(c, "name")
+	if !ok {
+		return
+	}
+
+	res, err := cc.svc.AuthorBooks(c, req)
+	cc.writeResponse(c, res, err)
+}
+
+func (cc *catalogController) PublisherBooks(c *gin.Context) {
+	req, ok := cc.bindRequest(c, "title")
+	if !ok {
+		return
+	}
+
+	res, err := cc.svc.PublisherBooks(c, req)
+	cc.writeResponse(c, res, err)
+}
+
+func (cc *catalogController) bindRequest(c *gin.Context, param string) (domain.CatalogRequest, bool) {
+	var req domain.CatalogRequest
+
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, bindingErrorResponse(err, req))
+		return req, false
+	}
+
+	name, fieldErr := validatePathParam(param, c.Param(param), maxCatalogNameLength)
+	if fieldErr != nil {
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{Message: invalidRequestMessage, Errors: []domain.FieldError{*fieldErr}})
+		return req, false
+	}
+	req.Name = name
+
+	if req.Page == 0 {
+		req.Page = defaultPage
+	}
+	if req.Size == 0 {
+		req.Size = defaultPageSize
+	}
+	return req, true
+}
+
+func (cc *catalogController) writeResponse(c *gin.Context, res domain.SearchResult, err error) {
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	maxAge := cc.maxAge.Load()
+	if !res.FetchedAt.IsZero() {
+		maxAge -= time.Since(res.FetchedAt)
+	}
+	writeCacheableJSON(c, res, res.FetchedAt, maxAge)
+}
+
+func NewCatalogController(svc service.CatalogService, maxAge *live.Duration) CatalogController {
+	return &catalogController{
+		svc:    svc,
+		maxAge: maxAge,
+	}
+}
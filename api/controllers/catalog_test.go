@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorBooks(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svcMock := &mocks.CatalogService{}
+		catalogController := NewCatalogController(svcMock, live.NewDuration(10*time.Minute))
+
+		expectedResult := domain.SearchResult{
+			Books: []domain.Book{{ID: "1", Authors: []domain.Author{{Name: "Franz Kafka"}}}},
+			Total: 3,
+			Page:  2,
+			Size:  1,
+		}
+		expectedJSONResponse, err := json.Marshal(expectedResult)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/authors/Franz%20Kafka/books?page=2&size=1", nil)
+		c.Params = gin.Params{{Key: "name", Value: " Franz  Kafka"}}
+
+		svcMock.On("AuthorBooks", c, domain.CatalogRequest{Name: "Franz Kafka", Page: 2, Size: 1}).Return(expectedResult, nil)
+
+		catalogController.AuthorBooks(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, string(expectedJSONResponse), string(res))
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("default pagination", func(t *testing.T) {
+		svcMock := &mocks.CatalogService{}
+		catalogController := NewCatalogController(svcMock, live.NewDuration(10*time.Minute))
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/authors/kafka/books", nil)
+		c.Params = gin.Params{{Key: "name", Value: "kafka"}}
+
+		svcMock.On("AuthorBooks", c, domain.CatalogRequest{Name: "kafka", Page: 1, Size: 20}).Return(domain.SearchResult{}, nil)
+
+		catalogController.AuthorBooks(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			query string
+			field string
+		}{
+			{" ", "", "name"},
+			{"kafka", "?size=51", "size"},
+			{"kafka", "?page=-1", "page"},
+		}
+
+		for _, tt := range tests {
+			svcMock := &mocks.CatalogService{}
+			catalogController := NewCatalogController(svcMock, live.NewDuration(10*time.Minute))
+
+			w := httptest.NewRecorder()
+
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/authors/x/books"+tt.query, nil)
+			c.Params = gin.Params{{Key: "name", Value: tt.name}}
+
+			catalogController.AuthorBooks(c)
+
+			res, err := io.ReadAll(w.Body)
+			assert.NoError(t, err)
+
+			response := domain.ErrorResponse{}
+			err = json.Unmarshal(res, &response)
+			assert.NoError(t, err)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, tt.field, response.Errors[0].Field)
+			svcMock.AssertExpectations(t)
+		}
+	})
+}
+
+func TestPublisherBooks(t *testing.T) {
+	t.Run("service error", func(t *testing.T) {
+		svcMock := &mocks.CatalogService{}
+		catalogController := NewCatalogController(svcMock, live.NewDuration(10*time.Minute))
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/publishers/cheshmeh/books", nil)
+		c.Params = gin.Params{{Key: "title", Value: "cheshmeh"}}
+
+		svcMock.On("PublisherBooks", c, domain.CatalogRequest{Name: "cheshmeh", Page: 1, Size: 20}).Return(domain.SearchResult{}, errors.New("internal error"))
+
+		catalogController.PublisherBooks(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		svcMock.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	swaggerUICSS    = "https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui.css"
+	swaggerUIBundle = "https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui-bundle.js"
+	swaggerUIScript = `window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });`
+)
+
+// swaggerUIPage renders the document served on /openapi.json with the Swagger UI bundle from unpkg.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Fidibo Challenge API</title>
+  <link rel="stylesheet" href="` + swaggerUICSS + `" crossorigin="anonymous" referrerpolicy="no-referrer">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="` + swaggerUIBundle + `" crossorigin="anonymous" referrerpolicy="no-referrer"></script>
+  <script>` + swaggerUIScript + `</script>
+</body>
+</html>
+`
+
+// swaggerUIPolicy only lets the page load the pinned Swagger UI files and run its own inline script, and only
+// lets it call this service.
+var swaggerUIPolicy = "default-src 'none'; script-src " + swaggerUIBundle + " 'sha256-" + scriptHash(swaggerUIScript) + "'; " +
+	"style-src " + swaggerUICSS + " 'unsafe-inline'; img-src 'self' data:; connect-src 'self'; " +
+	"base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+func scriptHash(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+type DocsController interface {
+	OpenAPI(c *gin.Context)
+	SwaggerUI(c *gin.Context)
+}
+
+type docsController struct {
+	spec []byte
+}
+
+func (d *docsController) OpenAPI(c *gin.Context) {
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, jsonContentType, d.spec)
+}
+
+func (d *docsController) SwaggerUI(c *gin.Context) {
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Content-Security-Policy", swaggerUIPolicy)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+func NewDocsController(spec []byte) DocsController {
+	return &docsController{
+		spec: spec,
+	}
+}
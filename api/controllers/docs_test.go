@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocs(t *testing.T) {
+	docsController := NewDocsController([]byte(`{"openapi":"3.0.3"}`))
+	gin.SetMode(gin.TestMode)
+
+	t.Run("openapi document", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+
+		docsController.OpenAPI(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, jsonContentType, w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"openapi":"3.0.3"}`, w.Body.String())
+	})
+
+	t.Run("swagger ui", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/docs", nil)
+
+		docsController.SwaggerUI(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `url: "/openapi.json"`)
+		assert.Contains(t, w.Body.String(), "<script>"+swaggerUIScript+"</script>")
+
+		policy := w.Header().Get("Content-Security-Policy")
+		assert.Contains(t, policy, "script-src https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui-bundle.js 'sha256-"+scriptHash(swaggerUIScript)+"'")
+		assert.Contains(t, policy, "default-src 'none'")
+	})
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/service"
+)
+
+type FavoriteController interface {
+	Add(c *gin.Context)
+	Remove(c *gin.Context)
+	List(c *gin.Context)
+}
+
+type favoriteController struct {
+	svc service.FavoriteService
+}
+
+func (f *favoriteController) Add(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req domain.FavoriteRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, bindingErrorResponse(err, req))
+		return
+	}
+	bookID, fieldErr := validatePathParam("book_id", req.BookID, maxBookParamLength)
+	if fieldErr != nil {
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{Message: invalidRequestMessage, Errors: []domain.FieldError{*fieldErr}})
+		return
+	}
+
+	res, err := f.svc.Add(c, username, bookID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+func (f *favoriteController) Remove(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	bookID, fieldErr := validatePathParam("bookId", c.Param("bookId"), maxBookParamLength)
+	if fieldErr != nil {
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{Message: invalidRequestMessage, Errors: []domain.FieldError{*fieldErr}})
+		return
+	}
+
+	err := f.svc.Remove(c, username, bookID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow()
+}
+
+func (f *favoriteController) List(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	res, err := f.svc.List(c, username)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, res)
+}
+
+func NewFavoriteController(svc service.FavoriteService) FavoriteController {
+	return &favoriteController{
+		svc: svc,
+	}
+}
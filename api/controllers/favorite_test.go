@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddFavorite(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svcMock := &mocks.FavoriteService{}
+		favoriteController := NewFavoriteController(svcMock)
+
+		expectedFavorite := domain.Favorite{Book: domain.Book{ID: "123"}, AddedAt: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)}
+		expectedJSONResponse, err := json.Marshal(expectedFavorite)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodPost, "/me/favorites", `{"book_id": " 123 "}`)
+
+		svcMock.On("Add", c, "test", "123").Return(expectedFavorite, nil)
+
+		favoriteController.Add(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.JSONEq(t, string(expectedJSONResponse), string(res))
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("missing book id", func(t *testing.T) {
+		svcMock := &mocks.FavoriteService{}
+		favoriteController := NewFavoriteController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodPost, "/me/favorites", `{}`)
+
+		favoriteController.Add(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		response := domain.ErrorResponse{}
+		err = json.Unmarshal(res, &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "book_id", response.Errors[0].Field)
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			err        error
+			statusCode int
+		}{
+			{domain.ErrBookNotFound, http.StatusNotFound},
+			{domain.ErrFavoriteExists, http.StatusConflict},
+			{domain.ErrFavoritesLimitReached, http.StatusConflict},
+			{errors.New("redis error"), http.StatusInternalServerError},
+		}
+
+		for _, tt := range tests {
+			svcMock := &mocks.FavoriteService{}
+			favoriteController := NewFavoriteController(svcMock)
+
+			w := httptest.NewRecorder()
+
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+			c.Request = newUserRequest(http.MethodPost, "/me/favorites", `{"book_id": "123"}`)
+
+			svcMock.On("Add", c, "test", "123").Return(domain.Favorite{}, tt.err)
+
+			favoriteController.Add(c)
+
+			assert.Equal(t, tt.statusCode, w.Code, tt.err.Error())
+			svcMock.AssertExpectations(t)
+		}
+	})
+}
+
+func TestRemoveFavorite(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svcMock := &mocks.FavoriteService{}
+		favoriteController := NewFavoriteController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodDelete, "/me/favorites/123", "")
+		c.Params = gin.Params{{Key: "bookId", Value: "123"}}
+
+		svcMock.On("Remove", c, "test", "123").Return(nil)
+
+		favoriteController.Remove(c)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svcMock := &mocks.FavoriteService{}
+		favoriteController := NewFavoriteController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodDelete, "/me/favorites/123", "")
+		c.Params = gin.Params{{Key: "bookId", Value: "123"}}
+
+		svcMock.On("Remove", c, "test", "123").Return(domain.ErrFavoriteNotFound)
+
+		favoriteController.Remove(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		svcMock.AssertExpectations(t)
+	})
+}
+
+func TestListFavorites(t *testing.T) {
+	svcMock := &mocks.FavoriteService{}
+	favoriteController := NewFavoriteController(svcMock)
+
+	expectedResponse := domain.FavoritesResponse{Favorites: []domain.Favorite{{Book: domain.Book{ID: "123"}}}}
+
+	w := httptest.NewRecorder()
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newUserRequest(http.MethodGet, "/me/favorites", "")
+
+	svcMock.On("List", c, "test").Return(expectedResponse, nil)
+
+	favoriteController.List(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	svcMock.AssertExpectations(t)
+}
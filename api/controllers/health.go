@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/service"
+)
+
+type HealthController interface {
+	Live(c *gin.Context)
+	Ready(c *gin.Context)
+}
+
+type healthController struct {
+	svc service.HealthService
+}
+
+func (h *healthController) Live(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, h.svc.Live(c))
+}
+
+func (h *healthController) Ready(c *gin.Context) {
+	res, ready := h.svc.Ready(c)
+
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, res)
+}
+
+func NewHealthController(svc service.HealthService) HealthController {
+	return &healthController{
+		svc: svc,
+	}
+}
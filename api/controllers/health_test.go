@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLive(t *testing.T) {
+	svcMock := &mocks.HealthService{}
+	healthController := NewHealthController(svcMock)
+
+	w := httptest.NewRecorder()
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+
+	svcMock.On("Live", c).Return(domain.HealthResponse{Status: domain.HealthStatusUp})
+
+	healthController.Live(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"status":"up"}`, w.Body.String())
+	svcMock.AssertExpectations(t)
+}
+
+func TestReady(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		svcMock := &mocks.HealthService{}
+		healthController := NewHealthController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+		svcMock.On("Ready", c).Return(domain.HealthResponse{
+			Status: domain.HealthStatusUp,
+			Checks: map[string]domain.DependencyHealth{"redis": {Status: domain.HealthStatusUp, LatencyMS: 0.5}},
+		}, true)
+
+		healthController.Ready(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"up","checks":{"redis":{"status":"up","latency_ms":0.5}}}`, w.Body.String())
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		svcMock := &mocks.HealthService{}
+		healthController := NewHealthController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+		svcMock.On("Ready", c).Return(domain.HealthResponse{Status: domain.HealthStatusDraining}, false)
+
+		healthController.Ready(c)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.JSONEq(t, `{"status":"draining"}`, w.Body.String())
+		svcMock.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/service"
+)
+
+type HistoryController interface {
+	List(c *gin.Context)
+	Clear(c *gin.Context)
+	SetPreference(c *gin.Context)
+}
+
+type historyController struct {
+	svc service.HistoryService
+}
+
+func (h *historyController) List(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.svc.List(c, username)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *historyController) Clear(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	err := h.svc.Clear(c, username)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow()
+}
+
+func (h *historyController) SetPreference(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SearchHistoryPreference
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, bindingErrorResponse(err, req))
+		return
+	}
+
+	err = h.svc.SetEnabled(c, username, *req.Enabled)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+func NewHistoryController(svc service.HistoryService) HistoryController {
+	return &historyController{
+		svc: svc,
+	}
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/user"
+	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func newUserRequest(method, target, body string) *http.Request {
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, r)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req.WithContext(user.WithUsername(req.Context(), "test"))
+}
+
+func TestListHistory(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svcMock := &mocks.HistoryService{}
+		historyController := NewHistoryController(svcMock)
+
+		expectedResponse := domain.SearchHistory{
+			Enabled: true,
+			Entries: []domain.SearchHistoryEntry{{Query: "test", ResultCount: 2, SearchedAt: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)}},
+		}
+		expectedJSONResponse, err := json.Marshal(expectedResponse)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodGet, "/me/search-history", "")
+
+		svcMock.On("List", c, "test").Return(expectedResponse, nil)
+
+		historyController.List(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.JSONEq(t, string(expectedJSONResponse), string(res))
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("no user", func(t *testing.T) {
+		svcMock := &mocks.HistoryService{}
+		historyController := NewHistoryController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/me/search-history", nil)
+
+		historyController.List(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		svcMock := &mocks.HistoryService{}
+		historyController := NewHistoryController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodGet, "/me/search-history", "")
+
+		svcMock.On("List", c, "test").Return(domain.SearchHistory{}, errors.New("redis error"))
+
+		historyController.List(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "redis error")
+		assert.Len(t, c.Errors, 1)
+		svcMock.AssertExpectations(t)
+	})
+}
+
+func TestClearHistory(t *testing.T) {
+	svcMock := &mocks.HistoryService{}
+	historyController := NewHistoryController(svcMock)
+
+	w := httptest.NewRecorder()
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newUserRequest(http.MethodDelete, "/me/search-history", "")
+
+	svcMock.On("Clear", c, "test").Return(nil)
+
+	historyController.Clear(c)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	svcMock.AssertExpectations(t)
+}
+
+func TestSetHistoryPreference(t *testing.T) {
+	t.Run("opt out", func(t *testing.T) {
+		svcMock := &mocks.HistoryService{}
+		historyController := NewHistoryController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodPut, "/me/search-history/preference", `{"enabled": false}`)
+
+		svcMock.On("SetEnabled", c, "test", false).Return(nil)
+
+		historyController.SetPreference(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"enabled": false}`, string(res))
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("missing preference", func(t *testing.T) {
+		svcMock := &mocks.HistoryService{}
+		historyController := NewHistoryController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodPut, "/me/search-history/preference", `{}`)
+
+		historyController.SetPreference(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		response := domain.ErrorResponse{}
+		err = json.Unmarshal(res, &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "enabled", response.Errors[0].Field)
+		svcMock.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const jsonContentType = "application/json; charset=utf-8"
+
+// writeCacheableJSON writes body with validators derived from its content and answers conditional
+// GET and HEAD requests with 304 Not Modified when the client's copy is still current.
+func writeCacheableJSON(c *gin.Context, body interface{}, lastModified time.Time, maxAge time.Duration) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	etag := fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:16]))
+
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds())))
+	c.Header("Vary", "Authorization")
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	c.Data(http.StatusOK, jsonContentType, data)
+}
+
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
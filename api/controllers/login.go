@@ -22,13 +22,13 @@ func (l *loginController) Login(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	res, err := l.svc.Login(req)
+	res, err := l.svc.Login(c, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		writeServiceError(c, err)
 		return
 	}
 
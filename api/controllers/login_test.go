@@ -3,6 +3,7 @@ package controllers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -33,8 +34,6 @@ func TestLogin(t *testing.T) {
 		expectedJSONResponse, err := json.Marshal(expectedResponse)
 		assert.NoError(t, err)
 
-		svcMock.On("Login", requestData).Return(expectedResponse, nil)
-
 		w := httptest.NewRecorder()
 
 		gin.SetMode(gin.TestMode)
@@ -44,6 +43,8 @@ func TestLogin(t *testing.T) {
 		c.Request.Header.Set("Content-Type", "application/json")
 		c.Request.Body = io.NopCloser(bytes.NewBuffer(jsonData))
 
+		svcMock.On("Login", c, requestData).Return(expectedResponse, nil)
+
 		loginController.Login(c)
 
 		res, err := io.ReadAll(w.Body)
@@ -87,4 +88,38 @@ func TestLogin(t *testing.T) {
 		assert.NotEmpty(t, response.Message)
 		svcMock.AssertExpectations(t)
 	})
+
+	t.Run("service error", func(t *testing.T) {
+		svcMock := &mocks.LoginService{}
+		loginController := NewLoginController(svcMock)
+
+		requestData := domain.LoginRequest{
+			Username: "test",
+			Password: "test",
+		}
+		jsonData, err := json.Marshal(requestData)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = &http.Request{Header: make(http.Header)}
+		c.Request.Method = http.MethodPost
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(jsonData))
+
+		svcMock.On("Login", c, requestData).Return(domain.LoginResponse{}, errors.New("could not sign token: key is invalid"))
+
+		loginController.Login(c)
+
+		response := domain.ErrorResponse{}
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, http.StatusText(http.StatusInternalServerError), response.Message)
+		assert.Len(t, c.Errors, 1)
+		svcMock.AssertExpectations(t)
+	})
 }
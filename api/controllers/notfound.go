@@ -15,7 +15,7 @@ type NotFoundController interface {
 type notFoundController struct{}
 
 func (n *notFoundController) NotFound(c *gin.Context) {
-	c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Not Found"})
+	writeError(c, http.StatusNotFound, domain.ErrorResponse{Message: "Not Found"})
 }
 
 func NewNotFoundController() NotFoundController {
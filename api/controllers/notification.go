@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/service"
+)
+
+type NotificationController interface {
+	List(c *gin.Context)
+}
+
+type notificationController struct {
+	svc service.NotificationService
+}
+
+func (n *notificationController) List(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	res, err := n.svc.List(c, username)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, res)
+}
+
+func NewNotificationController(svc service.NotificationService) NotificationController {
+	return &notificationController{
+		svc: svc,
+	}
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListNotifications(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svcMock := &mocks.NotificationService{}
+		notificationController := NewNotificationController(svcMock)
+
+		expectedResponse := domain.NotificationsResponse{Notifications: []domain.Notification{{ID: "1", SavedSearchID: "abc", NewBooks: []domain.Book{{ID: "123"}}}}}
+		expectedJSONResponse, err := json.Marshal(expectedResponse)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodGet, "/me/notifications", "")
+
+		svcMock.On("List", c, "test").Return(expectedResponse, nil)
+
+		notificationController.List(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.JSONEq(t, string(expectedJSONResponse), string(res))
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		svcMock := &mocks.NotificationService{}
+		notificationController := NewNotificationController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodGet, "/me/notifications", "")
+
+		svcMock.On("List", c, "test").Return(domain.NotificationsResponse{}, errors.New("redis error"))
+
+		notificationController.List(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "redis error")
+		assert.Len(t, c.Errors, 1)
+		svcMock.AssertExpectations(t)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/metrics"
 	"github.com/kavehjamshidi/fidibo-challenge/internal/token"
 	"github.com/kavehjamshidi/fidibo-challenge/service"
 )
@@ -15,8 +16,8 @@ type RefreshTokenController interface {
 }
 
 type refreshTokenController struct {
-	secret string
-	svc    service.RefreshTokenService
+	keys token.Keyring
+	svc  service.RefreshTokenService
 }
 
 func (r *refreshTokenController) RefreshToken(c *gin.Context) {
@@ -24,28 +25,29 @@ func (r *refreshTokenController) RefreshToken(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	username, err := token.ExtractUsername(req.RefreshToken, r.secret)
+	username, err := r.keys.ExtractUsername(req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		metrics.Tokens.WithLabelValues(metrics.TokenRefresh, metrics.ResultRejected).Inc()
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	res, err := r.svc.RefreshToken(username)
+	res, err := r.svc.RefreshToken(c, username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		writeServiceError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, res)
 }
 
-func NewRefreshTokenController(svc service.RefreshTokenService, secret string) RefreshTokenController {
+func NewRefreshTokenController(svc service.RefreshTokenService, keys token.Keyring) RefreshTokenController {
 	return &refreshTokenController{
-		svc:    svc,
-		secret: secret,
+		svc:  svc,
+		keys: keys,
 	}
 }
@@ -18,12 +18,12 @@ import (
 
 func TestRefreshToken(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
-		secret := "test secret"
+		keys := token.NewKeyring("test secret", time.Minute)
 		username := "test"
 		svcMock := &mocks.RefreshTokenService{}
-		refreshTokenController := NewRefreshTokenController(svcMock, secret)
+		refreshTokenController := NewRefreshTokenController(svcMock, keys)
 
-		jwt, err := token.GenerateJWT(username, secret, time.Hour)
+		jwt, err := token.GenerateJWT(username, keys.Secret(), time.Hour)
 		assert.NoError(t, err)
 
 		refreshTokenRequest := domain.RefreshTokenRequest{
@@ -39,11 +39,11 @@ func TestRefreshToken(t *testing.T) {
 		expectedJSONResponse, err := json.Marshal(expectedResponse)
 		assert.NoError(t, err)
 
-		svcMock.On("RefreshToken", username).Return(expectedResponse, nil)
-
 		w := httptest.NewRecorder()
 		gin.SetMode(gin.TestMode)
 		c, _ := gin.CreateTestContext(w)
+
+		svcMock.On("RefreshToken", c, username).Return(expectedResponse, nil)
 		c.Request = &http.Request{Header: make(http.Header)}
 		c.Request.Method = http.MethodPost
 		c.Request.Header.Set("Content-Type", "application/json")
@@ -60,9 +60,9 @@ func TestRefreshToken(t *testing.T) {
 	})
 
 	t.Run("invalid token", func(t *testing.T) {
-		secret := "test secret"
+		keys := token.NewKeyring("test secret", time.Minute)
 		svcMock := &mocks.RefreshTokenService{}
-		refreshTokenController := NewRefreshTokenController(svcMock, secret)
+		refreshTokenController := NewRefreshTokenController(svcMock, keys)
 
 		jwt := "invalid jwt"
 
@@ -95,9 +95,9 @@ func TestRefreshToken(t *testing.T) {
 	})
 
 	t.Run("invalid request body", func(t *testing.T) {
-		secret := "test secret"
+		keys := token.NewKeyring("test secret", time.Minute)
 		svcMock := &mocks.RefreshTokenService{}
-		refreshTokenController := NewRefreshTokenController(svcMock, secret)
+		refreshTokenController := NewRefreshTokenController(svcMock, keys)
 
 		r := gin.Default()
 		r.POST("/refresh-token", refreshTokenController.RefreshToken)
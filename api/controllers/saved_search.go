@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/service"
+)
+
+type SavedSearchController interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Get(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type savedSearchController struct {
+	svc service.SavedSearchService
+}
+
+func (s *savedSearchController) Create(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindSavedSearchRequest(c)
+	if !ok {
+		return
+	}
+
+	res, err := s.svc.Create(c, username, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+func (s *savedSearchController) List(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	res, err := s.svc.List(c, username)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, res)
+}
+
+func (s *savedSearchController) Get(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	res, err := s.svc.Get(c, username, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, res)
+}
+
+func (s *savedSearchController) Update(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := bindSavedSearchRequest(c)
+	if !ok {
+		return
+	}
+
+	res, err := s.svc.Update(c, username, id, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (s *savedSearchController) Delete(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	err := s.svc.Delete(c, username, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow()
+}
+
+func bindSavedSearchRequest(c *gin.Context) (domain.SavedSearchRequest, bool) {
+	var req domain.SavedSearchRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, bindingErrorResponse(err, req))
+		return req, false
+	}
+
+	keyword, fieldErr := validateKeyword("keyword", req.Keyword)
+	if fieldErr != nil {
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{Message: invalidRequestMessage, Errors: []domain.FieldError{*fieldErr}})
+		return req, false
+	}
+	req.Keyword = keyword
+
+	if req.WebhookURL != "" && !isWebhookURL(req.WebhookURL) {
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{
+			Message: invalidRequestMessage,
+			Errors:  []domain.FieldError{{Field: "webhook_url", Message: "must be an http or https URL"}},
+		})
+		return req, false
+	}
+
+	return req, true
+}
+
+func NewSavedSearchController(svc service.SavedSearchService) SavedSearchController {
+	return &savedSearchController{
+		svc: svc,
+	}
+}
@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSavedSearch(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svcMock := &mocks.SavedSearchService{}
+		savedSearchController := NewSavedSearchController(svcMock)
+
+		req := domain.SavedSearchRequest{Name: "Kafka", Keyword: "kafka"}
+		expected := domain.SavedSearch{ID: "abc", Name: "Kafka", Keyword: "kafka"}
+		expectedJSONResponse, err := json.Marshal(expected)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodPost, "/me/saved-searches", `{"name": "Kafka", "keyword": "  kafka "}`)
+
+		svcMock.On("Create", c, "test", req).Return(expected, nil)
+
+		savedSearchController.Create(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.JSONEq(t, string(expectedJSONResponse), string(res))
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("invalid keyword", func(t *testing.T) {
+		svcMock := &mocks.SavedSearchService{}
+		savedSearchController := NewSavedSearchController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodPost, "/me/saved-searches", `{"keyword": "k"}`)
+
+		savedSearchController.Create(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		response := domain.ErrorResponse{}
+		err = json.Unmarshal(res, &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "keyword", response.Errors[0].Field)
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("invalid webhook URL", func(t *testing.T) {
+		svcMock := &mocks.SavedSearchService{}
+		savedSearchController := NewSavedSearchController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodPost, "/me/saved-searches", `{"keyword": "kafka", "webhook_url": "ftp://example.com"}`)
+
+		savedSearchController.Create(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		response := domain.ErrorResponse{}
+		err = json.Unmarshal(res, &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "webhook_url", response.Errors[0].Field)
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			err        error
+			statusCode int
+		}{
+			{domain.ErrSavedSearchLimitReached, http.StatusConflict},
+			{domain.ErrWebhookURLNotAllowed, http.StatusBadRequest},
+			{errors.New("redis error"), http.StatusInternalServerError},
+		}
+
+		for _, tt := range tests {
+			svcMock := &mocks.SavedSearchService{}
+			savedSearchController := NewSavedSearchController(svcMock)
+
+			w := httptest.NewRecorder()
+
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+			c.Request = newUserRequest(http.MethodPost, "/me/saved-searches", `{"keyword": "kafka"}`)
+
+			svcMock.On("Create", c, "test", domain.SavedSearchRequest{Keyword: "kafka"}).Return(domain.SavedSearch{}, tt.err)
+
+			savedSearchController.Create(c)
+
+			assert.Equal(t, tt.statusCode, w.Code, tt.err.Error())
+			svcMock.AssertExpectations(t)
+		}
+	})
+}
+
+func TestGetSavedSearch(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svcMock := &mocks.SavedSearchService{}
+		savedSearchController := NewSavedSearchController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodGet, "/me/saved-searches/abc", "")
+		c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+		svcMock.On("Get", c, "test", "abc").Return(domain.SavedSearch{ID: "abc"}, nil)
+
+		savedSearchController.Get(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svcMock := &mocks.SavedSearchService{}
+		savedSearchController := NewSavedSearchController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodGet, "/me/saved-searches/abc", "")
+		c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+		svcMock.On("Get", c, "test", "abc").Return(domain.SavedSearch{}, domain.ErrSavedSearchNotFound)
+
+		savedSearchController.Get(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		svcMock.AssertExpectations(t)
+	})
+}
+
+func TestUpdateSavedSearch(t *testing.T) {
+	svcMock := &mocks.SavedSearchService{}
+	savedSearchController := NewSavedSearchController(svcMock)
+
+	w := httptest.NewRecorder()
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newUserRequest(http.MethodPut, "/me/saved-searches/abc", `{"keyword": "camus"}`)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+	svcMock.On("Update", c, "test", "abc", domain.SavedSearchRequest{Keyword: "camus"}).Return(domain.SavedSearch{ID: "abc", Keyword: "camus"}, nil)
+
+	savedSearchController.Update(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	svcMock.AssertExpectations(t)
+}
+
+func TestDeleteSavedSearch(t *testing.T) {
+	svcMock := &mocks.SavedSearchService{}
+	savedSearchController := NewSavedSearchController(svcMock)
+
+	w := httptest.NewRecorder()
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newUserRequest(http.MethodDelete, "/me/saved-searches/abc", "")
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+	svcMock.On("Delete", c, "test", "abc").Return(nil)
+
+	savedSearchController.Delete(c)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	svcMock.AssertExpectations(t)
+}
+
+func TestListSavedSearches(t *testing.T) {
+	svcMock := &mocks.SavedSearchService{}
+	savedSearchController := NewSavedSearchController(svcMock)
+
+	expectedResponse := domain.SavedSearchesResponse{SavedSearches: []domain.SavedSearch{{ID: "abc"}}}
+
+	w := httptest.NewRecorder()
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newUserRequest(http.MethodGet, "/me/saved-searches", "")
+
+	svcMock.On("List", c, "test").Return(expectedResponse, nil)
+
+	savedSearchController.List(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	svcMock.AssertExpectations(t)
+}
@@ -2,43 +2,102 @@ package controllers
 
 import (
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/normalize"
 	"github.com/kavehjamshidi/fidibo-challenge/service"
 )
 
-const queryKey = "keyword"
+const (
+	defaultPage     = 1
+	defaultPageSize = 20
+)
 
 type SearchController interface {
 	Search(c *gin.Context)
 }
 
 type searchController struct {
-	svc service.SearchService
+	svc    service.SearchService
+	maxAge *live.Duration
 }
 
 func (s *searchController) Search(c *gin.Context) {
-	query, _ := c.GetQuery(queryKey)
+	var req domain.SearchRequest
 
-	res, err := s.svc.Search(c, query)
+	err := s.bindRequest(c, &req)
 	if err != nil {
-		statusCode := s.mapErrorToStatusCode(err)
-		c.JSON(statusCode, domain.ErrorResponse{Message: err.Error()})
+		writeError(c, http.StatusBadRequest, bindingErrorResponse(err, req))
+		return
+	}
+	if fieldErrors := s.validateRequest(&req); len(fieldErrors) > 0 {
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{Message: invalidRequestMessage, Errors: fieldErrors})
 		return
 	}
+	if req.Page == 0 {
+		req.Page = defaultPage
+	}
+	if req.Size == 0 {
+		req.Size = defaultPageSize
+	}
 
-	c.JSON(http.StatusOK, res)
+	res, err := s.svc.Search(c, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	maxAge := s.maxAge.Load()
+	if !res.FetchedAt.IsZero() {
+		maxAge -= time.Since(res.FetchedAt)
+	}
+	writeCacheableJSON(c, res, res.FetchedAt, maxAge)
 }
 
-func (s *searchController) mapErrorToStatusCode(err error) int {
-	if strings.Contains(err.Error(), "service unavailable") {
-		return http.StatusServiceUnavailable
+func (s *searchController) validateRequest(req *domain.SearchRequest) []domain.FieldError {
+	var fieldErrors []domain.FieldError
+
+	keyword, fieldErr := validateKeyword("keyword", req.Keyword)
+	if fieldErr != nil {
+		fieldErrors = append(fieldErrors, *fieldErr)
 	}
-	return http.StatusInternalServerError
+	req.Keyword = keyword
+
+	filters := []struct {
+		field string
+		value *string
+	}{
+		{field: "author", value: &req.Author},
+		{field: "publisher", value: &req.Publisher},
+	}
+	for _, filter := range filters {
+		if normalize.HasControlCharacters(*filter.value) {
+			fieldErrors = append(fieldErrors, domain.FieldError{Field: filter.field, Message: "must not contain control characters"})
+		}
+		*filter.value = normalize.Query(*filter.value)
+	}
+
+	if req.MinPrice != nil && req.MaxPrice != nil && *req.MinPrice > *req.MaxPrice {
+		fieldErrors = append(fieldErrors, domain.FieldError{Field: "max_price", Message: "must not be less than min_price"})
+	}
+
+	return fieldErrors
 }
 
-func NewSearchController(svc service.SearchService) SearchController {
-	return &searchController{svc: svc}
+func (s *searchController) bindRequest(c *gin.Context, req *domain.SearchRequest) error {
+	if c.Request.Method == http.MethodPost && c.ContentType() == binding.MIMEJSON && c.Request.ContentLength != 0 {
+		return c.ShouldBindJSON(req)
+	}
+	return c.ShouldBindQuery(req)
+}
+
+func NewSearchController(svc service.SearchService, maxAge *live.Duration) SearchController {
+	return &searchController{
+		svc:    svc,
+		maxAge: maxAge,
+	}
 }
@@ -1,25 +1,32 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch"
 	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestSearch(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		query := "test"
 		svcMock := &mocks.SearchService{}
-		searchController := NewSearchController(svcMock)
+		searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
 
 		expectedResult := domain.SearchResult{
 			Books: []domain.Book{
@@ -53,7 +60,7 @@ func TestSearch(t *testing.T) {
 		q.Add("keyword", query)
 		c.Request.URL.RawQuery = q.Encode()
 
-		svcMock.On("Search", c, query).Return(expectedResult, nil)
+		svcMock.On("Search", c, domain.SearchRequest{Keyword: query, Page: 1, Size: 20}).Return(expectedResult, nil)
 
 		searchController.Search(c)
 
@@ -68,7 +75,7 @@ func TestSearch(t *testing.T) {
 	t.Run("other error", func(t *testing.T) {
 		query := "test"
 		svcMock := &mocks.SearchService{}
-		searchController := NewSearchController(svcMock)
+		searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
 
 		w := httptest.NewRecorder()
 
@@ -83,7 +90,7 @@ func TestSearch(t *testing.T) {
 
 		errorMsg := "unknown error"
 
-		svcMock.On("Search", c, query).Return(domain.SearchResult{}, errors.New(errorMsg))
+		svcMock.On("Search", c, domain.SearchRequest{Keyword: query, Page: 1, Size: 20}).Return(domain.SearchResult{}, errors.New(errorMsg))
 
 		searchController.Search(c)
 
@@ -95,15 +102,116 @@ func TestSearch(t *testing.T) {
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
-		assert.NotEmpty(t, response.Message)
-		assert.Contains(t, response.Message, errorMsg)
+		assert.Equal(t, "Internal Server Error", response.Message)
+		assert.Equal(t, errorMsg, c.Errors.Last().Err.Error())
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("upstream errors", func(t *testing.T) {
+		cases := []struct {
+			name         string
+			err          error
+			expectedCode int
+		}{
+			{
+				name:         "upstream too many requests",
+				err:          &fidibosearch.UpstreamStatusError{StatusCode: http.StatusTooManyRequests},
+				expectedCode: http.StatusTooManyRequests,
+			},
+			{
+				name:         "upstream unavailable",
+				err:          &fidibosearch.UpstreamStatusError{StatusCode: http.StatusServiceUnavailable},
+				expectedCode: http.StatusServiceUnavailable,
+			},
+			{
+				name:         "upstream server error",
+				err:          &fidibosearch.UpstreamStatusError{StatusCode: http.StatusInternalServerError, Body: "boom"},
+				expectedCode: http.StatusBadGateway,
+			},
+			{
+				name:         "upstream timeout",
+				err:          &fidibosearch.UpstreamTimeoutError{Err: context.DeadlineExceeded},
+				expectedCode: http.StatusGatewayTimeout,
+			},
+			{
+				name:         "upstream unreachable",
+				err:          &fidibosearch.UpstreamTransportError{Err: errors.New("dial tcp 10.0.0.1:443: connect: connection refused")},
+				expectedCode: http.StatusBadGateway,
+			},
+			{
+				name:         "upstream decode error",
+				err:          &fidibosearch.UpstreamDecodeError{Err: errors.New("invalid character")},
+				expectedCode: http.StatusBadGateway,
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				query := "test"
+				svcMock := &mocks.SearchService{}
+				searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
+
+				w := httptest.NewRecorder()
+
+				gin.SetMode(gin.TestMode)
+				c, _ := gin.CreateTestContext(w)
+				c.Request = &http.Request{Header: make(http.Header), URL: &url.URL{}}
+				c.Request.Method = http.MethodPost
+				c.Request.Header.Set("Content-Type", "application/json")
+				q := c.Request.URL.Query()
+				q.Add("keyword", query)
+				c.Request.URL.RawQuery = q.Encode()
+
+				svcMock.On("Search", c, domain.SearchRequest{Keyword: query, Page: 1, Size: 20}).Return(domain.SearchResult{}, fmt.Errorf("service unavailable: %w", tc.err))
+
+				searchController.Search(c)
+
+				res, err := io.ReadAll(w.Body)
+				assert.NoError(t, err)
+
+				response := domain.ErrorResponse{}
+				err = json.Unmarshal(res, &response)
+				assert.NoError(t, err)
+
+				assert.Equal(t, tc.expectedCode, w.Code)
+				assert.Equal(t, http.StatusText(tc.expectedCode), response.Message)
+				assert.NotContains(t, string(res), tc.err.Error())
+				assert.ErrorIs(t, c.Errors.Last().Err, tc.err)
+				svcMock.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("upstream retry after is forwarded", func(t *testing.T) {
+		query := "test"
+		svcMock := &mocks.SearchService{}
+		searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = &http.Request{Header: make(http.Header), URL: &url.URL{}}
+		c.Request.Method = http.MethodPost
+		q := c.Request.URL.Query()
+		q.Add("keyword", query)
+		c.Request.URL.RawQuery = q.Encode()
+
+		upstreamErr := &fidibosearch.UpstreamStatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 5 * time.Second}
+
+		svcMock.On("Search", c, domain.SearchRequest{Keyword: query, Page: 1, Size: 20}).Return(domain.SearchResult{}, fmt.Errorf("service unavailable: %w", upstreamErr))
+
+		searchController.Search(c)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "5", w.Header().Get("Retry-After"))
 		svcMock.AssertExpectations(t)
 	})
 
-	t.Run("service unavailable", func(t *testing.T) {
+	t.Run("circuit open", func(t *testing.T) {
 		query := "test"
 		svcMock := &mocks.SearchService{}
-		searchController := NewSearchController(svcMock)
+		searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
 
 		w := httptest.NewRecorder()
 
@@ -116,9 +224,9 @@ func TestSearch(t *testing.T) {
 		q.Add("keyword", query)
 		c.Request.URL.RawQuery = q.Encode()
 
-		errorMsg := "service unavailable: some error"
+		openErr := &fidibosearch.CircuitOpenError{RetryAfter: 29500 * time.Millisecond}
 
-		svcMock.On("Search", c, query).Return(domain.SearchResult{}, errors.New(errorMsg))
+		svcMock.On("Search", c, domain.SearchRequest{Keyword: query, Page: 1, Size: 20}).Return(domain.SearchResult{}, fmt.Errorf("service unavailable: %w", openErr))
 
 		searchController.Search(c)
 
@@ -130,8 +238,330 @@ func TestSearch(t *testing.T) {
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
 		assert.NotEmpty(t, response.Message)
-		assert.Contains(t, response.Message, errorMsg)
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("bulkhead full", func(t *testing.T) {
+		query := "test"
+		svcMock := &mocks.SearchService{}
+		searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = &http.Request{Header: make(http.Header), URL: &url.URL{}}
+		c.Request.Method = http.MethodPost
+		c.Request.Header.Set("Content-Type", "application/json")
+		q := c.Request.URL.Query()
+		q.Add("keyword", query)
+		c.Request.URL.RawQuery = q.Encode()
+
+		svcMock.On("Search", c, domain.SearchRequest{Keyword: query, Page: 1, Size: 20}).Return(domain.SearchResult{}, fmt.Errorf("service unavailable: %w", fidibosearch.ErrBulkheadFull))
+
+		searchController.Search(c)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		svcMock.AssertExpectations(t)
+	})
+	t.Run("pagination parameters", func(t *testing.T) {
+		svcMock := &mocks.SearchService{}
+		searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = &http.Request{Header: make(http.Header), URL: &url.URL{}}
+		c.Request.Method = http.MethodPost
+		q := c.Request.URL.Query()
+		q.Add("keyword", "test")
+		q.Add("page", "3")
+		q.Add("size", "5")
+		c.Request.URL.RawQuery = q.Encode()
+
+		expectedResult := domain.SearchResult{Total: 100, Page: 3, Size: 5, HasMore: true}
+
+		svcMock.On("Search", c, domain.SearchRequest{Keyword: "test", Page: 3, Size: 5}).Return(expectedResult, nil)
+
+		searchController.Search(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("filter parameters", func(t *testing.T) {
+		svcMock := &mocks.SearchService{}
+		searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = &http.Request{Header: make(http.Header), URL: &url.URL{}}
+		c.Request.Method = http.MethodPost
+		q := c.Request.URL.Query()
+		q.Add("keyword", "test")
+		q.Add("author", "kafka")
+		q.Add("publisher", "cheshmeh")
+		q.Add("format", "audiobook")
+		q.Add("min_price", "1000")
+		q.Add("max_price", "50000")
+		q.Add("language", "fa")
+		q.Add("sort", "date")
+		c.Request.URL.RawQuery = q.Encode()
+
+		minPrice, maxPrice := 1000.0, 50000.0
+		expectedRequest := domain.SearchRequest{
+			Keyword:   "test",
+			Page:      1,
+			Size:      20,
+			Author:    "kafka",
+			Publisher: "cheshmeh",
+			Format:    domain.FormatAudiobook,
+			MinPrice:  &minPrice,
+			MaxPrice:  &maxPrice,
+			Language:  "fa",
+			Sort:      domain.SortDate,
+		}
+
+		svcMock.On("Search", c, expectedRequest).Return(domain.SearchResult{}, nil)
+
+		searchController.Search(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("invalid query parameters", func(t *testing.T) {
+		for _, rawQuery := range []string{"page=-1", "size=100", "page=abc", "format=pdf", "sort=popularity", "min_price=-1", "min_price=100&max_price=50"} {
+			svcMock := &mocks.SearchService{}
+			searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
+
+			w := httptest.NewRecorder()
+
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+			c.Request = &http.Request{Header: make(http.Header), URL: &url.URL{RawQuery: "keyword=test&" + rawQuery}}
+			c.Request.Method = http.MethodPost
+
+			searchController.Search(c)
+
+			res, err := io.ReadAll(w.Body)
+			assert.NoError(t, err)
+
+			response := domain.ErrorResponse{}
+			err = json.Unmarshal(res, &response)
+			assert.NoError(t, err)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, rawQuery)
+			assert.NotEmpty(t, response.Message)
+			svcMock.AssertExpectations(t)
+		}
+	})
+	t.Run("json body on post", func(t *testing.T) {
+		svcMock := &mocks.SearchService{}
+		searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		body := `{"keyword":"test","page":2,"format":"ebook"}`
+		c.Request = httptest.NewRequest(http.MethodPost, "/search/book", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		svcMock.On("Search", c, domain.SearchRequest{Keyword: "test", Page: 2, Size: 20, Format: domain.FormatEbook}).Return(domain.SearchResult{}, nil)
+
+		searchController.Search(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("caching headers", func(t *testing.T) {
+		fetchedAt := time.Now().Add(-4 * time.Minute).UTC()
+		expectedResult := domain.SearchResult{
+			Books:     []domain.Book{{ID: "123", Title: "test title"}},
+			FetchedAt: fetchedAt,
+		}
+
+		svcMock := &mocks.SearchService{}
+		searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/search/book?keyword=test", nil)
+
+		svcMock.On("Search", c, domain.SearchRequest{Keyword: "test", Page: 1, Size: 20}).Return(expectedResult, nil)
+
+		searchController.Search(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		assert.Equal(t, fetchedAt.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+		assert.Regexp(t, `^private, max-age=(359|360)$`, w.Header().Get("Cache-Control"))
+		assert.Equal(t, "Authorization", w.Header().Get("Vary"))
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("if-none-match returns not modified", func(t *testing.T) {
+		expectedResult := domain.SearchResult{
+			Books:     []domain.Book{{ID: "123", Title: "test title"}},
+			FetchedAt: time.Now().UTC(),
+		}
+
+		svcMock := &mocks.SearchService{}
+		searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
+		svcMock.On("Search", mock.Anything, domain.SearchRequest{Keyword: "test", Page: 1, Size: 20}).Return(expectedResult, nil)
+
+		gin.SetMode(gin.TestMode)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/search/book?keyword=test", nil)
+		searchController.Search(c)
+		etag := w.Header().Get("ETag")
+
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/search/book?keyword=test", nil)
+		c.Request.Header.Set("If-None-Match", `"other", W/`+etag)
+		searchController.Search(c)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/search/book?keyword=test", nil)
+		c.Request.Header.Set("If-None-Match", `"other"`)
+		searchController.Search(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Body.String())
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("if-modified-since returns not modified", func(t *testing.T) {
+		expectedResult := domain.SearchResult{
+			Books:     []domain.Book{{ID: "123", Title: "test title"}},
+			FetchedAt: time.Now().Add(-time.Minute).UTC(),
+		}
+
+		svcMock := &mocks.SearchService{}
+		searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/search/book?keyword=test", nil)
+		c.Request.Header.Set("If-Modified-Since", time.Now().UTC().Format(http.TimeFormat))
+
+		svcMock.On("Search", c, domain.SearchRequest{Keyword: "test", Page: 1, Size: 20}).Return(expectedResult, nil)
+
+		searchController.Search(c)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		svcMock.AssertExpectations(t)
+	})
+	t.Run("conditional headers are ignored on POST", func(t *testing.T) {
+		expectedResult := domain.SearchResult{
+			Books:     []domain.Book{{ID: "123", Title: "test title"}},
+			FetchedAt: time.Now().Add(-time.Minute).UTC(),
+		}
+
+		svcMock := &mocks.SearchService{}
+		searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
+		svcMock.On("Search", mock.Anything, domain.SearchRequest{Keyword: "test", Page: 1, Size: 20}).Return(expectedResult, nil)
+
+		gin.SetMode(gin.TestMode)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/search/book?keyword=test", nil)
+		searchController.Search(c)
+		etag := w.Header().Get("ETag")
+
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/search/book?keyword=test", nil)
+		c.Request.Header.Set("If-None-Match", etag)
+		c.Request.Header.Set("If-Modified-Since", time.Now().UTC().Format(http.TimeFormat))
+		searchController.Search(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Body.String())
+		svcMock.AssertExpectations(t)
+	})
+	t.Run("keyword validation", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			rawQuery string
+			field    string
+			message  string
+		}{
+			{name: "missing keyword", rawQuery: "", field: "keyword", message: "is required"},
+			{name: "blank keyword", rawQuery: "keyword=%20%20", field: "keyword", message: "is required"},
+			{name: "too short", rawQuery: "keyword=a", field: "keyword", message: "must be at least 2 characters"},
+			{name: "too long", rawQuery: "keyword=" + strings.Repeat("a", 101), field: "keyword", message: "must be at most 100 characters"},
+			{name: "control characters", rawQuery: "keyword=te%00st", field: "keyword", message: "must not contain control characters"},
+			{name: "control characters in filter", rawQuery: "keyword=test&author=ka%1bfka", field: "author", message: "must not contain control characters"},
+			{name: "invalid format", rawQuery: "keyword=test&format=pdf", field: "format", message: "must be one of: ebook, audiobook"},
+			{name: "invalid size", rawQuery: "keyword=test&size=100", field: "size", message: "must be at most 50"},
+			{name: "page too large", rawQuery: "keyword=test&page=1001", field: "page", message: "must be at most 1000"},
+			{name: "page overflowing the offset", rawQuery: "keyword=test&page=4611686018427387905", field: "page", message: "must be at most 1000"},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				svcMock := &mocks.SearchService{}
+				searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
+
+				w := httptest.NewRecorder()
+
+				gin.SetMode(gin.TestMode)
+				c, _ := gin.CreateTestContext(w)
+				c.Request = httptest.NewRequest(http.MethodGet, "/search/book?"+tc.rawQuery, nil)
+
+				searchController.Search(c)
+
+				res, err := io.ReadAll(w.Body)
+				assert.NoError(t, err)
+
+				response := domain.ErrorResponse{}
+				err = json.Unmarshal(res, &response)
+				assert.NoError(t, err)
+
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assert.Contains(t, response.Errors, domain.FieldError{Field: tc.field, Message: tc.message})
+				svcMock.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("keyword is trimmed and normalized", func(t *testing.T) {
+		svcMock := &mocks.SearchService{}
+		searchController := NewSearchController(svcMock, live.NewDuration(10*time.Minute))
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/search/book?keyword="+url.QueryEscape("  ۱۹۸۴   جورج اورول "), nil)
+
+		svcMock.On("Search", c, domain.SearchRequest{Keyword: "1984 جورج اورول", Page: 1, Size: 20}).Return(domain.SearchResult{}, nil)
+
+		searchController.Search(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
 		svcMock.AssertExpectations(t)
 	})
 }
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/requestid"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch"
+)
+
+const overloadRetryAfter = time.Second
+
+// writeServiceError answers with the status of err. Server and upstream errors can carry upstream bodies and
+// transport details, so they are only described by their status; err itself is attached to the request for the
+// access log.
+func writeServiceError(c *gin.Context, err error) {
+	if retryAfter, ok := retryAfter(err); ok {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	_ = c.Error(err)
+
+	status := mapErrorToStatusCode(err)
+	message := err.Error()
+	if status == http.StatusTooManyRequests || status >= http.StatusInternalServerError {
+		message = http.StatusText(status)
+	}
+	writeError(c, status, domain.ErrorResponse{Message: message})
+}
+
+// writeError adds the ID of the request to an error response so that it can be matched with the logs.
+func writeError(c *gin.Context, status int, res domain.ErrorResponse) {
+	res.RequestID, _ = requestid.FromContext(c)
+	c.JSON(status, res)
+}
+
+func mapErrorToStatusCode(err error) int {
+	var (
+		openErr      *fidibosearch.CircuitOpenError
+		statusErr    *fidibosearch.UpstreamStatusError
+		timeoutErr   *fidibosearch.UpstreamTimeoutError
+		decodeErr    *fidibosearch.UpstreamDecodeError
+		transportErr *fidibosearch.UpstreamTransportError
+	)
+
+	switch {
+	case errors.Is(err, domain.ErrWebhookURLNotAllowed):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrBookNotFound), errors.Is(err, domain.ErrFavoriteNotFound), errors.Is(err, domain.ErrSavedSearchNotFound),
+		errors.Is(err, domain.ErrWebhookNotFound), errors.Is(err, domain.ErrWebhookDeliveryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrFavoriteExists), errors.Is(err, domain.ErrFavoritesLimitReached), errors.Is(err, domain.ErrSavedSearchLimitReached),
+		errors.Is(err, domain.ErrWebhooksLimitReached):
+		return http.StatusConflict
+	case errors.As(err, &openErr), errors.Is(err, fidibosearch.ErrBulkheadFull), errors.Is(err, fidibosearch.ErrQuotaExceeded):
+		return http.StatusServiceUnavailable
+	case errors.As(err, &timeoutErr):
+		return http.StatusGatewayTimeout
+	case errors.As(err, &statusErr):
+		switch statusErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return statusErr.StatusCode
+		}
+		return http.StatusBadGateway
+	case errors.As(err, &decodeErr), errors.As(err, &transportErr):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
+
+func retryAfter(err error) (time.Duration, bool) {
+	var (
+		openErr   *fidibosearch.CircuitOpenError
+		statusErr *fidibosearch.UpstreamStatusError
+	)
+
+	switch {
+	case errors.As(err, &openErr):
+		if openErr.RetryAfter < time.Second {
+			return time.Second, true
+		}
+		return openErr.RetryAfter, true
+	case errors.Is(err, fidibosearch.ErrBulkheadFull), errors.Is(err, fidibosearch.ErrQuotaExceeded):
+		return overloadRetryAfter, true
+	case errors.As(err, &statusErr):
+		return statusErr.RetryAfter, statusErr.RetryAfter > 0
+	}
+	return 0, false
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/normalize"
+	"github.com/kavehjamshidi/fidibo-challenge/service"
+)
+
+const (
+	defaultSuggestLimit = 10
+	maxPrefixLength     = 50
+	suggestMaxAge       = 60
+)
+
+type SuggestController interface {
+	Suggest(c *gin.Context)
+}
+
+type suggestController struct {
+	svc service.SuggestService
+}
+
+func (s *suggestController) Suggest(c *gin.Context) {
+	var req domain.SuggestRequest
+
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, bindingErrorResponse(err, req))
+		return
+	}
+	if fieldErr := s.validatePrefix(&req); fieldErr != nil {
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{Message: invalidRequestMessage, Errors: []domain.FieldError{*fieldErr}})
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultSuggestLimit
+	}
+
+	res, err := s.svc.Suggest(c, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", suggestMaxAge))
+	c.JSON(http.StatusOK, res)
+}
+
+func (s *suggestController) validatePrefix(req *domain.SuggestRequest) *domain.FieldError {
+	if normalize.HasControlCharacters(req.Prefix) {
+		return &domain.FieldError{Field: "prefix", Message: "must not contain control characters"}
+	}
+
+	req.Prefix = normalize.Query(req.Prefix)
+
+	length := utf8.RuneCountInString(req.Prefix)
+	if length == 0 {
+		return &domain.FieldError{Field: "prefix", Message: "is required"}
+	}
+	if length > maxPrefixLength {
+		return &domain.FieldError{Field: "prefix", Message: fmt.Sprintf("must be at most %d characters", maxPrefixLength)}
+	}
+	return nil
+}
+
+func NewSuggestController(svc service.SuggestService) SuggestController {
+	return &suggestController{svc: svc}
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggest(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svcMock := &mocks.SuggestService{}
+		suggestController := NewSuggestController(svcMock)
+
+		expectedResponse := domain.SuggestResponse{
+			Suggestions: []domain.Suggestion{{Text: "کافکا", Type: domain.SuggestionTypeAuthor}},
+		}
+		expectedJSONResponse, err := json.Marshal(expectedResponse)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/search/suggest?prefix="+url.QueryEscape(" کاف "), nil)
+
+		svcMock.On("Suggest", c, domain.SuggestRequest{Prefix: "کاف", Limit: 10}).Return(expectedResponse, nil)
+
+		suggestController.Suggest(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+		assert.JSONEq(t, string(expectedJSONResponse), string(res))
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		for _, rawQuery := range []string{"", "prefix=%20", "prefix=a%00", "prefix=ka&limit=100"} {
+			svcMock := &mocks.SuggestService{}
+			suggestController := NewSuggestController(svcMock)
+
+			w := httptest.NewRecorder()
+
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/search/suggest?"+rawQuery, nil)
+
+			suggestController.Suggest(c)
+
+			res, err := io.ReadAll(w.Body)
+			assert.NoError(t, err)
+
+			response := domain.ErrorResponse{}
+			err = json.Unmarshal(res, &response)
+			assert.NoError(t, err)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, rawQuery)
+			assert.NotEmpty(t, response.Errors, rawQuery)
+			svcMock.AssertExpectations(t)
+		}
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		svcMock := &mocks.SuggestService{}
+		suggestController := NewSuggestController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/search/suggest?prefix=ka", nil)
+
+		svcMock.On("Suggest", c, domain.SuggestRequest{Prefix: "ka", Limit: 10}).Return(domain.SuggestResponse{}, errors.New("redis error"))
+
+		suggestController.Suggest(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "redis error")
+		assert.Len(t, c.Errors, 1)
+		svcMock.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/user"
+)
+
+// currentUser returns the username the auth middleware attached to the request and answers with 401 when
+// there is none.
+func currentUser(c *gin.Context) (string, bool) {
+	username, ok := user.Username(c.Request.Context())
+	if !ok {
+		writeError(c, http.StatusUnauthorized, domain.ErrorResponse{Message: "Unauthorized"})
+		return "", false
+	}
+	return username, true
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/normalize"
+)
+
+const (
+	invalidRequestMessage = "invalid request"
+
+	minKeywordLength = 2
+	maxKeywordLength = 100
+
+	maxIDLength = 64
+)
+
+func validateKeyword(field string, keyword string) (string, *domain.FieldError) {
+	if normalize.HasControlCharacters(keyword) {
+		return "", &domain.FieldError{Field: field, Message: "must not contain control characters"}
+	}
+
+	keyword = normalize.Query(keyword)
+
+	length := utf8.RuneCountInString(keyword)
+	switch {
+	case length == 0:
+		return "", &domain.FieldError{Field: field, Message: "is required"}
+	case length < minKeywordLength:
+		return "", &domain.FieldError{Field: field, Message: fmt.Sprintf("must be at least %d characters", minKeywordLength)}
+	case length > maxKeywordLength:
+		return "", &domain.FieldError{Field: field, Message: fmt.Sprintf("must be at most %d characters", maxKeywordLength)}
+	}
+
+	return keyword, nil
+}
+
+func bindingErrorResponse(err error, obj interface{}) domain.ErrorResponse {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return domain.ErrorResponse{Message: err.Error()}
+	}
+
+	res := domain.ErrorResponse{Message: invalidRequestMessage}
+	for _, fe := range validationErrors {
+		res.Errors = append(res.Errors, domain.FieldError{
+			Field:   fieldName(obj, fe.StructField()),
+			Message: validationMessage(fe),
+		})
+	}
+	return res
+}
+
+// fieldName returns the name a client uses for a struct field, keeping the index of an element of a slice
+// field such as "events[0]".
+func fieldName(obj interface{}, structField string) string {
+	if i := strings.IndexByte(structField, '['); i > 0 {
+		return fieldName(obj, structField[:i]) + structField[i:]
+	}
+
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	f, ok := t.FieldByName(structField)
+	if !ok {
+		return structField
+	}
+	for _, tag := range []string{"form", "json"} {
+		if name := strings.Split(f.Tag.Get(tag), ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+	return structField
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	}
+	return fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+}
+
+func validatePathParam(field, value string, maxLength int) (string, *domain.FieldError) {
+	if normalize.HasControlCharacters(value) {
+		return "", &domain.FieldError{Field: field, Message: "must not contain control characters"}
+	}
+
+	value = normalize.Whitespace(value)
+
+	length := utf8.RuneCountInString(value)
+	if length == 0 {
+		return "", &domain.FieldError{Field: field, Message: "is required"}
+	}
+	if length > maxLength {
+		return "", &domain.FieldError{Field: field, Message: fmt.Sprintf("must be at most %d characters", maxLength)}
+	}
+	return value, nil
+}
+
+func pathID(c *gin.Context, param string) (string, bool) {
+	id, fieldErr := validatePathParam(param, c.Param(param), maxIDLength)
+	if fieldErr != nil {
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{Message: invalidRequestMessage, Errors: []domain.FieldError{*fieldErr}})
+		return "", false
+	}
+	return id, true
+}
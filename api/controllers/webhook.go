@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/service"
+)
+
+type WebhookController interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Get(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	Deliveries(c *gin.Context)
+	Redeliver(c *gin.Context)
+}
+
+type webhookController struct {
+	svc service.WebhookService
+}
+
+func (w *webhookController) Create(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	res, err := w.svc.Create(c, username, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, res)
+}
+
+func (w *webhookController) List(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	res, err := w.svc.List(c, username)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, res)
+}
+
+func (w *webhookController) Get(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	res, err := w.svc.Get(c, username, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, res)
+}
+
+func (w *webhookController) Update(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	res, err := w.svc.Update(c, username, id, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (w *webhookController) Delete(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	err := w.svc.Delete(c, username, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow()
+}
+
+func (w *webhookController) Deliveries(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	res, err := w.svc.Deliveries(c, username, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, res)
+}
+
+func (w *webhookController) Redeliver(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := pathID(c, "deliveryId")
+	if !ok {
+		return
+	}
+
+	res, err := w.svc.Redeliver(c, username, id, deliveryID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, res)
+}
+
+func bindWebhookRequest(c *gin.Context) (domain.WebhookRequest, bool) {
+	var req domain.WebhookRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, bindingErrorResponse(err, req))
+		return req, false
+	}
+
+	if !isWebhookURL(req.URL) {
+		writeError(c, http.StatusBadRequest, domain.ErrorResponse{
+			Message: invalidRequestMessage,
+			Errors:  []domain.FieldError{{Field: "url", Message: "must be an http or https URL"}},
+		})
+		return req, false
+	}
+	return req, true
+}
+
+func isWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func NewWebhookController(svc service.WebhookService) WebhookController {
+	return &webhookController{
+		svc: svc,
+	}
+}
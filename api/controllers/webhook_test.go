@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svcMock := &mocks.WebhookService{}
+		webhookController := NewWebhookController(svcMock)
+
+		req := domain.WebhookRequest{URL: "https://example.com/hook", Events: []string{domain.EventSavedSearchNewResults}}
+		expected := domain.Webhook{ID: "abc", URL: "https://example.com/hook", Events: []string{domain.EventSavedSearchNewResults}, Secret: "secret", Active: true}
+		expectedJSONResponse, err := json.Marshal(expected)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodPost, "/webhooks", `{"url": "https://example.com/hook", "events": ["saved_search.new_results"]}`)
+
+		svcMock.On("Create", c, "test", req).Return(expected, nil)
+
+		webhookController.Create(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.JSONEq(t, string(expectedJSONResponse), string(res))
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		tests := []struct {
+			body  string
+			field string
+		}{
+			{`{"url": "ftp://example.com", "events": ["saved_search.new_results"]}`, "url"},
+			{`{"url": "https://example.com/hook", "events": []}`, "events"},
+			{`{"url": "https://example.com/hook", "events": ["book.added"]}`, "events[0]"},
+			{`{"url": "https://example.com/hook", "events": ["user.registered"]}`, "events[0]"},
+			{`{"url": "https://example.com/hook", "events": ["saved_search.new_results"], "secret": "short"}`, "secret"},
+		}
+
+		for _, tt := range tests {
+			svcMock := &mocks.WebhookService{}
+			webhookController := NewWebhookController(svcMock)
+
+			w := httptest.NewRecorder()
+
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+			c.Request = newUserRequest(http.MethodPost, "/webhooks", tt.body)
+
+			webhookController.Create(c)
+
+			res, err := io.ReadAll(w.Body)
+			assert.NoError(t, err)
+
+			response := domain.ErrorResponse{}
+			err = json.Unmarshal(res, &response)
+			assert.NoError(t, err)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, tt.body)
+			if assert.Len(t, response.Errors, 1, tt.body) {
+				assert.Equal(t, tt.field, response.Errors[0].Field)
+			}
+			svcMock.AssertExpectations(t)
+		}
+	})
+}
+
+func TestGetWebhook(t *testing.T) {
+	svcMock := &mocks.WebhookService{}
+	webhookController := NewWebhookController(svcMock)
+
+	w := httptest.NewRecorder()
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newUserRequest(http.MethodGet, "/webhooks/abc", "")
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+	svcMock.On("Get", c, "test", "abc").Return(domain.Webhook{}, domain.ErrWebhookNotFound)
+
+	webhookController.Get(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	svcMock.AssertExpectations(t)
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	svcMock := &mocks.WebhookService{}
+	webhookController := NewWebhookController(svcMock)
+
+	w := httptest.NewRecorder()
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newUserRequest(http.MethodDelete, "/webhooks/abc", "")
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+	svcMock.On("Delete", c, "test", "abc").Return(nil)
+
+	webhookController.Delete(c)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	svcMock.AssertExpectations(t)
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	t.Run("log", func(t *testing.T) {
+		svcMock := &mocks.WebhookService{}
+		webhookController := NewWebhookController(svcMock)
+
+		expectedResponse := domain.WebhookDeliveriesResponse{Deliveries: []domain.WebhookDelivery{{ID: "d1", WebhookID: "abc", Payload: json.RawMessage(`{}`)}}}
+		expectedJSONResponse, err := json.Marshal(expectedResponse)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodGet, "/webhooks/abc/deliveries", "")
+		c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+		svcMock.On("Deliveries", c, "test", "abc").Return(expectedResponse, nil)
+
+		webhookController.Deliveries(c)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.JSONEq(t, string(expectedJSONResponse), string(res))
+		svcMock.AssertExpectations(t)
+	})
+
+	t.Run("redeliver", func(t *testing.T) {
+		svcMock := &mocks.WebhookService{}
+		webhookController := NewWebhookController(svcMock)
+
+		w := httptest.NewRecorder()
+
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newUserRequest(http.MethodPost, "/webhooks/abc/deliveries/d1/redeliver", "")
+		c.Params = gin.Params{{Key: "id", Value: "abc"}, {Key: "deliveryId", Value: "d1"}}
+
+		svcMock.On("Redeliver", c, "test", "abc", "d1").Return(domain.WebhookDelivery{ID: "d1", Status: domain.DeliveryStatusPending, Payload: json.RawMessage(`{}`)}, nil)
+
+		webhookController.Redeliver(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		svcMock.AssertExpectations(t)
+	})
+}
@@ -6,29 +6,35 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/requestid"
 	"github.com/kavehjamshidi/fidibo-challenge/internal/token"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/user"
 )
 
-func JWTAuth(secret string) gin.HandlerFunc {
+func JWTAuth(keys token.Keyring) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		authHeaderParts := strings.Split(authHeader, " ")
 
 		if len(authHeaderParts) != 2 {
-			c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: "Unauthorized"})
-			c.Abort()
+			unauthorized(c, "Unauthorized")
 			return
 		}
 
 		jwt := authHeaderParts[1]
 
-		err := token.ValidateToken(jwt, secret)
+		username, err := keys.ExtractUsername(jwt)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: err.Error()})
-			c.Abort()
+			unauthorized(c, err.Error())
 			return
 		}
+		c.Request = c.Request.WithContext(user.WithUsername(c.Request.Context(), username))
 
 		c.Next()
 	}
 }
+
+func unauthorized(c *gin.Context, message string) {
+	requestID, _ := requestid.FromContext(c)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, domain.ErrorResponse{Message: message, RequestID: requestID})
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/metrics"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/requestid"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/user"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/ratelimit"
+)
+
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// RateLimit limits the requests to the routes of group, counting them per user when the request is
+// authenticated and per client IP otherwise. A limit of zero requests disables it. Requests are let through
+// when the limiter fails, so that an outage of Redis does not take down the whole API.
+func RateLimit(limiter ratelimit.Limiter, group string, limit ratelimit.Limit, logger *slog.Logger) gin.HandlerFunc {
+	if limit.Requests <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	logger = logger.With("component", "rate_limit", "group", group)
+
+	return func(c *gin.Context) {
+		key := group + ":ip:" + c.ClientIP()
+		if username, ok := user.Username(c.Request.Context()); ok {
+			key = group + ":user:" + username
+		}
+
+		res, err := limiter.Allow(c, key, limit)
+		if err != nil {
+			logger.WarnContext(c, "could not check rate limit", "error", err)
+			c.Next()
+			return
+		}
+
+		reset := strconv.Itoa(int(math.Ceil(res.ResetAfter.Seconds())))
+		c.Header(RateLimitLimitHeader, strconv.Itoa(res.Limit))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(res.Remaining))
+		c.Header(RateLimitResetHeader, reset)
+
+		if !res.Allowed {
+			metrics.RateLimitedRequests.WithLabelValues(group).Inc()
+			c.Header("Retry-After", reset)
+			requestID, _ := requestid.FromContext(c)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, domain.ErrorResponse{Message: "Too many requests", RequestID: requestID})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/user"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/ratelimit"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/ratelimit/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limit := ratelimit.Limit{Requests: 10, Window: time.Minute}
+
+	newRouter := func(limiter ratelimit.Limiter, limit ratelimit.Limit, username string) *gin.Engine {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			if username != "" {
+				c.Request = c.Request.WithContext(user.WithUsername(c.Request.Context(), username))
+			}
+		})
+		r.Use(RateLimit(limiter, "search", limit, slog.Default()))
+		r.GET("/search/book", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return r
+	}
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/search/book", nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		return req
+	}
+
+	t.Run("allowed by user", func(t *testing.T) {
+		limiter := &mocks.Limiter{}
+		limiter.On("Allow", mock.Anything, "search:user:test", limit).
+			Return(ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: 59500 * time.Millisecond}, nil)
+
+		w := httptest.NewRecorder()
+		newRouter(limiter, limit, "test").ServeHTTP(w, newRequest())
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "10", w.Header().Get(RateLimitLimitHeader))
+		assert.Equal(t, "9", w.Header().Get(RateLimitRemainingHeader))
+		assert.Equal(t, "60", w.Header().Get(RateLimitResetHeader))
+		limiter.AssertExpectations(t)
+	})
+
+	t.Run("rejected by ip", func(t *testing.T) {
+		limiter := &mocks.Limiter{}
+		limiter.On("Allow", mock.Anything, "search:ip:203.0.113.7", limit).
+			Return(ratelimit.Result{Allowed: false, Limit: 10, Remaining: 0, ResetAfter: 1500 * time.Millisecond}, nil)
+
+		w := httptest.NewRecorder()
+		newRouter(limiter, limit, "").ServeHTTP(w, newRequest())
+
+		response := domain.ErrorResponse{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "Too many requests", response.Message)
+		assert.Equal(t, "0", w.Header().Get(RateLimitRemainingHeader))
+		assert.Equal(t, "2", w.Header().Get(RateLimitResetHeader))
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+		limiter.AssertExpectations(t)
+	})
+
+	t.Run("spoofed forwarded header is ignored", func(t *testing.T) {
+		limiter := &mocks.Limiter{}
+		limiter.On("Allow", mock.Anything, "search:ip:203.0.113.7", limit).
+			Return(ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9}, nil)
+
+		r := newRouter(limiter, limit, "")
+		err := r.SetTrustedProxies(nil)
+		assert.NoError(t, err)
+
+		req := newRequest()
+		req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		limiter.AssertExpectations(t)
+	})
+
+	t.Run("forwarded header of a trusted proxy", func(t *testing.T) {
+		limiter := &mocks.Limiter{}
+		limiter.On("Allow", mock.Anything, "search:ip:198.51.100.1", limit).
+			Return(ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9}, nil)
+
+		r := newRouter(limiter, limit, "")
+		err := r.SetTrustedProxies([]string{"203.0.113.0/24"})
+		assert.NoError(t, err)
+
+		req := newRequest()
+		req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		limiter.AssertExpectations(t)
+	})
+
+	t.Run("limiter error", func(t *testing.T) {
+		limiter := &mocks.Limiter{}
+		limiter.On("Allow", mock.Anything, "search:user:test", limit).Return(ratelimit.Result{}, errors.New("connection refused"))
+
+		w := httptest.NewRecorder()
+		newRouter(limiter, limit, "test").ServeHTTP(w, newRequest())
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(RateLimitLimitHeader))
+		limiter.AssertExpectations(t)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		limiter := &mocks.Limiter{}
+
+		w := httptest.NewRecorder()
+		newRouter(limiter, ratelimit.Limit{Window: time.Minute}, "test").ServeHTTP(w, newRequest())
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(RateLimitLimitHeader))
+		limiter.AssertExpectations(t)
+	})
+}
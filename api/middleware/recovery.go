@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/requestid"
+)
+
+// Recovery turns a panic in a handler into a 500 response in the shape of the other errors, and logs it with
+// its stack and the request ID. Panics caused by a client that went away are not answered.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+
+			err, ok := rec.(error)
+			if !ok {
+				err = fmt.Errorf("%v", rec)
+			}
+			_ = c.Error(fmt.Errorf("panic: %w", err))
+
+			if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+				logger.WarnContext(c.Request.Context(), "client went away", "error", err)
+				c.Abort()
+				return
+			}
+
+			logger.ErrorContext(c.Request.Context(), "panic recovered", "error", err, "stack", string(debug.Stack()))
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+
+			requestID, _ := requestid.FromContext(c.Request.Context())
+			c.AbortWithStatusJSON(http.StatusInternalServerError, domain.ErrorResponse{
+				Message:   http.StatusText(http.StatusInternalServerError),
+				RequestID: requestID,
+			})
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/requestid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logs := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(logs, nil))
+
+	r := gin.New()
+	r.Use(RequestID())
+	r.Use(Logger(logger))
+	r.Use(Recovery(logger))
+	r.GET("/panic", func(c *gin.Context) {
+		panic("something went wrong")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(requestid.Header, "test-request-id")
+	r.ServeHTTP(w, req)
+
+	response := domain.ErrorResponse{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, domain.ErrorResponse{Message: "Internal Server Error", RequestID: "test-request-id"}, response)
+	assert.Contains(t, logs.String(), `"msg":"panic recovered"`)
+	assert.Contains(t, logs.String(), `"error":"panic: something went wrong"`)
+	assert.Contains(t, logs.String(), `"status":500`)
+}
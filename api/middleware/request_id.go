@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/requestid"
+)
+
+// RequestID keeps the X-Request-ID sent by the client, or generates one, and returns it on the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Request = c.Request.WithContext(requestid.WithRequestID(c.Request.Context(), id))
+		c.Header(requestid.Header, id)
+
+		c.Next()
+	}
+}
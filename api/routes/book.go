@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/api/controllers"
+)
+
+const (
+	bookRoute       = "/books/:id"
+	bookBySlugRoute = "/books/by-slug/:slug"
+)
+
+func SetupBookRoutes(r *gin.RouterGroup, controller controllers.BookController) {
+	r.GET(bookRoute, controller.GetByID)
+	r.GET(bookBySlugRoute, controller.GetBySlug)
+}
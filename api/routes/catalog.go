@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/api/controllers"
+)
+
+const (
+	authorBooksRoute    = "/authors/:name/books"
+	publisherBooksRoute = "/publishers/:title/books"
+)
+
+func SetupCatalogRoutes(r *gin.RouterGroup, controller controllers.CatalogController) {
+	r.GET(authorBooksRoute, controller.AuthorBooks)
+	r.GET(publisherBooksRoute, controller.PublisherBooks)
+}
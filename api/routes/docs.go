@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/api/controllers"
+)
+
+const (
+	openAPIRoute   = "/openapi.json"
+	swaggerUIRoute = "/docs"
+)
+
+func SetupDocsRoutes(r *gin.RouterGroup, controller controllers.DocsController) {
+	r.GET(openAPIRoute, controller.OpenAPI)
+	r.GET(swaggerUIRoute, controller.SwaggerUI)
+}
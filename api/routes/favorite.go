@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/api/controllers"
+)
+
+const (
+	favoritesRoute = "/me/favorites"
+	favoriteRoute  = "/me/favorites/:bookId"
+)
+
+func SetupFavoriteRoutes(r *gin.RouterGroup, controller controllers.FavoriteController) {
+	r.GET(favoritesRoute, controller.List)
+	r.POST(favoritesRoute, controller.Add)
+	r.DELETE(favoriteRoute, controller.Remove)
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/api/controllers"
+)
+
+const (
+	livenessRoute  = "/healthz"
+	readinessRoute = "/readyz"
+)
+
+func SetupHealthRoutes(r *gin.RouterGroup, controller controllers.HealthController) {
+	r.GET(livenessRoute, controller.Live)
+	r.GET(readinessRoute, controller.Ready)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/api/controllers"
+)
+
+const (
+	historyRoute           = "/me/search-history"
+	historyPreferenceRoute = "/me/search-history/preference"
+)
+
+func SetupHistoryRoutes(r *gin.RouterGroup, controller controllers.HistoryController) {
+	r.GET(historyRoute, controller.List)
+	r.DELETE(historyRoute, controller.Clear)
+	r.PUT(historyPreferenceRoute, controller.SetPreference)
+}
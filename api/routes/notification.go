@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/api/controllers"
+)
+
+const (
+	notificationsRoute = "/me/notifications"
+)
+
+func SetupNotificationRoutes(r *gin.RouterGroup, controller controllers.NotificationController) {
+	r.GET(notificationsRoute, controller.List)
+}
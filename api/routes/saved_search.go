@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/api/controllers"
+)
+
+const (
+	savedSearchesRoute = "/me/saved-searches"
+	savedSearchRoute   = "/me/saved-searches/:id"
+)
+
+func SetupSavedSearchRoutes(r *gin.RouterGroup, controller controllers.SavedSearchController) {
+	r.GET(savedSearchesRoute, controller.List)
+	r.POST(savedSearchesRoute, controller.Create)
+	r.GET(savedSearchRoute, controller.Get)
+	r.PUT(savedSearchRoute, controller.Update)
+	r.DELETE(savedSearchRoute, controller.Delete)
+}
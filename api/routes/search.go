@@ -10,5 +10,6 @@ const (
 )
 
 func SetupSearchRoutes(r *gin.RouterGroup, controller controllers.SearchController) {
+	r.GET(searchRoute, controller.Search)
 	r.POST(searchRoute, controller.Search)
 }
@@ -1,23 +1,76 @@
 package routes
 
 import (
+	"log/slog"
+
 	"github.com/gin-gonic/gin"
 	"github.com/kavehjamshidi/fidibo-challenge/api/controllers"
 	"github.com/kavehjamshidi/fidibo-challenge/api/middleware"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/token"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/ratelimit"
 )
 
 type Controllers struct {
 	controllers.SearchController
+	controllers.SuggestController
+	controllers.BookController
+	controllers.CatalogController
+	controllers.HistoryController
+	controllers.FavoriteController
+	controllers.SavedSearchController
+	controllers.NotificationController
+	controllers.WebhookController
 	controllers.LoginController
 	controllers.RefreshTokenController
+	controllers.HealthController
+	controllers.DocsController
+}
+
+// RateLimits are the limits of the route groups: Auth for login and refresh, counted per client IP, Search
+// for the routes that can reach search.fidibo.com and API for the other protected routes, counted per user.
+type RateLimits struct {
+	Auth   ratelimit.Limit
+	Search ratelimit.Limit
+	API    ratelimit.Limit
 }
 
-func Setup(gin *gin.Engine, ctrl Controllers, accessTokenSecret string) {
+func Setup(gin *gin.Engine,
+	ctrl Controllers,
+	accessTokenKeys token.Keyring,
+	limiter ratelimit.Limiter,
+	limits RateLimits,
+	logger *slog.Logger) {
+	gin.ContextWithFallback = true
+	gin.Use(middleware.RequestID())
+	gin.Use(middleware.Metrics())
+	gin.Use(middleware.Tracing())
+	gin.Use(middleware.Logger(logger))
+	gin.Use(middleware.Recovery(logger))
+
 	publicRouter := gin.Group("")
-	SetupLoginRoutes(publicRouter, ctrl.LoginController)
-	SetupRefreshTokenRoutes(publicRouter, ctrl.RefreshTokenController)
+	SetupHealthRoutes(publicRouter, ctrl.HealthController)
+	SetupDocsRoutes(publicRouter, ctrl.DocsController)
+
+	authRouter := publicRouter.Group("")
+	authRouter.Use(middleware.RateLimit(limiter, "auth", limits.Auth, logger))
+	SetupLoginRoutes(authRouter, ctrl.LoginController)
+	SetupRefreshTokenRoutes(authRouter, ctrl.RefreshTokenController)
 
 	protectedRouter := gin.Group("")
-	protectedRouter.Use(middleware.JWTAuth(accessTokenSecret))
-	SetupSearchRoutes(protectedRouter, ctrl.SearchController)
+	protectedRouter.Use(middleware.JWTAuth(accessTokenKeys))
+
+	searchRouter := protectedRouter.Group("")
+	searchRouter.Use(middleware.RateLimit(limiter, "search", limits.Search, logger))
+	SetupSearchRoutes(searchRouter, ctrl.SearchController)
+	SetupBookRoutes(searchRouter, ctrl.BookController)
+	SetupCatalogRoutes(searchRouter, ctrl.CatalogController)
+
+	apiRouter := protectedRouter.Group("")
+	apiRouter.Use(middleware.RateLimit(limiter, "api", limits.API, logger))
+	SetupSuggestRoutes(apiRouter, ctrl.SuggestController)
+	SetupHistoryRoutes(apiRouter, ctrl.HistoryController)
+	SetupFavoriteRoutes(apiRouter, ctrl.FavoriteController)
+	SetupSavedSearchRoutes(apiRouter, ctrl.SavedSearchController)
+	SetupNotificationRoutes(apiRouter, ctrl.NotificationController)
+	SetupWebhookRoutes(apiRouter, ctrl.WebhookController)
 }
@@ -0,0 +1,94 @@
+package routes
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/api/controllers"
+	"github.com/kavehjamshidi/fidibo-challenge/docs"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/token"
+	ratelimitmocks "github.com/kavehjamshidi/fidibo-challenge/pkg/ratelimit/mocks"
+	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pathParam = regexp.MustCompile(`:([^/]+)`)
+
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	keys := token.NewKeyring("secret", 0)
+	maxAge := live.NewDuration(0)
+
+	r := gin.New()
+	Setup(r, Controllers{
+		SearchController:       controllers.NewSearchController(&mocks.SearchService{}, maxAge),
+		SuggestController:      controllers.NewSuggestController(&mocks.SuggestService{}),
+		BookController:         controllers.NewBookController(&mocks.BookService{}, maxAge),
+		CatalogController:      controllers.NewCatalogController(&mocks.CatalogService{}, maxAge),
+		HistoryController:      controllers.NewHistoryController(&mocks.HistoryService{}),
+		FavoriteController:     controllers.NewFavoriteController(&mocks.FavoriteService{}),
+		SavedSearchController:  controllers.NewSavedSearchController(&mocks.SavedSearchService{}),
+		NotificationController: controllers.NewNotificationController(&mocks.NotificationService{}),
+		WebhookController:      controllers.NewWebhookController(&mocks.WebhookService{}),
+		LoginController:        controllers.NewLoginController(&mocks.LoginService{}),
+		RefreshTokenController: controllers.NewRefreshTokenController(&mocks.RefreshTokenService{}, keys),
+		HealthController:       controllers.NewHealthController(&mocks.HealthService{}),
+		DocsController:         controllers.NewDocsController(docs.OpenAPI),
+	}, keys, &ratelimitmocks.Limiter{}, RateLimits{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return r
+}
+
+// TestRoutesAreDocumented fails when a route is added without an entry in docs/openapi.json, or an entry is
+// left behind after its route was removed.
+func TestRoutesAreDocumented(t *testing.T) {
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Security *[]map[string][]string `json:"security"`
+		} `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(docs.OpenAPI, &spec))
+
+	var documented []string
+	public := map[string]bool{}
+	for path, operations := range spec.Paths {
+		for method, operation := range operations {
+			route := strings.ToUpper(method) + " " + path
+			documented = append(documented, route)
+			public[route] = operation.Security != nil && len(*operation.Security) == 0
+		}
+	}
+
+	r := setupRouter()
+	var registered []string
+	for _, route := range r.Routes() {
+		registered = append(registered, route.Method+" "+pathParam.ReplaceAllString(route.Path, "{$1}"))
+	}
+
+	sort.Strings(documented)
+	sort.Strings(registered)
+	assert.Equal(t, registered, documented)
+
+	t.Run("routes without security require a token", func(t *testing.T) {
+		for _, route := range r.Routes() {
+			name := route.Method + " " + pathParam.ReplaceAllString(route.Path, "{$1}")
+			if public[name] {
+				continue
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(route.Method, pathParam.ReplaceAllString(route.Path, "1"), nil))
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, name)
+		}
+	})
+}
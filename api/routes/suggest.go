@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/api/controllers"
+)
+
+const (
+	suggestRoute = "/search/suggest"
+)
+
+func SetupSuggestRoutes(r *gin.RouterGroup, controller controllers.SuggestController) {
+	r.GET(suggestRoute, controller.Suggest)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kavehjamshidi/fidibo-challenge/api/controllers"
+)
+
+const (
+	webhooksRoute          = "/webhooks"
+	webhookRoute           = "/webhooks/:id"
+	webhookDeliveriesRoute = "/webhooks/:id/deliveries"
+	webhookRedeliverRoute  = "/webhooks/:id/deliveries/:deliveryId/redeliver"
+)
+
+func SetupWebhookRoutes(r *gin.RouterGroup, controller controllers.WebhookController) {
+	r.GET(webhooksRoute, controller.List)
+	r.POST(webhooksRoute, controller.Create)
+	r.GET(webhookRoute, controller.Get)
+	r.PUT(webhookRoute, controller.Update)
+	r.DELETE(webhookRoute, controller.Delete)
+	r.GET(webhookDeliveriesRoute, controller.Deliveries)
+	r.POST(webhookRedeliverRoute, controller.Redeliver)
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+type Config struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// DrainDelay is how long the server keeps accepting requests after readiness starts failing, so that load
+	// balancers stop sending new ones before the listener closes.
+	DrainDelay      time.Duration
+	ShutdownTimeout time.Duration
+}
+
+func New(handler http.Handler, cfg Config) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// Serve serves on the listener until ctx is done, then calls drain, waits for the drain delay and shuts the
+// server down, giving in-flight requests until the shutdown timeout to finish.
+func Serve(ctx context.Context, srv *http.Server, ln net.Listener, cfg Config, drain func(), logger *slog.Logger) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("shutting down", "drain_delay", cfg.DrainDelay.String(), "timeout", cfg.ShutdownTimeout.String())
+	if drain != nil {
+		drain()
+	}
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-time.After(cfg.DrainDelay):
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	shutdownErr := srv.Shutdown(shutdownCtx)
+	if shutdownErr != nil {
+		logger.Error("in-flight requests did not finish in time", "error", shutdownErr)
+		srv.Close()
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return shutdownErr
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	cfg := Config{
+		ReadTimeout:       time.Second,
+		ReadHeaderTimeout: 2 * time.Second,
+		WriteTimeout:      3 * time.Second,
+		IdleTimeout:       4 * time.Second,
+		MaxHeaderBytes:    1024,
+	}
+
+	srv := New(http.NotFoundHandler(), cfg)
+
+	assert.Equal(t, time.Second, srv.ReadTimeout)
+	assert.Equal(t, 2*time.Second, srv.ReadHeaderTimeout)
+	assert.Equal(t, 3*time.Second, srv.WriteTimeout)
+	assert.Equal(t, 4*time.Second, srv.IdleTimeout)
+	assert.Equal(t, 1024, srv.MaxHeaderBytes)
+}
+
+func TestServe(t *testing.T) {
+	t.Run("drains in-flight requests", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.Write([]byte("done"))
+		})
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+
+		cfg := Config{DrainDelay: 10 * time.Millisecond, ShutdownTimeout: time.Second}
+		srv := New(handler, cfg)
+
+		var drained atomic.Bool
+		ctx, cancel := context.WithCancel(context.Background())
+		served := make(chan error, 1)
+		go func() {
+			served <- Serve(ctx, srv, ln, cfg, func() { drained.Store(true) }, slog.Default())
+		}()
+
+		body := make(chan string, 1)
+		go func() {
+			res, err := http.Get("http://" + ln.Addr().String())
+			assert.NoError(t, err)
+			defer res.Body.Close()
+			b, _ := io.ReadAll(res.Body)
+			body <- string(b)
+		}()
+
+		<-started
+		cancel()
+		time.Sleep(20 * time.Millisecond)
+		assert.True(t, drained.Load())
+		close(release)
+
+		assert.Equal(t, "done", <-body)
+		assert.NoError(t, <-served)
+	})
+
+	t.Run("gives up after the shutdown timeout", func(t *testing.T) {
+		started := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-r.Context().Done()
+		})
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+
+		cfg := Config{ShutdownTimeout: 10 * time.Millisecond}
+		srv := New(handler, cfg)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		served := make(chan error, 1)
+		go func() {
+			served <- Serve(ctx, srv, ln, cfg, nil, slog.Default())
+		}()
+
+		go http.Get("http://" + ln.Addr().String())
+
+		<-started
+		cancel()
+
+		assert.ErrorIs(t, <-served, context.DeadlineExceeded)
+	})
+
+	t.Run("listener error", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		ln.Close()
+
+		err = Serve(context.Background(), New(http.NotFoundHandler(), Config{}), ln, Config{}, nil, slog.Default())
+		assert.Error(t, err)
+	})
+}
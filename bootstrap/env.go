@@ -1,71 +1,461 @@
 package bootstrap
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
 	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 const (
-	serverAddressEnvKey      = "SERVER_ADDRESS"
-	redisAddressEnvKey       = "REDIS_ADDRESS"
-	testRedisAddressEnvKey   = "TEST_REDIS_ADDRESS"
-	accessTokenExpiryEnvKey  = "ACCESS_EXPIRY"
-	refreshTokenExpiryEnvKey = "REFRESH_EXPIRY"
-	accessTokenSecretEnvKey  = "ACCESS_SECRET"
-	refreshTokenSecretEnvKey = "REFRESH_SECRET"
-
-	defaultServerAddress      = ":8080"
-	defaultRedisAddress       = "localhost:6379"
-	defaultTestRedisAddress   = "localhost:6379"
-	defaultAccessTokenExpiry  = "15m"
-	defaultRefreshTokenExpiry = "168h"
-	defaultAccessTokenSecret  = "access token secret"
-	defaultRefreshTokenSecret = "refresh token secret"
+	ProfileDev = "dev"
+
+	configFileEnvKey = "CONFIG_FILE"
+	configFileFlag   = "config"
+	printConfigFlag  = "print-config"
+
+	fileSuffix = "_file"
+	redacted   = "REDACTED"
 )
 
+// Env is the configuration of the service. Every field with an env tag can be set, from the lowest to the
+// highest precedence, by its default, the config file, the environment variable named by the tag and the
+// command line flag of the same name in kebab case, e.g. CACHE_TTL, cache_ttl in the file and --cache-ttl.
+// In the file and the environment, a setting can also be read from the file named by its _FILE variant, e.g.
+// ACCESS_SECRET_FILE. Fields tagged with reload are applied by Watch without a restart.
 type Env struct {
-	ServerAddress      string
-	RedisAddress       string
-	TestRedisAddress   string
-	AccessTokenExpiry  time.Duration
-	RefreshTokenExpiry time.Duration
-	AccessTokenSecret  string
-	RefreshTokenSecret string
+	Profile string `env:"PROFILE" default:"production" validate:"required"`
+
+	ServerAddress      string        `env:"SERVER_ADDRESS" default:":8080" validate:"required"`
+	RedisAddress       string        `env:"REDIS_ADDRESS" default:"localhost:6379" validate:"required"`
+	TestRedisAddress   string        `env:"TEST_REDIS_ADDRESS" default:"localhost:6379"`
+	AccessTokenExpiry  time.Duration `env:"ACCESS_EXPIRY" default:"15m" validate:"positive"`
+	RefreshTokenExpiry time.Duration `env:"REFRESH_EXPIRY" default:"168h" validate:"positive"`
+	AccessTokenSecret  string        `env:"ACCESS_SECRET" default:"access token secret" validate:"required" secret:"true" reload:"true"`
+	RefreshTokenSecret string        `env:"REFRESH_SECRET" default:"refresh token secret" validate:"required" secret:"true" reload:"true"`
+	CacheTTL           time.Duration `env:"CACHE_TTL" default:"10m" validate:"positive" reload:"true"`
+	SuggestBudget      time.Duration `env:"SUGGEST_BUDGET" default:"10ms" validate:"positive"`
+	SuggestCacheTTL    time.Duration `env:"SUGGEST_CACHE_TTL" default:"30s" validate:"positive" reload:"true"`
+	BookCacheTTL       time.Duration `env:"BOOK_CACHE_TTL" default:"24h" validate:"positive" reload:"true"`
+	SearchHistorySize  int           `env:"SEARCH_HISTORY_SIZE" default:"50" validate:"positive"`
+	FavoritesLimit     int           `env:"FAVORITES_LIMIT" default:"500" validate:"positive"`
+
+	SavedSearchesLimit  int           `env:"SAVED_SEARCHES_LIMIT" default:"20" validate:"positive"`
+	SavedSearchInterval time.Duration `env:"SAVED_SEARCH_INTERVAL" default:"15m" validate:"positive"`
+	NotificationsSize   int           `env:"NOTIFICATIONS_SIZE" default:"100" validate:"positive"`
+
+	WebhooksLimit            int           `env:"WEBHOOKS_LIMIT" default:"50" validate:"positive"`
+	WebhookTimeout           time.Duration `env:"WEBHOOK_TIMEOUT" default:"5s" validate:"positive"`
+	WebhookMaxAttempts       int           `env:"WEBHOOK_MAX_ATTEMPTS" default:"8" validate:"positive"`
+	WebhookRetryBackoff      time.Duration `env:"WEBHOOK_RETRY_BACKOFF" default:"10s" validate:"positive"`
+	WebhookMaxRetryBackoff   time.Duration `env:"WEBHOOK_MAX_RETRY_BACKOFF" default:"1h" validate:"positive"`
+	WebhookPollInterval      time.Duration `env:"WEBHOOK_POLL_INTERVAL" default:"1s" validate:"positive"`
+	WebhookBatchSize         int           `env:"WEBHOOK_BATCH_SIZE" default:"20" validate:"positive"`
+	WebhookDeliveryLogSize   int           `env:"WEBHOOK_DELIVERY_LOG_SIZE" default:"100" validate:"positive"`
+	WebhookDeliveryRetention time.Duration `env:"WEBHOOK_DELIVERY_RETENTION" default:"168h" validate:"positive"`
+	WebhookAllowPrivate      bool          `env:"WEBHOOK_ALLOW_PRIVATE_ADDRESSES" default:"false"`
+	SystemWebhookURL         string        `env:"SYSTEM_WEBHOOK_URL"`
+	SystemWebhookSecret      string        `env:"SYSTEM_WEBHOOK_SECRET" secret:"true"`
+
+	UpstreamTimeout       time.Duration `env:"FIDIBO_TIMEOUT" default:"5s" validate:"positive"`
+	BreakerWindowSize     int           `env:"FIDIBO_BREAKER_WINDOW" default:"20" validate:"positive"`
+	BreakerMinRequests    int           `env:"FIDIBO_BREAKER_MIN_REQUESTS" default:"10" validate:"positive"`
+	BreakerErrorRate      float64       `env:"FIDIBO_BREAKER_ERROR_RATE" default:"0.5" validate:"ratio"`
+	BreakerSlowCall       time.Duration `env:"FIDIBO_BREAKER_SLOW_CALL" default:"3s" validate:"positive"`
+	BreakerSlowCallRate   float64       `env:"FIDIBO_BREAKER_SLOW_CALL_RATE" default:"0.8" validate:"ratio"`
+	BreakerOpenTimeout    time.Duration `env:"FIDIBO_BREAKER_OPEN_TIMEOUT" default:"30s" validate:"positive"`
+	BreakerHalfOpenCalls  int           `env:"FIDIBO_BREAKER_HALF_OPEN_CALLS" default:"3" validate:"positive"`
+	UpstreamMaxConcurrent int           `env:"FIDIBO_MAX_CONCURRENT" default:"20" validate:"positive"`
+	UpstreamMaxQueueWait  time.Duration `env:"FIDIBO_MAX_QUEUE_WAIT" default:"100ms" validate:"nonnegative"`
+
+	UpstreamQuotaLimit           int           `env:"FIDIBO_QUOTA_LIMIT" default:"20" validate:"nonnegative"`
+	UpstreamQuotaWindow          time.Duration `env:"FIDIBO_QUOTA_WINDOW" default:"1s" validate:"positive"`
+	UpstreamQuotaMaxWait         time.Duration `env:"FIDIBO_QUOTA_MAX_WAIT" default:"250ms" validate:"nonnegative"`
+	UpstreamQuotaBackgroundShare float64       `env:"FIDIBO_QUOTA_BACKGROUND_SHARE" default:"0.5" validate:"ratio"`
+
+	AdminAddress string `env:"ADMIN_ADDRESS" default:"127.0.0.1:9090" validate:"required"`
+
+	TracingEndpoint    string  `env:"TRACING_ENDPOINT"`
+	TracingInsecure    bool    `env:"TRACING_INSECURE" default:"false"`
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO" default:"0.1" validate:"ratio"`
+
+	LogLevel slog.Level `env:"LOG_LEVEL" default:"info" reload:"true"`
+
+	HealthCheckTimeout  time.Duration `env:"HEALTH_CHECK_TIMEOUT" default:"1s" validate:"positive"`
+	HealthCheckUpstream bool          `env:"HEALTH_CHECK_UPSTREAM" default:"false"`
+
+	ServerReadTimeout       time.Duration `env:"SERVER_READ_TIMEOUT" default:"15s" validate:"nonnegative"`
+	ServerReadHeaderTimeout time.Duration `env:"SERVER_READ_HEADER_TIMEOUT" default:"5s" validate:"positive"`
+	ServerWriteTimeout      time.Duration `env:"SERVER_WRITE_TIMEOUT" default:"30s" validate:"nonnegative"`
+	ServerIdleTimeout       time.Duration `env:"SERVER_IDLE_TIMEOUT" default:"60s" validate:"nonnegative"`
+	ServerMaxHeaderBytes    int           `env:"SERVER_MAX_HEADER_BYTES" default:"65536" validate:"positive"`
+	TrustedProxies          string        `env:"TRUSTED_PROXIES"`
+	ShutdownDrainDelay      time.Duration `env:"SHUTDOWN_DRAIN_DELAY" default:"5s" validate:"nonnegative"`
+	ShutdownTimeout         time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s" validate:"positive"`
+
+	ConfigWatchInterval time.Duration `env:"CONFIG_WATCH_INTERVAL" default:"10s" validate:"nonnegative"`
+	SecretRotationGrace time.Duration `env:"SECRET_ROTATION_GRACE" default:"15m" validate:"nonnegative"`
+	// RefreshSecretRotationGrace defaults to RefreshTokenExpiry so refresh tokens outlive a rotation.
+	RefreshSecretRotationGrace time.Duration `env:"REFRESH_SECRET_ROTATION_GRACE" validate:"nonnegative"`
+
+	RateLimitAuthRequests   int           `env:"RATE_LIMIT_AUTH_REQUESTS" default:"10" validate:"nonnegative"`
+	RateLimitAuthWindow     time.Duration `env:"RATE_LIMIT_AUTH_WINDOW" default:"1m" validate:"positive"`
+	RateLimitSearchRequests int           `env:"RATE_LIMIT_SEARCH_REQUESTS" default:"60" validate:"nonnegative"`
+	RateLimitSearchWindow   time.Duration `env:"RATE_LIMIT_SEARCH_WINDOW" default:"1m" validate:"positive"`
+	RateLimitAPIRequests    int           `env:"RATE_LIMIT_API_REQUESTS" default:"300" validate:"nonnegative"`
+	RateLimitAPIWindow      time.Duration `env:"RATE_LIMIT_API_WINDOW" default:"1m" validate:"positive"`
+
+	// PrintConfig is set by the --print-config flag and is not part of the configuration itself.
+	PrintConfig bool
 }
 
-func NewEnv() *Env {
-	serverAddress := getEnvWithFallback(serverAddressEnvKey, defaultServerAddress)
-	redisAddress := getEnvWithFallback(redisAddressEnvKey, defaultRedisAddress)
-	testRedisAddress := getEnvWithFallback(testRedisAddressEnvKey, defaultTestRedisAddress)
-	accessTokenSecret := getEnvWithFallback(accessTokenSecretEnvKey, defaultAccessTokenSecret)
-	refreshTokenSecret := getEnvWithFallback(refreshTokenSecretEnvKey, defaultRefreshTokenSecret)
+type setting struct {
+	index  int
+	key    string
+	flag   string
+	def    string
+	rule   string
+	secret bool
+	reload bool
+}
 
-	accessTokenExpiryString := getEnvWithFallback(accessTokenExpiryEnvKey, defaultAccessTokenExpiry)
-	accessTokenExpiry, err := time.ParseDuration(accessTokenExpiryString)
-	if err != nil {
-		panic(err)
+// fileKey is the name of the setting in the config file.
+func (s setting) fileKey() string {
+	return strings.ToLower(s.key)
+}
+
+type value struct {
+	raw  string
+	from string
+}
+
+func envSettings() []setting {
+	t := reflect.TypeOf(Env{})
+	settings := make([]setting, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		settings = append(settings, setting{
+			index:  i,
+			key:    key,
+			flag:   strings.ReplaceAll(strings.ToLower(key), "_", "-"),
+			def:    field.Tag.Get("default"),
+			rule:   field.Tag.Get("validate"),
+			secret: field.Tag.Get("secret") == "true",
+			reload: field.Tag.Get("reload") == "true",
+		})
+	}
+	return settings
+}
+
+// Load builds the configuration from the defaults, the config file named by --config or CONFIG_FILE, the
+// environment and the command line flags in args, each overriding the previous one. All invalid settings are
+// reported together in the returned error.
+func Load(args []string) (*Env, error) {
+	return load(args, os.Getenv)
+}
+
+func load(args []string, getenv func(string) string) (*Env, error) {
+	settings := envSettings()
+
+	fs := flag.NewFlagSet("fidibo-challenge", flag.ContinueOnError)
+	configFile := fs.String(configFileFlag, "", "path of a YAML or TOML config file, overrides "+configFileEnvKey)
+	printConfig := fs.Bool(printConfigFlag, false, "print the configuration with secrets redacted and exit")
+	flagKeys := make(map[string]string, len(settings))
+	for _, s := range settings {
+		fs.String(s.flag, "", fmt.Sprintf("overrides %s (default %q)", s.key, s.def))
+		flagKeys[s.flag] = s.key
 	}
-	refreshTokenExpiryString := getEnvWithFallback(refreshTokenExpiryEnvKey, defaultRefreshTokenExpiry)
-	refreshTokenExpiry, err := time.ParseDuration(refreshTokenExpiryString)
+	err := fs.Parse(args)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	return &Env{
-		ServerAddress:      serverAddress,
-		RedisAddress:       redisAddress,
-		TestRedisAddress:   testRedisAddress,
-		AccessTokenExpiry:  accessTokenExpiry,
-		RefreshTokenExpiry: refreshTokenExpiry,
-		AccessTokenSecret:  accessTokenSecret,
-		RefreshTokenSecret: refreshTokenSecret,
+	var errs []error
+
+	values := make(map[string]value, len(settings))
+	for _, s := range settings {
+		values[s.key] = value{raw: s.def, from: "default"}
+	}
+
+	path := *configFile
+	if path == "" {
+		path = getenv(configFileEnvKey)
+	}
+	if path != "" {
+		file, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(file))
+		for key := range file {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			envKey := strings.ToUpper(strings.TrimSuffix(key, fileSuffix))
+			if _, ok := values[envKey]; !ok || key != strings.ToLower(key) {
+				errs = append(errs, fmt.Errorf("%s: unknown setting in %s", key, path))
+				continue
+			}
+			raw, ok := scalarString(file[key])
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: must be a single value in %s", key, path))
+				continue
+			}
+			if !strings.HasSuffix(key, fileSuffix) {
+				values[envKey] = value{raw: raw, from: path}
+				continue
+			}
+			if _, ok := file[strings.TrimSuffix(key, fileSuffix)]; ok {
+				errs = append(errs, fmt.Errorf("%s: only one of %s and %s can be set in %s", envKey, strings.TrimSuffix(key, fileSuffix), key, path))
+				continue
+			}
+			raw, err = readValueFile(raw)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				continue
+			}
+			values[envKey] = value{raw: raw, from: key + " in " + path}
+		}
+	}
+
+	for _, s := range settings {
+		raw, filePath := getenv(s.key), getenv(s.key+strings.ToUpper(fileSuffix))
+		if raw != "" && filePath != "" {
+			errs = append(errs, fmt.Errorf("%s: only one of %s and %s%s can be set", s.key, s.key, s.key, strings.ToUpper(fileSuffix)))
+			continue
+		}
+		if raw != "" {
+			values[s.key] = value{raw: raw, from: "the environment"}
+			continue
+		}
+		if filePath != "" {
+			raw, err := readValueFile(filePath)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s: %w", s.key, strings.ToUpper(fileSuffix), err))
+				continue
+			}
+			values[s.key] = value{raw: raw, from: s.key + strings.ToUpper(fileSuffix)}
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		key, ok := flagKeys[f.Name]
+		if ok {
+			values[key] = value{raw: f.Value.String(), from: "--" + f.Name}
+		}
+	})
+
+	env := &Env{PrintConfig: *printConfig}
+	fields := reflect.ValueOf(env).Elem()
+	for _, s := range settings {
+		v := values[s.key]
+		field := fields.Field(s.index)
+		if v.raw == "" && s.def == "" && field.Kind() != reflect.String {
+			continue
+		}
+
+		err := parseValue(field, v.raw)
+		if err != nil {
+			raw := v.raw
+			if s.secret {
+				raw = redacted
+			}
+			errs = append(errs, fmt.Errorf("%s: %q from %s %w", s.key, raw, v.from, err))
+			continue
+		}
+
+		err = checkRule(field, s.rule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.key, err))
+		}
+	}
+
+	if values["REFRESH_SECRET_ROTATION_GRACE"].raw == "" {
+		env.RefreshSecretRotationGrace = env.RefreshTokenExpiry
+	}
+
+	errs = append(errs, env.validate(settings, values)...)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return env, nil
+}
+
+func parseValue(field reflect.Value, raw string) error {
+	switch field.Interface().(type) {
+	case time.Duration:
+		val, err := time.ParseDuration(raw)
+		if err != nil {
+			return errors.New("is not a valid duration")
+		}
+		field.SetInt(int64(val))
+	case slog.Level:
+		var val slog.Level
+		err := val.UnmarshalText([]byte(raw))
+		if err != nil {
+			return errors.New("is not a valid log level")
+		}
+		field.Set(reflect.ValueOf(val))
+	case string:
+		field.SetString(raw)
+	case int:
+		val, err := strconv.Atoi(raw)
+		if err != nil {
+			return errors.New("is not a valid integer")
+		}
+		field.SetInt(int64(val))
+	case float64:
+		val, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return errors.New("is not a valid number")
+		}
+		field.SetFloat(val)
+	case bool:
+		val, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("is not a valid boolean")
+		}
+		field.SetBool(val)
+	default:
+		return fmt.Errorf("has unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+func checkRule(field reflect.Value, rule string) error {
+	switch rule {
+	case "required":
+		if field.String() == "" {
+			return errors.New("must be set")
+		}
+	case "positive":
+		if field.Int() <= 0 {
+			return errors.New("must be greater than zero")
+		}
+	case "nonnegative":
+		if field.Int() < 0 {
+			return errors.New("must not be negative")
+		}
+	case "ratio":
+		if field.Float() < 0 || field.Float() > 1 {
+			return errors.New("must be between 0 and 1")
+		}
+	}
+	return nil
+}
+
+func (e *Env) validate(settings []setting, values map[string]value) []error {
+	var errs []error
+
+	if e.Profile != ProfileDev {
+		for _, s := range settings {
+			if s.secret && s.def != "" && values[s.key].raw == s.def {
+				errs = append(errs, fmt.Errorf("%s: the default secret is only allowed in the %s profile", s.key, ProfileDev))
+			}
+		}
+	}
+
+	if e.BreakerMinRequests > e.BreakerWindowSize {
+		errs = append(errs, errors.New("FIDIBO_BREAKER_MIN_REQUESTS: must not be greater than FIDIBO_BREAKER_WINDOW"))
+	}
+	if e.WebhookRetryBackoff > e.WebhookMaxRetryBackoff {
+		errs = append(errs, errors.New("WEBHOOK_RETRY_BACKOFF: must not be greater than WEBHOOK_MAX_RETRY_BACKOFF"))
+	}
+	if e.UpstreamQuotaLimit > 0 && math.Floor(float64(e.UpstreamQuotaLimit)*e.UpstreamQuotaBackgroundShare) < 1 {
+		errs = append(errs, errors.New("FIDIBO_QUOTA_BACKGROUND_SHARE: must allow background requests at least one request of FIDIBO_QUOTA_LIMIT"))
+	}
+	if e.SystemWebhookURL != "" && len(e.SystemWebhookSecret) < 16 {
+		errs = append(errs, errors.New("SYSTEM_WEBHOOK_SECRET: must be at least 16 characters when SYSTEM_WEBHOOK_URL is set"))
+	}
+	for _, proxy := range e.TrustedProxyList() {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		if net.ParseIP(proxy) == nil && cidrErr != nil {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES: %q is not an IP address or CIDR", proxy))
+		}
+	}
+
+	return errs
+}
+
+// TrustedProxyList returns the comma separated addresses of TRUSTED_PROXIES. Only requests from these may set
+// the client IP with X-Forwarded-For; with none, the client IP is always the remote address.
+func (e *Env) TrustedProxyList() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(e.TrustedProxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// Changed returns the keys of the settings that differ between e and other.
+func (e *Env) Changed(other *Env) []string {
+	var keys []string
+	a, b := reflect.ValueOf(e).Elem(), reflect.ValueOf(other).Elem()
+	for _, s := range envSettings() {
+		if !a.Field(s.index).Equal(b.Field(s.index)) {
+			keys = append(keys, s.key)
+		}
+	}
+	return keys
+}
+
+// Print writes the configuration to w as YAML that can be used as a config file. Secrets are redacted.
+func (e *Env) Print(w io.Writer) error {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	fields := reflect.ValueOf(e).Elem()
+	for _, s := range envSettings() {
+		val, tag := formatValue(fields.Field(s.index))
+		if s.secret && val != "" {
+			val = redacted
+		}
+		doc.Content = append(doc.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: s.fileKey()},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: val},
+		)
+	}
+
+	enc := yaml.NewEncoder(w)
+	err := enc.Encode(doc)
+	if err != nil {
+		return err
 	}
+	return enc.Close()
 }
 
-func getEnvWithFallback(key string, defaultValue string) string {
-	val := os.Getenv(key)
-	if val == "" {
-		val = defaultValue
+func formatValue(field reflect.Value) (string, string) {
+	switch val := field.Interface().(type) {
+	case time.Duration:
+		return val.String(), "!!str"
+	case slog.Level:
+		return strings.ToLower(val.String()), "!!str"
+	case string:
+		return val, "!!str"
+	case int:
+		return strconv.Itoa(val), "!!int"
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64), "!!float"
+	case bool:
+		return strconv.FormatBool(val), "!!bool"
 	}
-	return val
+	return fmt.Sprint(field.Interface()), "!!str"
 }
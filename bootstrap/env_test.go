@@ -0,0 +1,264 @@
+package bootstrap
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getenvFrom(vars map[string]string) func(string) string {
+	return func(key string) string {
+		return vars[key]
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("defaults in dev profile", func(t *testing.T) {
+		env, err := load([]string{"--profile", ProfileDev}, getenvFrom(nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, ProfileDev, env.Profile)
+		assert.Equal(t, ":8080", env.ServerAddress)
+		assert.Equal(t, "access token secret", env.AccessTokenSecret)
+		assert.Equal(t, 10*time.Minute, env.CacheTTL)
+		assert.Equal(t, 50, env.SearchHistorySize)
+		assert.Equal(t, 0.1, env.TracingSampleRatio)
+		assert.Equal(t, slog.LevelInfo, env.LogLevel)
+		assert.False(t, env.HealthCheckUpstream)
+		assert.False(t, env.PrintConfig)
+	})
+
+	t.Run("file then env then flags", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", `
+profile: dev
+cache_ttl: 1m
+search_history_size: 10
+favorites_limit: 20
+log_level: warn
+`)
+		env, err := load(
+			[]string{"--config", path, "--favorites-limit", "30"},
+			getenvFrom(map[string]string{"SEARCH_HISTORY_SIZE": "15", "FAVORITES_LIMIT": "25"}),
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, env.CacheTTL)
+		assert.Equal(t, 15, env.SearchHistorySize)
+		assert.Equal(t, 30, env.FavoritesLimit)
+		assert.Equal(t, slog.LevelWarn, env.LogLevel)
+	})
+
+	t.Run("config file from env", func(t *testing.T) {
+		path := writeFile(t, "config.toml", `
+profile = "dev"
+tracing_sample_ratio = 0.5
+health_check_upstream = true
+`)
+		env, err := load(nil, getenvFrom(map[string]string{"CONFIG_FILE": path}))
+
+		require.NoError(t, err)
+		assert.Equal(t, 0.5, env.TracingSampleRatio)
+		assert.True(t, env.HealthCheckUpstream)
+	})
+
+	t.Run("all errors reported together", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", `
+profile: dev
+cache_size: 10
+webhook_timeout: [1s]
+`)
+		_, err := load(
+			[]string{"--config", path, "--search-history-size", "0"},
+			getenvFrom(map[string]string{"CACHE_TTL": "ten minutes", "FIDIBO_BREAKER_ERROR_RATE": "2", "LOG_LEVEL": "loud", "FIDIBO_MAX_CONCURRENT": "0"}),
+		)
+
+		require.Error(t, err)
+		msg := err.Error()
+		assert.Contains(t, msg, "cache_size: unknown setting in "+path)
+		assert.Contains(t, msg, "webhook_timeout: must be a single value in "+path)
+		assert.Contains(t, msg, `CACHE_TTL: "ten minutes" from the environment is not a valid duration`)
+		assert.Contains(t, msg, "FIDIBO_BREAKER_ERROR_RATE: must be between 0 and 1")
+		assert.Contains(t, msg, `LOG_LEVEL: "loud" from the environment is not a valid log level`)
+		assert.Contains(t, msg, "SEARCH_HISTORY_SIZE: must be greater than zero")
+		assert.Contains(t, msg, "FIDIBO_MAX_CONCURRENT: must be greater than zero")
+	})
+
+	t.Run("cross field validation", func(t *testing.T) {
+		_, err := load(
+			[]string{"--profile", ProfileDev, "--fidibo-breaker-min-requests", "30", "--webhook-retry-backoff", "2h", "--fidibo-quota-limit", "3", "--fidibo-quota-background-share", "0.3"},
+			getenvFrom(nil),
+		)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "FIDIBO_BREAKER_MIN_REQUESTS: must not be greater than FIDIBO_BREAKER_WINDOW")
+		assert.Contains(t, err.Error(), "WEBHOOK_RETRY_BACKOFF: must not be greater than WEBHOOK_MAX_RETRY_BACKOFF")
+		assert.Contains(t, err.Error(), "FIDIBO_QUOTA_BACKGROUND_SHARE: must allow background requests at least one request of FIDIBO_QUOTA_LIMIT")
+	})
+
+	t.Run("trusted proxies", func(t *testing.T) {
+		env, err := load([]string{"--profile", ProfileDev, "--trusted-proxies", "10.0.0.1, 192.168.0.0/16"}, getenvFrom(nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.1", "192.168.0.0/16"}, env.TrustedProxyList())
+
+		_, err = load([]string{"--profile", ProfileDev, "--trusted-proxies", "10.0.0.1,proxy"}, getenvFrom(nil))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `TRUSTED_PROXIES: "proxy" is not an IP address or CIDR`)
+	})
+
+	t.Run("system webhook", func(t *testing.T) {
+		_, err := load([]string{"--profile", ProfileDev, "--system-webhook-url", "https://example.com/hook"}, getenvFrom(nil))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "SYSTEM_WEBHOOK_SECRET: must be at least 16 characters when SYSTEM_WEBHOOK_URL is set")
+
+		env, err := load([]string{"--profile", ProfileDev, "--system-webhook-url", "https://example.com/hook"},
+			getenvFrom(map[string]string{"SYSTEM_WEBHOOK_SECRET": "0123456789abcdef"}))
+
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789abcdef", env.SystemWebhookSecret)
+	})
+
+	t.Run("refresh secret rotation grace", func(t *testing.T) {
+		env, err := load([]string{"--profile", ProfileDev, "--refresh-expiry", "72h"}, getenvFrom(nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, 15*time.Minute, env.SecretRotationGrace)
+		assert.Equal(t, 72*time.Hour, env.RefreshSecretRotationGrace)
+
+		env, err = load([]string{"--profile", ProfileDev, "--refresh-secret-rotation-grace", "0s"}, getenvFrom(nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), env.RefreshSecretRotationGrace)
+	})
+
+	t.Run("default secrets outside dev profile", func(t *testing.T) {
+		_, err := load(nil, getenvFrom(map[string]string{"ACCESS_SECRET": "a real secret"}))
+
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "ACCESS_SECRET")
+		assert.Contains(t, err.Error(), "REFRESH_SECRET: the default secret is only allowed in the dev profile")
+	})
+
+	t.Run("production with secrets", func(t *testing.T) {
+		env, err := load(nil, getenvFrom(map[string]string{
+			"ACCESS_SECRET":  "access",
+			"REFRESH_SECRET": "refresh",
+		}))
+
+		require.NoError(t, err)
+		assert.Equal(t, "production", env.Profile)
+		assert.Equal(t, "access", env.AccessTokenSecret)
+		assert.Equal(t, "refresh", env.RefreshTokenSecret)
+	})
+
+	t.Run("help", func(t *testing.T) {
+		_, err := load([]string{"--help"}, getenvFrom(nil))
+
+		assert.ErrorIs(t, err, flag.ErrHelp)
+	})
+
+	t.Run("missing config file", func(t *testing.T) {
+		_, err := load([]string{"--config", filepath.Join(t.TempDir(), "missing.yaml")}, getenvFrom(nil))
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestPrint(t *testing.T) {
+	env, err := load(
+		[]string{"--print-config", "--cache-ttl", "90s", "--log-level", "debug"},
+		getenvFrom(map[string]string{"ACCESS_SECRET": "access", "REFRESH_SECRET": "refresh"}),
+	)
+	require.NoError(t, err)
+	assert.True(t, env.PrintConfig)
+
+	buf := &bytes.Buffer{}
+	err = env.Print(buf)
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "access_secret: REDACTED\n")
+	assert.Contains(t, out, "refresh_secret: REDACTED\n")
+	assert.NotContains(t, out, "access\n")
+	assert.Contains(t, out, "cache_ttl: 1m30s\n")
+	assert.Contains(t, out, "log_level: debug\n")
+	assert.Contains(t, out, "tracing_endpoint: \"\"\n")
+
+	t.Run("output is a valid config file", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", out)
+
+		reloaded, err := load(
+			[]string{"--config", path},
+			getenvFrom(map[string]string{"ACCESS_SECRET": "access", "REFRESH_SECRET": "refresh"}),
+		)
+
+		require.NoError(t, err)
+		reloaded.PrintConfig = true
+		assert.Equal(t, env, reloaded)
+	})
+}
+
+func TestLoadFromFiles(t *testing.T) {
+	accessPath := writeFile(t, "access_secret", "access from file\n")
+	refreshPath := writeFile(t, "refresh_secret", "refresh from file")
+
+	t.Run("env", func(t *testing.T) {
+		env, err := load(nil, getenvFrom(map[string]string{
+			"ACCESS_SECRET_FILE":  accessPath,
+			"REFRESH_SECRET_FILE": refreshPath,
+		}))
+
+		require.NoError(t, err)
+		assert.Equal(t, "access from file", env.AccessTokenSecret)
+		assert.Equal(t, "refresh from file", env.RefreshTokenSecret)
+	})
+
+	t.Run("config file", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "access_secret_file: "+accessPath+"\nrefresh_secret: refresh\n")
+
+		env, err := load([]string{"--config", path}, getenvFrom(nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, "access from file", env.AccessTokenSecret)
+		assert.Equal(t, "refresh", env.RefreshTokenSecret)
+	})
+
+	t.Run("env overrides file in config file", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "access_secret_file: "+accessPath+"\nrefresh_secret: refresh\n")
+
+		env, err := load([]string{"--config", path}, getenvFrom(map[string]string{"ACCESS_SECRET": "access"}))
+
+		require.NoError(t, err)
+		assert.Equal(t, "access", env.AccessTokenSecret)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "access_secret: access\naccess_secret_file: "+accessPath+"\n")
+
+		_, err := load([]string{"--config", path}, getenvFrom(map[string]string{
+			"REFRESH_SECRET":      "refresh",
+			"REFRESH_SECRET_FILE": refreshPath,
+			"CACHE_TTL_FILE":      accessPath + ".missing",
+		}))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ACCESS_SECRET: only one of access_secret and access_secret_file can be set in "+path)
+		assert.Contains(t, err.Error(), "REFRESH_SECRET: only one of REFRESH_SECRET and REFRESH_SECRET_FILE can be set")
+		assert.Contains(t, err.Error(), "CACHE_TTL_FILE: open "+accessPath+".missing")
+	})
+}
@@ -0,0 +1,65 @@
+package bootstrap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigFile(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		path := writeFile(t, "config.yml", "server_address: \":8081\"\nfavorites_limit: 10\n")
+
+		settings, err := readConfigFile(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"server_address": ":8081", "favorites_limit": 10}, settings)
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		path := writeFile(t, "config.toml", "server_address = \":8081\"\nfavorites_limit = 10\n")
+
+		settings, err := readConfigFile(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"server_address": ":8081", "favorites_limit": int64(10)}, settings)
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		path := writeFile(t, "config.json", "{}")
+
+		_, err := readConfigFile(path)
+
+		assert.ErrorContains(t, err, "must have a .yaml, .yml or .toml extension")
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "server_address: [")
+
+		_, err := readConfigFile(path)
+
+		assert.ErrorContains(t, err, "could not parse config file")
+	})
+}
+
+func TestScalarString(t *testing.T) {
+	for _, tc := range []struct {
+		in   any
+		want string
+		ok   bool
+	}{
+		{"10m", "10m", true},
+		{10, "10", true},
+		{int64(10), "10", true},
+		{0.25, "0.25", true},
+		{true, "true", true},
+		{[]any{"a"}, "", false},
+		{map[string]any{"a": 1}, "", false},
+		{nil, "", false},
+	} {
+		got, ok := scalarString(tc.in)
+		assert.Equal(t, tc.want, got)
+		assert.Equal(t, tc.ok, ok)
+	}
+}
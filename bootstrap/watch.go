@@ -0,0 +1,61 @@
+package bootstrap
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Watch loads the configuration with args every interval until ctx is done, picking up changes to the config
+// file and the files of the _FILE settings. apply is called with the previous and the new configuration
+// whenever a setting changed. Invalid configurations are logged and skipped, and settings that are not
+// reloadable are reported as needing a restart.
+func Watch(ctx context.Context, args []string, env *Env, interval time.Duration, apply func(prev, next *Env), logger *slog.Logger) {
+	watch(ctx, func() (*Env, error) { return Load(args) }, env, interval, apply, logger)
+}
+
+func watch(ctx context.Context, load func() (*Env, error), env *Env, interval time.Duration, apply func(prev, next *Env), logger *slog.Logger) {
+	logger = logger.With("component", "config_watcher")
+	reloadable := map[string]bool{}
+	for _, s := range envSettings() {
+		reloadable[s.key] = s.reload
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	current := env
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		next, err := load()
+		if err != nil {
+			logger.ErrorContext(ctx, "could not reload configuration", "error", err)
+			continue
+		}
+		next.PrintConfig = current.PrintConfig
+
+		changed := current.Changed(next)
+		if len(changed) == 0 {
+			continue
+		}
+
+		var restart []string
+		for _, key := range changed {
+			if !reloadable[key] {
+				restart = append(restart, key)
+			}
+		}
+		logger.InfoContext(ctx, "configuration reloaded", "changed", changed)
+		if len(restart) > 0 {
+			logger.WarnContext(ctx, "changed settings take effect after a restart", "settings", restart)
+		}
+
+		apply(current, next)
+		current = next
+	}
+}
@@ -0,0 +1,83 @@
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replaceFile swaps the file in one step, so the watcher never reads it half written.
+func replaceFile(t *testing.T, path, content string) {
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(content), 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func TestWatch(t *testing.T) {
+	secretPath := writeFile(t, "access_secret", "first secret\n")
+	configPath := writeFile(t, "config.yaml", "cache_ttl: 1m\n")
+	vars := map[string]string{
+		"CONFIG_FILE":        configPath,
+		"ACCESS_SECRET_FILE": secretPath,
+		"REFRESH_SECRET":     "refresh",
+	}
+	loadEnv := func() (*Env, error) { return load(nil, getenvFrom(vars)) }
+
+	env, err := loadEnv()
+	require.NoError(t, err)
+	require.Equal(t, "first secret", env.AccessTokenSecret)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	logs := &bytes.Buffer{}
+	applied := make(chan [2]*Env)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watch(ctx, loadEnv, env, 5*time.Millisecond, func(prev, next *Env) {
+			applied <- [2]*Env{prev, next}
+		}, slog.New(slog.NewTextHandler(logs, nil)))
+	}()
+
+	t.Run("secret file changed", func(t *testing.T) {
+		replaceFile(t, secretPath, "second secret\n")
+
+		envs := <-applied
+		assert.Equal(t, "first secret", envs[0].AccessTokenSecret)
+		assert.Equal(t, "second secret", envs[1].AccessTokenSecret)
+	})
+
+	t.Run("invalid config is skipped", func(t *testing.T) {
+		replaceFile(t, configPath, "cache_ttl: never\n")
+		time.Sleep(30 * time.Millisecond)
+		replaceFile(t, configPath, "cache_ttl: 2m\nsearch_history_size: 5\n")
+
+		envs := <-applied
+		assert.Equal(t, time.Minute, envs[0].CacheTTL)
+		assert.Equal(t, 2*time.Minute, envs[1].CacheTTL)
+		assert.Equal(t, "second secret", envs[1].AccessTokenSecret)
+	})
+
+	cancel()
+	<-done
+
+	assert.Contains(t, logs.String(), "could not reload configuration")
+	assert.Contains(t, logs.String(), `CACHE_TTL: \"never\" from `+configPath+` is not a valid duration`)
+	assert.Contains(t, logs.String(), "changed settings take effect after a restart")
+	assert.Contains(t, logs.String(), "settings=[SEARCH_HISTORY_SIZE]")
+}
+
+func TestChanged(t *testing.T) {
+	a, err := load([]string{"--profile", ProfileDev}, getenvFrom(nil))
+	require.NoError(t, err)
+	b, err := load([]string{"--profile", ProfileDev, "--log-level", "debug", "--cache-ttl", "1m"}, getenvFrom(nil))
+	require.NoError(t, err)
+
+	assert.Empty(t, a.Changed(a))
+	assert.Equal(t, []string{"CACHE_TTL", "LOG_LEVEL"}, a.Changed(b))
+}
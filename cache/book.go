@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	bookIDKeyPrefix   = "book:id:"
+	bookSlugKeyPrefix = "book:slug:"
+)
+
+type BookCacher interface {
+	Get(ctx context.Context, lookup domain.BookLookup) (domain.Book, error)
+	Store(ctx context.Context, books []domain.Book) error
+}
+
+type redisBookCache struct {
+	redisClient *redis.Client
+	ttl         *live.Duration
+}
+
+func (rc *redisBookCache) Get(ctx context.Context, lookup domain.BookLookup) (domain.Book, error) {
+	book, err := rc.get(ctx, lookup)
+	recordGet(bookCacheName, err)
+	return book, err
+}
+
+func (rc *redisBookCache) get(ctx context.Context, lookup domain.BookLookup) (domain.Book, error) {
+	id := lookup.ID
+	if id == "" {
+		var err error
+		id, err = rc.redisClient.Get(ctx, bookSlugKeyPrefix+lookup.Slug).Result()
+		if err != nil {
+			return domain.Book{}, err
+		}
+	}
+
+	val, err := rc.redisClient.Get(ctx, bookIDKeyPrefix+id).Result()
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	book := domain.Book{}
+	err = json.Unmarshal([]byte(val), &book)
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	return book, nil
+}
+
+// Store caches every book under its ID and maps its slug to the ID. Search specific fields like the score
+// and highlights are dropped since they do not describe the book itself.
+func (rc *redisBookCache) Store(ctx context.Context, books []domain.Book) error {
+	pipe := rc.redisClient.Pipeline()
+
+	for _, book := range books {
+		if book.ID == "" {
+			continue
+		}
+		book.Score = 0
+		book.Highlight = nil
+
+		data, err := json.Marshal(book)
+		if err != nil {
+			return err
+		}
+
+		pipe.Set(ctx, bookIDKeyPrefix+book.ID, data, rc.ttl.Load())
+		if book.Slug != "" {
+			pipe.Set(ctx, bookSlugKeyPrefix+book.Slug, book.ID, rc.ttl.Load())
+		}
+	}
+
+	if pipe.Len() == 0 {
+		return nil
+	}
+	_, err := pipe.Exec(ctx)
+	recordStore(bookCacheName, err)
+	return err
+}
+
+func NewBookCacher(redisClient *redis.Client, ttl *live.Duration) BookCacher {
+	return &redisBookCache{
+		redisClient: redisClient,
+		ttl:         ttl,
+	}
+}
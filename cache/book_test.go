@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookStore(t *testing.T) {
+	t.Run("successful store", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		cache := NewBookCacher(db, live.NewDuration(ttl))
+
+		books := []domain.Book{
+			{ID: "123", Title: "test title", Slug: "test", Score: 1.5, Highlight: map[string][]string{"title": {"<em>test</em>"}}},
+			{ID: "456", Title: "no slug"},
+			{Title: "no id"},
+		}
+		first, err := json.Marshal(domain.Book{ID: "123", Title: "test title", Slug: "test"})
+		assert.NoError(t, err)
+		second, err := json.Marshal(domain.Book{ID: "456", Title: "no slug"})
+		assert.NoError(t, err)
+
+		mock.ExpectSet("book:id:123", first, ttl).SetVal("OK")
+		mock.ExpectSet("book:slug:test", "123", ttl).SetVal("OK")
+		mock.ExpectSet("book:id:456", second, ttl).SetVal("OK")
+
+		err = cache.Store(context.TODO(), books)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed store", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		cache := NewBookCacher(db, live.NewDuration(ttl))
+
+		data, err := json.Marshal(domain.Book{ID: "123"})
+		assert.NoError(t, err)
+
+		errorMsg := "redis error"
+		mock.ExpectSet("book:id:123", data, ttl).SetErr(errors.New(errorMsg))
+
+		err = cache.Store(context.TODO(), []domain.Book{{ID: "123"}})
+		assert.ErrorContains(t, err, errorMsg)
+	})
+}
+
+func TestBookGet(t *testing.T) {
+	book := domain.Book{ID: "123", Title: "test title", Slug: "test"}
+	data, err := json.Marshal(book)
+	assert.NoError(t, err)
+
+	t.Run("by id", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		cache := NewBookCacher(db, live.NewDuration(ttl))
+
+		mock.ExpectGet("book:id:123").SetVal(string(data))
+
+		res, err := cache.Get(context.TODO(), domain.BookLookup{ID: "123"})
+		assert.NoError(t, err)
+		assert.Equal(t, book, res)
+	})
+
+	t.Run("by slug", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		cache := NewBookCacher(db, live.NewDuration(ttl))
+
+		mock.ExpectGet("book:slug:test").SetVal("123")
+		mock.ExpectGet("book:id:123").SetVal(string(data))
+
+		res, err := cache.Get(context.TODO(), domain.BookLookup{Slug: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, book, res)
+	})
+
+	t.Run("unknown slug", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		cache := NewBookCacher(db, live.NewDuration(ttl))
+
+		mock.ExpectGet("book:slug:test").RedisNil()
+
+		_, err := cache.Get(context.TODO(), domain.BookLookup{Slug: "test"})
+		assert.ErrorIs(t, err, redis.Nil)
+	})
+
+	t.Run("unmarshal error", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		cache := NewBookCacher(db, live.NewDuration(ttl))
+
+		mock.ExpectGet("book:id:123").SetVal("invalid")
+
+		_, err := cache.Get(context.TODO(), domain.BookLookup{ID: "123"})
+		assert.Error(t, err)
+	})
+}
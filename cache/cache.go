@@ -3,16 +3,13 @@ package cache
 import (
 	"context"
 	"encoding/json"
-	"time"
 
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/tracing"
 	"github.com/redis/go-redis/v9"
 )
 
-const (
-	ttl = 10 * time.Minute
-)
-
 type Cacher interface {
 	Get(ctx context.Context, key string) (domain.SearchResult, error)
 	Store(ctx context.Context, key string, value domain.SearchResult) error
@@ -20,9 +17,18 @@ type Cacher interface {
 
 type redisCache struct {
 	redisClient *redis.Client
+	ttl         *live.Duration
 }
 
 func (rc *redisCache) Get(ctx context.Context, key string) (domain.SearchResult, error) {
+	ctx, span := startSpan(ctx, searchCacheName, operationGet, key)
+	res, err := rc.get(ctx, key)
+	recordGet(searchCacheName, err)
+	endGetSpan(span, err)
+	return res, err
+}
+
+func (rc *redisCache) get(ctx context.Context, key string) (domain.SearchResult, error) {
 	val, err := rc.redisClient.Get(ctx, key).Result()
 	if err != nil {
 		return domain.SearchResult{}, err
@@ -38,16 +44,23 @@ func (rc *redisCache) Get(ctx context.Context, key string) (domain.SearchResult,
 }
 
 func (rc *redisCache) Store(ctx context.Context, key string, val domain.SearchResult) error {
+	ctx, span := startSpan(ctx, searchCacheName, operationStore, key)
+
 	data, err := json.Marshal(val)
 	if err != nil {
+		tracing.End(span, err)
 		return err
 	}
 
-	return rc.redisClient.Set(ctx, key, data, ttl).Err()
+	err = rc.redisClient.Set(ctx, key, data, rc.ttl.Load()).Err()
+	recordStore(searchCacheName, err)
+	tracing.End(span, err)
+	return err
 }
 
-func NewCacher(redisClient *redis.Client) Cacher {
+func NewCacher(redisClient *redis.Client, ttl *live.Duration) Cacher {
 	return &redisCache{
 		redisClient: redisClient,
+		ttl:         ttl,
 	}
 }
@@ -5,18 +5,22 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redismock/v9"
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
 
+const ttl = 10 * time.Minute
+
 func TestStore(t *testing.T) {
 	t.Run("successful store", func(t *testing.T) {
 		db, mock := redismock.NewClientMock()
 
-		cache := NewCacher(db)
+		cache := NewCacher(db, live.NewDuration(ttl))
 
 		key := "key1"
 		val := domain.SearchResult{
@@ -51,7 +55,7 @@ func TestStore(t *testing.T) {
 	t.Run("failed store", func(t *testing.T) {
 		db, mock := redismock.NewClientMock()
 
-		cache := NewCacher(db)
+		cache := NewCacher(db, live.NewDuration(ttl))
 
 		key := "key1"
 		val := domain.SearchResult{
@@ -91,7 +95,7 @@ func TestGet(t *testing.T) {
 	t.Run("successful get", func(t *testing.T) {
 		db, mock := redismock.NewClientMock()
 
-		cache := NewCacher(db)
+		cache := NewCacher(db, live.NewDuration(ttl))
 
 		key := "key1"
 		val := domain.SearchResult{
@@ -124,7 +128,7 @@ func TestGet(t *testing.T) {
 	t.Run("key not found", func(t *testing.T) {
 		db, mock := redismock.NewClientMock()
 
-		cache := NewCacher(db)
+		cache := NewCacher(db, live.NewDuration(ttl))
 
 		key := "key1"
 
@@ -138,7 +142,7 @@ func TestGet(t *testing.T) {
 	t.Run("other redis error", func(t *testing.T) {
 		db, mock := redismock.NewClientMock()
 
-		cache := NewCacher(db)
+		cache := NewCacher(db, live.NewDuration(ttl))
 
 		key := "key1"
 		errorMsg := "other error"
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"errors"
+
+	"github.com/kavehjamshidi/fidibo-challenge/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	searchCacheName = "search"
+	bookCacheName   = "book"
+
+	operationGet   = "get"
+	operationStore = "store"
+)
+
+func recordGet(cache string, err error) {
+	result := metrics.ResultHit
+	switch {
+	case errors.Is(err, redis.Nil):
+		result = metrics.ResultMiss
+	case err != nil:
+		result = metrics.ResultError
+	}
+	metrics.CacheRequests.WithLabelValues(cache, operationGet, result).Inc()
+}
+
+func recordStore(cache string, err error) {
+	result := metrics.ResultSuccess
+	if err != nil {
+		result = metrics.ResultError
+	}
+	metrics.CacheRequests.WithLabelValues(cache, operationStore, result).Inc()
+}
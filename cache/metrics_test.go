@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheMetrics(t *testing.T) {
+	hits := metrics.CacheRequests.WithLabelValues(searchCacheName, operationGet, metrics.ResultHit)
+	misses := metrics.CacheRequests.WithLabelValues(searchCacheName, operationGet, metrics.ResultMiss)
+	failures := metrics.CacheRequests.WithLabelValues(searchCacheName, operationGet, metrics.ResultError)
+	hitsBefore, missesBefore, failuresBefore := testutil.ToFloat64(hits), testutil.ToFloat64(misses), testutil.ToFloat64(failures)
+
+	db, mock := redismock.NewClientMock()
+	cache := NewCacher(db, live.NewDuration(ttl))
+
+	mock.ExpectGet("hit").SetVal(`{"books":[]}`)
+	mock.ExpectGet("miss").RedisNil()
+	mock.ExpectGet("error").SetErr(errors.New("redis error"))
+
+	_, _ = cache.Get(context.TODO(), "hit")
+	_, _ = cache.Get(context.TODO(), "miss")
+	_, _ = cache.Get(context.TODO(), "error")
+
+	assert.Equal(t, hitsBefore+1, testutil.ToFloat64(hits))
+	assert.Equal(t, missesBefore+1, testutil.ToFloat64(misses))
+	assert.Equal(t, failuresBefore+1, testutil.ToFloat64(failures))
+}
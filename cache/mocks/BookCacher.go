@@ -0,0 +1,68 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// BookCacher is an autogenerated mock type for the BookCacher type
+type BookCacher struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: ctx, lookup
+func (_m *BookCacher) Get(ctx context.Context, lookup domain.BookLookup) (domain.Book, error) {
+	ret := _m.Called(ctx, lookup)
+
+	var r0 domain.Book
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BookLookup) (domain.Book, error)); ok {
+		return rf(ctx, lookup)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BookLookup) domain.Book); ok {
+		r0 = rf(ctx, lookup)
+	} else {
+		r0 = ret.Get(0).(domain.Book)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.BookLookup) error); ok {
+		r1 = rf(ctx, lookup)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: ctx, books
+func (_m *BookCacher) Store(ctx context.Context, books []domain.Book) error {
+	ret := _m.Called(ctx, books)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.Book) error); ok {
+		r0 = rf(ctx, books)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewBookCacher interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewBookCacher creates a new instance of BookCacher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewBookCacher(t mockConstructorTestingTNewBookCacher) *BookCacher {
+	mock := &BookCacher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
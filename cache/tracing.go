@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kavehjamshidi/fidibo-challenge/internal/tracing"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const cacheHitKey = attribute.Key("cache.hit")
+
+func startSpan(ctx context.Context, cache, operation, key string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "cache."+cache+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemRedis,
+			semconv.DBOperation(operation),
+			attribute.String("cache.key", key),
+		),
+	)
+}
+
+// endGetSpan ends the span of a lookup, recording a miss as an attribute rather than an error.
+func endGetSpan(span trace.Span, err error) {
+	if errors.Is(err, redis.Nil) {
+		span.SetAttributes(cacheHitKey.Bool(false))
+		err = nil
+	} else if err == nil {
+		span.SetAttributes(cacheHitKey.Bool(true))
+	}
+	tracing.End(span, err)
+}
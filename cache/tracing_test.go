@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestCacheTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(tracing.NewTracerProvider(sdktrace.WithSyncer(exporter), 1))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	db, mock := redismock.NewClientMock()
+	cache := NewCacher(db, live.NewDuration(ttl))
+
+	mock.ExpectGet("hit").SetVal(`{"books":[]}`)
+	mock.ExpectGet("miss").RedisNil()
+	mock.ExpectGet("error").SetErr(errors.New("redis error"))
+
+	_, _ = cache.Get(context.TODO(), "hit")
+	_, _ = cache.Get(context.TODO(), "miss")
+	_, _ = cache.Get(context.TODO(), "error")
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 3)
+	for _, span := range spans {
+		assert.Equal(t, "cache.search.get", span.Name)
+	}
+	assert.Contains(t, spans[0].Attributes, cacheHitKey.Bool(true))
+	assert.Contains(t, spans[1].Attributes, cacheHitKey.Bool(false))
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+	assert.Equal(t, codes.Error, spans[2].Status.Code)
+}
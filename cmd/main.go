@@ -2,14 +2,36 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kavehjamshidi/fidibo-challenge/api/controllers"
 	"github.com/kavehjamshidi/fidibo-challenge/api/routes"
+	"github.com/kavehjamshidi/fidibo-challenge/api/server"
 	"github.com/kavehjamshidi/fidibo-challenge/bootstrap"
 	"github.com/kavehjamshidi/fidibo-challenge/cache"
 	"github.com/kavehjamshidi/fidibo-challenge/db"
+	"github.com/kavehjamshidi/fidibo-challenge/docs"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/logging"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/metrics"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/token"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/tracing"
 	"github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/ratelimit"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/webhook"
+	"github.com/kavehjamshidi/fidibo-challenge/repository"
 	"github.com/kavehjamshidi/fidibo-challenge/service"
 )
 
@@ -19,37 +41,276 @@ const (
 )
 
 func main() {
-	env := bootstrap.NewEnv()
+	env, err := bootstrap.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+	if env.PrintConfig {
+		err = env.Print(os.Stdout)
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(env.LogLevel)
+	logger := logging.New(os.Stdout, logLevel)
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Endpoint:    env.TracingEndpoint,
+		Insecure:    env.TracingInsecure,
+		SampleRatio: env.TracingSampleRatio,
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer shutdownTracing(context.Background())
+
+	accessTokenKeys := token.NewKeyring(env.AccessTokenSecret, env.SecretRotationGrace)
+	refreshTokenKeys := token.NewKeyring(env.RefreshTokenSecret, env.RefreshSecretRotationGrace)
+	cacheTTL := live.NewDuration(env.CacheTTL)
+	bookCacheTTL := live.NewDuration(env.BookCacheTTL)
+	suggestCacheTTL := live.NewDuration(env.SuggestCacheTTL)
 
 	redisClient := db.NewRedisClient(context.Background(), env.RedisAddress)
-	cache := cache.NewCacher(redisClient)
+	bookCache := cache.NewBookCacher(redisClient, bookCacheTTL)
+	cache := cache.NewCacher(redisClient, cacheTTL)
+	suggestionRepository := repository.NewSuggestionRepository(redisClient)
+	historyRepository := repository.NewHistoryRepository(redisClient, env.SearchHistorySize)
+	favoriteRepository := repository.NewFavoriteRepository(redisClient, env.FavoritesLimit)
+	savedSearchRepository := repository.NewSavedSearchRepository(redisClient, env.SavedSearchesLimit)
+	notificationRepository := repository.NewNotificationRepository(redisClient, env.NotificationsSize)
+	userRepository := repository.NewUserRepository(redisClient)
+	webhookRepository := repository.NewWebhookRepository(redisClient, env.WebhooksLimit)
+	webhookGuard := webhook.NewGuard(env.WebhookAllowPrivate)
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(redisClient, env.WebhookDeliveryLogSize, env.WebhookDeliveryRetention)
 
-	fidiboClient := fidibosearch.NewFidiboSearcher(fidiboQueryKey, fidiboSearchURL)
+	err = service.SetupSystemWebhook(context.Background(), webhookRepository, webhookGuard, env.SystemWebhookURL, env.SystemWebhookSecret)
+	if err != nil {
+		panic(err)
+	}
+	webhookPublisher := service.NewWebhookPublisher(webhookRepository, webhookDeliveryRepository)
+
+	fidiboSearcher := fidibosearch.NewFidiboSearcher(fidiboQueryKey, fidiboSearchURL, env.UpstreamTimeout)
+	if env.UpstreamQuotaLimit > 0 {
+		fidiboSearcher = fidibosearch.NewQuota(fidiboSearcher, redisClient, fidibosearch.QuotaConfig{
+			Limit:           env.UpstreamQuotaLimit,
+			Window:          env.UpstreamQuotaWindow,
+			MaxWait:         env.UpstreamQuotaMaxWait,
+			BackgroundShare: env.UpstreamQuotaBackgroundShare,
+		})
+	}
+	fidiboClient := fidibosearch.NewCircuitBreaker(
+		fidiboSearcher,
+		fidibosearch.BreakerConfig{
+			WindowSize:            env.BreakerWindowSize,
+			MinRequests:           env.BreakerMinRequests,
+			ErrorRateThreshold:    env.BreakerErrorRate,
+			SlowCallThreshold:     env.BreakerSlowCall,
+			SlowCallRateThreshold: env.BreakerSlowCallRate,
+			OpenTimeout:           env.BreakerOpenTimeout,
+			HalfOpenMaxCalls:      env.BreakerHalfOpenCalls,
+			MaxConcurrent:         env.UpstreamMaxConcurrent,
+			MaxQueueWait:          env.UpstreamMaxQueueWait,
+			OnStateChange: func(from, to fidibosearch.BreakerState) {
+				logger.Warn("fidibo search circuit breaker state changed", "from", from.String(), "to", to.String())
+				if to != fidibosearch.StateOpen {
+					return
+				}
+				err := webhookPublisher.Publish(context.Background(), domain.EventCircuitOpened, "", domain.CircuitOpenedEvent{
+					Upstream: fidiboSearchURL,
+					From:     from.String(),
+				})
+				if err != nil {
+					logger.Error("could not publish circuit opened event", "error", err)
+				}
+			},
+		})
 
 	loginSVC := service.NewLoginService(env.AccessTokenExpiry,
-		env.AccessTokenSecret,
+		accessTokenKeys,
 		env.RefreshTokenExpiry,
-		env.RefreshTokenSecret)
+		refreshTokenKeys,
+		userRepository,
+		webhookPublisher,
+		logger)
 	refreshTokenSVC := service.NewRefreshTokenService(env.AccessTokenExpiry,
-		env.AccessTokenSecret,
+		accessTokenKeys,
 		env.RefreshTokenExpiry,
-		env.RefreshTokenSecret)
-	searchSVC := service.NewSearchService(cache, fidiboClient)
+		refreshTokenKeys,
+		logger)
+	searchSVC := service.NewSearchService(cache, fidiboClient, suggestionRepository, bookCache, historyRepository, logger)
+	suggestSVC := service.NewSuggestService(suggestionRepository, env.SuggestBudget, suggestCacheTTL, logger)
+	bookSVC := service.NewBookService(bookCache, fidiboClient, logger)
+	catalogSVC := service.NewCatalogService(cache, fidiboClient, bookCache, logger)
+	historySVC := service.NewHistoryService(historyRepository, logger)
+	favoriteSVC := service.NewFavoriteService(favoriteRepository, bookSVC, logger)
+	savedSearchSVC := service.NewSavedSearchService(savedSearchRepository, webhookRepository, webhookGuard, logger)
+	notificationSVC := service.NewNotificationService(notificationRepository, logger)
+	webhookSVC := service.NewWebhookService(webhookRepository, webhookDeliveryRepository, webhookGuard, logger)
+	savedSearchRunner := service.NewSavedSearchRunner(savedSearchRepository, notificationRepository, fidiboClient, webhookPublisher, logger)
+	healthChecks := []service.HealthCheck{{
+		Name:  "redis",
+		Check: func(ctx context.Context) error { return redisClient.Ping(ctx).Err() },
+	}}
+	if env.HealthCheckUpstream {
+		probeClient := &http.Client{Timeout: env.HealthCheckTimeout}
+		healthChecks = append(healthChecks, service.HealthCheck{
+			Name:     "fidibo",
+			Optional: true,
+			Check: func(ctx context.Context) error {
+				if fidiboClient.State() == fidibosearch.StateOpen {
+					return errors.New("circuit open")
+				}
+				return fidibosearch.Probe(ctx, probeClient, fidiboSearchURL)
+			},
+		})
+	}
+	healthSVC := service.NewHealthService(healthChecks, env.HealthCheckTimeout, logger)
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepository, webhookDeliveryRepository, webhookGuard, service.WebhookDispatcherConfig{
+		Timeout:     env.WebhookTimeout,
+		MaxAttempts: env.WebhookMaxAttempts,
+		BaseBackoff: env.WebhookRetryBackoff,
+		MaxBackoff:  env.WebhookMaxRetryBackoff,
+		BatchSize:   env.WebhookBatchSize,
+	}, logger)
 
 	loginController := controllers.NewLoginController(loginSVC)
-	refreshTokenController := controllers.NewRefreshTokenController(refreshTokenSVC, env.RefreshTokenSecret)
-	searchController := controllers.NewSearchController(searchSVC)
+	refreshTokenController := controllers.NewRefreshTokenController(refreshTokenSVC, refreshTokenKeys)
+	searchController := controllers.NewSearchController(searchSVC, cacheTTL)
+	suggestController := controllers.NewSuggestController(suggestSVC)
+	bookController := controllers.NewBookController(bookSVC, bookCacheTTL)
+	catalogController := controllers.NewCatalogController(catalogSVC, cacheTTL)
+	historyController := controllers.NewHistoryController(historySVC)
+	favoriteController := controllers.NewFavoriteController(favoriteSVC)
+	savedSearchController := controllers.NewSavedSearchController(savedSearchSVC)
+	notificationController := controllers.NewNotificationController(notificationSVC)
+	webhookController := controllers.NewWebhookController(webhookSVC)
+	healthController := controllers.NewHealthController(healthSVC)
 	notFoundController := controllers.NewNotFoundController()
 
-	r := gin.Default()
+	r := gin.New()
+	err = r.SetTrustedProxies(env.TrustedProxyList())
+	if err != nil {
+		panic(err)
+	}
 
 	routes.Setup(r, routes.Controllers{
 		SearchController:       searchController,
+		SuggestController:      suggestController,
+		BookController:         bookController,
+		CatalogController:      catalogController,
+		HistoryController:      historyController,
+		FavoriteController:     favoriteController,
+		SavedSearchController:  savedSearchController,
+		NotificationController: notificationController,
+		WebhookController:      webhookController,
 		LoginController:        loginController,
 		RefreshTokenController: refreshTokenController,
-	}, env.AccessTokenSecret)
+		HealthController:       healthController,
+		DocsController:         controllers.NewDocsController(docs.OpenAPI),
+	}, accessTokenKeys, ratelimit.NewRedisLimiter(redisClient), routes.RateLimits{
+		Auth:   ratelimit.Limit{Requests: env.RateLimitAuthRequests, Window: env.RateLimitAuthWindow},
+		Search: ratelimit.Limit{Requests: env.RateLimitSearchRequests, Window: env.RateLimitSearchWindow},
+		API:    ratelimit.Limit{Requests: env.RateLimitAPIRequests, Window: env.RateLimitAPIWindow},
+	}, logger)
 
 	r.NoRoute(notFoundController.NotFound)
 
-	r.Run(env.ServerAddress)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		savedSearchRunner.Start(ctx, env.SavedSearchInterval)
+	}()
+	go func() {
+		defer workers.Done()
+		webhookDispatcher.Start(ctx, env.WebhookPollInterval)
+	}()
+	if env.ConfigWatchInterval > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			bootstrap.Watch(ctx, os.Args[1:], env, env.ConfigWatchInterval, func(prev, next *bootstrap.Env) {
+				accessTokenKeys.Rotate(next.AccessTokenSecret)
+				refreshTokenKeys.Rotate(next.RefreshTokenSecret)
+				cacheTTL.Store(next.CacheTTL)
+				bookCacheTTL.Store(next.BookCacheTTL)
+				suggestCacheTTL.Store(next.SuggestCacheTTL)
+				if next.LogLevel != prev.LogLevel {
+					logLevel.Set(next.LogLevel)
+				}
+			}, logger)
+		}()
+	}
+
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/metrics", metrics.Handler())
+	adminMux.Handle("/log-level", logging.LevelHandler(logLevel))
+	adminMux.Handle("/breaker", fidibosearch.StatsHandler(fidiboClient))
+	adminServer := &http.Server{
+		Handler:           adminMux,
+		ReadHeaderTimeout: env.ServerReadHeaderTimeout,
+	}
+	adminLn, err := net.Listen("tcp", env.AdminAddress)
+	if err != nil {
+		panic(err)
+	}
+	logger.Info("admin listening", "address", adminLn.Addr().String())
+	// Without the admin listener the service cannot be observed, so it shuts down when that one fails.
+	var adminFailed atomic.Bool
+	go func() {
+		err := adminServer.Serve(adminLn)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("admin server stopped", "error", err)
+			adminFailed.Store(true)
+			stop()
+		}
+	}()
+
+	serverConfig := server.Config{
+		ReadTimeout:       env.ServerReadTimeout,
+		ReadHeaderTimeout: env.ServerReadHeaderTimeout,
+		WriteTimeout:      env.ServerWriteTimeout,
+		IdleTimeout:       env.ServerIdleTimeout,
+		MaxHeaderBytes:    env.ServerMaxHeaderBytes,
+		DrainDelay:        env.ShutdownDrainDelay,
+		ShutdownTimeout:   env.ShutdownTimeout,
+	}
+	ln, err := net.Listen("tcp", env.ServerAddress)
+	if err != nil {
+		panic(err)
+	}
+	logger.Info("listening", "address", ln.Addr().String())
+
+	serveErr := server.Serve(ctx, server.New(r, serverConfig), ln, serverConfig, healthSVC.Drain, logger)
+	if serveErr != nil {
+		logger.Error("server stopped", "error", serveErr)
+	}
+	// Serve can return without a signal, so the workers are stopped here rather than only on SIGINT or SIGTERM.
+	stop()
+
+	workers.Wait()
+	adminServer.Close()
+	fidiboClient.Close()
+
+	err = redisClient.Close()
+	if err != nil {
+		logger.Error("could not close redis client", "error", err)
+	}
+	logger.Info("shutdown complete")
+
+	if serveErr != nil || adminFailed.Load() {
+		shutdownTracing(context.Background())
+		os.Exit(1)
+	}
 }
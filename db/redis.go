@@ -9,6 +9,9 @@ import (
 func NewRedisClient(ctx context.Context, addr string) *redis.Client {
 	rdb := redis.NewClient(&redis.Options{
 		Addr: addr,
+		// Without it, go-redis ignores context deadlines and waits for its own read and write timeouts, so the
+		// suggestion budget and the health check timeout would not bound Redis calls.
+		ContextTimeoutEnabled: true,
 	})
 
 	if err := rdb.Ping(ctx).Err(); err != nil {
@@ -0,0 +1,9 @@
+package docs
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI 3 document of the routes registered by routes.Setup. TestRoutesAreDocumented in
+// api/routes fails when a route is missing from it.
+//
+//go:embed openapi.json
+var OpenAPI []byte
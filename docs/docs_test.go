@@ -0,0 +1,77 @@
+package docs
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemasMatchDomain(t *testing.T) {
+	var spec struct {
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(OpenAPI, &spec))
+
+	types := []interface{}{
+		domain.ErrorResponse{}, domain.FieldError{},
+		domain.LoginRequest{}, domain.LoginResponse{}, domain.RefreshTokenRequest{}, domain.RefreshTokenResponse{},
+		domain.SearchRequest{}, domain.SearchResult{}, domain.Book{},
+		domain.Publisher{}, domain.Author{}, domain.Translator{}, domain.Narrator{}, domain.Category{},
+		domain.SuggestResponse{}, domain.Suggestion{},
+		domain.SearchHistory{}, domain.SearchHistoryEntry{}, domain.SearchHistoryPreference{},
+		domain.FavoriteRequest{}, domain.Favorite{}, domain.FavoritesResponse{},
+		domain.SavedSearchRequest{}, domain.SavedSearch{}, domain.SavedSearchesResponse{},
+		domain.Notification{}, domain.NotificationsResponse{},
+		domain.WebhookRequest{}, domain.Webhook{}, domain.WebhooksResponse{},
+		domain.WebhookDelivery{}, domain.WebhookDeliveriesResponse{},
+		domain.HealthResponse{}, domain.DependencyHealth{},
+	}
+	assert.Len(t, spec.Components.Schemas, len(types))
+
+	for _, v := range types {
+		typ := reflect.TypeOf(v)
+		schema, ok := spec.Components.Schemas[typ.Name()]
+		if !assert.True(t, ok, "missing schema for domain.%s", typ.Name()) {
+			continue
+		}
+
+		var fields, properties []string
+		for i := 0; i < typ.NumField(); i++ {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if name != "-" {
+				fields = append(fields, name)
+			}
+		}
+		for name := range schema.Properties {
+			properties = append(properties, name)
+		}
+		sort.Strings(fields)
+		sort.Strings(properties)
+		assert.Equal(t, fields, properties, "properties of %s", typ.Name())
+	}
+}
+
+func TestReferencesResolve(t *testing.T) {
+	var spec map[string]interface{}
+	require.NoError(t, json.Unmarshal(OpenAPI, &spec))
+
+	for _, match := range regexp.MustCompile(`"\$ref": "#/([^"]+)"`).FindAllStringSubmatch(string(OpenAPI), -1) {
+		var node interface{} = spec
+		for _, part := range strings.Split(match[1], "/") {
+			obj, _ := node.(map[string]interface{})
+			node = obj[part]
+		}
+		assert.NotNil(t, node, "unresolved reference %s", match[1])
+	}
+}
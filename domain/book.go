@@ -0,0 +1,17 @@
+package domain
+
+import "errors"
+
+var ErrBookNotFound = errors.New("book not found")
+
+type BookLookup struct {
+	ID   string
+	Slug string
+}
+
+func (l BookLookup) Matches(book Book) bool {
+	if l.ID != "" {
+		return book.ID == l.ID
+	}
+	return l.Slug != "" && book.Slug == l.Slug
+}
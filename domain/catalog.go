@@ -0,0 +1,7 @@
+package domain
+
+type CatalogRequest struct {
+	Name string `form:"-"`
+	Page int    `form:"page" binding:"omitempty,min=1"`
+	Size int    `form:"size" binding:"omitempty,min=1,max=50"`
+}
@@ -1,5 +1,12 @@
 package domain
 
-type ErrorResponse struct {
+type FieldError struct {
+	Field   string `json:"field"`
 	Message string `json:"message"`
 }
+
+type ErrorResponse struct {
+	Message   string       `json:"message"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
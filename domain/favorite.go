@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrFavoriteExists        = errors.New("book is already a favorite")
+	ErrFavoriteNotFound      = errors.New("favorite not found")
+	ErrFavoritesLimitReached = errors.New("favorites limit reached")
+)
+
+type FavoriteRequest struct {
+	BookID string `json:"book_id" binding:"required"`
+}
+
+type Favorite struct {
+	Book    Book      `json:"book"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+type FavoritesResponse struct {
+	Favorites []Favorite `json:"favorites"`
+}
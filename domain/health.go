@@ -0,0 +1,18 @@
+package domain
+
+const (
+	HealthStatusUp       = "up"
+	HealthStatusDown     = "down"
+	HealthStatusDraining = "draining"
+)
+
+type DependencyHealth struct {
+	Status    string  `json:"status"`
+	Optional  bool    `json:"optional,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+type HealthResponse struct {
+	Status string                      `json:"status"`
+	Checks map[string]DependencyHealth `json:"checks,omitempty"`
+}
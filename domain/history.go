@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+type SearchHistoryEntry struct {
+	Query       string    `json:"query"`
+	ResultCount int64     `json:"result_count"`
+	SearchedAt  time.Time `json:"searched_at"`
+}
+
+type SearchHistory struct {
+	Enabled bool                 `json:"enabled"`
+	Entries []SearchHistoryEntry `json:"entries"`
+}
+
+type SearchHistoryPreference struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
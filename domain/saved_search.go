@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrSavedSearchNotFound     = errors.New("saved search not found")
+	ErrSavedSearchLimitReached = errors.New("saved searches limit reached")
+)
+
+type SavedSearchRequest struct {
+	Name       string `json:"name" binding:"max=100"`
+	Keyword    string `json:"keyword"`
+	WebhookURL string `json:"webhook_url" binding:"max=2048"`
+}
+
+type SavedSearch struct {
+	ID            string    `json:"id"`
+	Username      string    `json:"-"`
+	Name          string    `json:"name"`
+	Keyword       string    `json:"keyword"`
+	WebhookURL    string    `json:"webhook_url,omitempty"`
+	WebhookID     string    `json:"webhook_id,omitempty"`
+	WebhookSecret string    `json:"webhook_secret,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type SavedSearchesResponse struct {
+	SavedSearches []SavedSearch `json:"saved_searches"`
+}
+
+type Notification struct {
+	ID            string    `json:"id"`
+	SavedSearchID string    `json:"saved_search_id"`
+	Name          string    `json:"name"`
+	Keyword       string    `json:"keyword"`
+	NewBooks      []Book    `json:"new_books"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type NotificationsResponse struct {
+	Notifications []Notification `json:"notifications"`
+}
@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type Publisher struct {
 	Title string `json:"title"`
 }
@@ -8,16 +13,69 @@ type Author struct {
 	Name string `json:"name"`
 }
 
+type Translator struct {
+	Name string `json:"name"`
+}
+
+type Narrator struct {
+	Name string `json:"name"`
+}
+
+type Category struct {
+	Title string `json:"title"`
+}
+
 type Book struct {
-	ImageName  string    `json:"image_name"`
-	Publishers Publisher `json:"publishers"`
-	ID         string    `json:"id"`
-	Title      string    `json:"title"`
-	Content    string    `json:"content"`
-	Slug       string    `json:"slug"`
-	Authors    []Author  `json:"authors"`
+	ImageName   string       `json:"image_name"`
+	Publishers  Publisher    `json:"publishers"`
+	ID          string       `json:"id"`
+	Title       string       `json:"title"`
+	Content     string       `json:"content"`
+	Slug        string       `json:"slug"`
+	Authors     []Author     `json:"authors"`
+	Translators []Translator `json:"translators,omitempty"`
+	Narrators   []Narrator   `json:"narrators,omitempty"`
+	Price       *float64     `json:"price,omitempty"`
+	Format      string       `json:"format,omitempty"`
+	PageCount   int          `json:"page_count,omitempty"`
+	PublishDate string       `json:"publish_date,omitempty"`
+	Rating      float64      `json:"rating,omitempty"`
+	Language    string       `json:"language,omitempty"`
+	Categories  []Category   `json:"categories,omitempty"`
+
+	Score     float64                    `json:"score,omitempty"`
+	Highlight map[string][]string        `json:"highlight,omitempty"`
+	Extra     map[string]json.RawMessage `json:"extra,omitempty"`
+}
+
+const (
+	FormatEbook     = "ebook"
+	FormatAudiobook = "audiobook"
+
+	SortRelevance = "relevance"
+	SortTitle     = "title"
+	SortDate      = "date"
+)
+
+type SearchRequest struct {
+	Keyword   string   `json:"keyword" form:"keyword"`
+	Page      int      `json:"page" form:"page" binding:"omitempty,min=1,max=1000"`
+	Size      int      `json:"size" form:"size" binding:"omitempty,min=1,max=50"`
+	Author    string   `json:"author" form:"author" binding:"max=100"`
+	Publisher string   `json:"publisher" form:"publisher" binding:"max=100"`
+	Format    string   `json:"format" form:"format" binding:"omitempty,oneof=ebook audiobook"`
+	MinPrice  *float64 `json:"min_price" form:"min_price" binding:"omitempty,min=0"`
+	MaxPrice  *float64 `json:"max_price" form:"max_price" binding:"omitempty,min=0"`
+	Language  string   `json:"language" form:"language" binding:"max=10"`
+	Sort      string   `json:"sort" form:"sort" binding:"omitempty,oneof=relevance title date"`
 }
 
 type SearchResult struct {
-	Books []Book `json:"books"`
+	Books      []Book    `json:"books"`
+	Total      int64     `json:"total"`
+	Page       int       `json:"page"`
+	Size       int       `json:"size"`
+	HasMore    bool      `json:"has_more"`
+	FetchedAt  time.Time `json:"fetched_at"`
+	DidYouMean string    `json:"did_you_mean,omitempty"`
 }
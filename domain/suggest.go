@@ -0,0 +1,20 @@
+package domain
+
+const (
+	SuggestionTypeTitle  = "title"
+	SuggestionTypeAuthor = "author"
+)
+
+type SuggestRequest struct {
+	Prefix string `form:"prefix"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=20"`
+}
+
+type Suggestion struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+type SuggestResponse struct {
+	Suggestions []Suggestion `json:"suggestions"`
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	EventSavedSearchNewResults = "saved_search.new_results"
+	EventUserRegistered        = "user.registered"
+	EventCircuitOpened         = "upstream.circuit_opened"
+)
+
+// SystemWebhookID is the ID of the webhook the operator configures for the events about the whole service.
+const SystemWebhookID = "system"
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusDead      = "dead"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookURLNotAllowed    = errors.New("webhook URL must resolve to a public address")
+	ErrWebhooksLimitReached    = errors.New("webhooks limit reached")
+)
+
+type WebhookRequest struct {
+	URL    string   `json:"url" binding:"max=2048"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=saved_search.new_results"`
+	Secret string   `json:"secret" binding:"omitempty,min=16,max=256"`
+	Active *bool    `json:"active"`
+}
+
+type Webhook struct {
+	ID            string    `json:"id"`
+	URL           string    `json:"url"`
+	Events        []string  `json:"events"`
+	Secret        string    `json:"secret,omitempty"`
+	Active        bool      `json:"active"`
+	CreatedBy     string    `json:"created_by"`
+	SavedSearchID string    `json:"saved_search_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (w Webhook) Subscribed(eventType string) bool {
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSystemEvent reports whether an event is about the whole service rather than one user. These are only sent
+// to the system webhook.
+func IsSystemEvent(eventType string) bool {
+	return eventType == EventUserRegistered || eventType == EventCircuitOpened
+}
+
+type WebhooksResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type SavedSearchResultsEvent struct {
+	Username     string       `json:"username"`
+	Notification Notification `json:"notification"`
+}
+
+type UserRegisteredEvent struct {
+	Username string `json:"username"`
+}
+
+type CircuitOpenedEvent struct {
+	Upstream string `json:"upstream"`
+	From     string `json:"from"`
+}
+
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhook_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
@@ -0,0 +1,25 @@
+package live
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Duration is a duration setting that can be changed while it is being read, e.g. on a configuration reload.
+type Duration struct {
+	ns atomic.Int64
+}
+
+func NewDuration(d time.Duration) *Duration {
+	v := &Duration{}
+	v.Store(d)
+	return v
+}
+
+func (v *Duration) Load() time.Duration {
+	return time.Duration(v.ns.Load())
+}
+
+func (v *Duration) Store(d time.Duration) {
+	v.ns.Store(int64(d))
+}
@@ -0,0 +1,28 @@
+package live
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuration(t *testing.T) {
+	d := NewDuration(time.Minute)
+	assert.Equal(t, time.Minute, d.Load())
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			d.Store(time.Duration(i) * time.Second)
+			_ = d.Load()
+		}(i)
+	}
+	wg.Wait()
+
+	d.Store(time.Hour)
+	assert.Equal(t, time.Hour, d.Load())
+}
@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kavehjamshidi/fidibo-challenge/internal/requestid"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("adds request and trace ids", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(buf, slog.LevelInfo).With("component", "test")
+
+		traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+		ctx := trace.ContextWithSpanContext(context.TODO(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}))
+		ctx = requestid.WithRequestID(ctx, "abc")
+
+		logger.ErrorContext(ctx, "search failed", "error", "upstream error")
+
+		record := map[string]interface{}{}
+		err := json.Unmarshal(buf.Bytes(), &record)
+		assert.NoError(t, err)
+		assert.Equal(t, "ERROR", record["level"])
+		assert.Equal(t, "search failed", record["msg"])
+		assert.Equal(t, "test", record["component"])
+		assert.Equal(t, "upstream error", record["error"])
+		assert.Equal(t, "abc", record[RequestIDKey])
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record[TraceIDKey])
+		assert.Equal(t, "00f067aa0ba902b7", record[SpanIDKey])
+	})
+
+	t.Run("filters by level", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		level := new(slog.LevelVar)
+		level.Set(slog.LevelWarn)
+		logger := New(buf, level)
+
+		logger.Info("hidden")
+		assert.Empty(t, buf.String())
+
+		level.Set(slog.LevelDebug)
+		logger.Debug("shown")
+		assert.Contains(t, buf.String(), "shown")
+	})
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel(" debug ")
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestLevelHandler(t *testing.T) {
+	level := new(slog.LevelVar)
+	handler := LevelHandler(level)
+
+	t.Run("get", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/log-level", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"level":"info"}`, w.Body.String())
+	})
+
+	t.Run("put", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/log-level", strings.NewReader(`{"level":"warn"}`)))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"level":"warn"}`, w.Body.String())
+		assert.Equal(t, slog.LevelWarn, level.Level())
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/log-level", strings.NewReader(`{"level":"verbose"}`)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, slog.LevelWarn, level.Level())
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/log-level", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "fidibo"
+
+const (
+	ResultHit      = "hit"
+	ResultMiss     = "miss"
+	ResultError    = "error"
+	ResultSuccess  = "success"
+	ResultFailure  = "failure"
+	ResultRejected = "rejected"
+	ResultDelayed  = "delayed"
+
+	TokenLogin   = "login"
+	TokenRefresh = "refresh"
+)
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to answer HTTP requests by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache operations by cache, operation and result.",
+	}, []string{"cache", "operation", "result"})
+
+	UpstreamRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Time taken by requests to search.fidibo.com by outcome.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 3, 5, 10},
+	}, []string{"outcome"})
+
+	UpstreamErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_errors_total",
+		Help:      "Failed or rejected requests to search.fidibo.com by reason.",
+	}, []string{"reason"})
+
+	UpstreamThrottled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_throttled_total",
+		Help:      "Requests to search.fidibo.com held by the shared quota by priority and result: delayed, rejected or error when the quota could not be checked.",
+	}, []string{"priority", "result"})
+
+	UpstreamQuotaWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_quota_wait_seconds",
+		Help:      "Time delayed requests to search.fidibo.com waited for the shared quota by priority.",
+		Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}, []string{"priority"})
+
+	UpstreamCircuitState = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "upstream_circuit_state",
+		Help:      "State of the circuit breaker in front of search.fidibo.com: 0 closed, 1 open, 2 half-open.",
+	})
+
+	Tokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_tokens_total",
+		Help:      "Token pairs requested on login and refresh, by result.",
+	}, []string{"operation", "result"})
+
+	RateLimitedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_requests_total",
+		Help:      "Requests rejected by the rate limiter, by route group.",
+	}, []string{"group"})
+)
+
+// Registry holds the metrics of the service along with the Go runtime and process metrics.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		CacheRequests,
+		UpstreamRequestDuration,
+		UpstreamErrors,
+		UpstreamThrottled,
+		UpstreamQuotaWait,
+		UpstreamCircuitState,
+		Tokens,
+		RateLimitedRequests,
+	)
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
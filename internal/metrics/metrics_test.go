@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	HTTPRequests.WithLabelValues(http.MethodGet, "/search/book", "200").Inc()
+	CacheRequests.WithLabelValues("search", "get", ResultHit).Inc()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	res, err := io.ReadAll(w.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, string(res), `fidibo_http_requests_total{method="GET",route="/search/book",status="200"} 1`)
+	assert.Contains(t, string(res), `fidibo_cache_requests_total{cache="search",operation="get",result="hit"} 1`)
+	assert.Contains(t, string(res), "go_goroutines")
+	assert.Contains(t, string(res), "process_cpu_seconds_total")
+}
@@ -0,0 +1,29 @@
+package normalize
+
+import (
+	"strings"
+	"unicode"
+)
+
+var digitReplacer = strings.NewReplacer(
+	"۰", "0", "۱", "1", "۲", "2", "۳", "3", "۴", "4",
+	"۵", "5", "۶", "6", "۷", "7", "۸", "8", "۹", "9",
+	"٠", "0", "١", "1", "٢", "2", "٣", "3", "٤", "4",
+	"٥", "5", "٦", "6", "٧", "7", "٨", "8", "٩", "9",
+)
+
+func Digits(s string) string {
+	return digitReplacer.Replace(s)
+}
+
+func Whitespace(s string) string {
+	return strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+}
+
+func Query(s string) string {
+	return Whitespace(Digits(s))
+}
+
+func HasControlCharacters(s string) bool {
+	return strings.IndexFunc(s, unicode.IsControl) >= 0
+}
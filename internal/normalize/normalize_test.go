@@ -0,0 +1,32 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "  کافکا  ", expected: "کافکا"},
+		{input: "۱۹۸۴", expected: "1984"},
+		{input: "١٩٨٤", expected: "1984"},
+		{input: "جنگ   و\tصلح", expected: "جنگ و صلح"},
+		{input: "می‌خواهم", expected: "می‌خواهم"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, Query(tc.input))
+	}
+}
+
+func TestHasControlCharacters(t *testing.T) {
+	assert.False(t, HasControlCharacters("کافکا"))
+	assert.False(t, HasControlCharacters("می‌خواهم"))
+	assert.True(t, HasControlCharacters("test\x00"))
+	assert.True(t, HasControlCharacters("te\x1bst"))
+	assert.True(t, HasControlCharacters("line\nbreak"))
+}
@@ -0,0 +1,33 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	id, ok := FromContext(WithRequestID(context.TODO(), "abc"))
+	assert.True(t, ok)
+	assert.Equal(t, "abc", id)
+
+	_, ok = FromContext(context.TODO())
+	assert.False(t, ok)
+}
+
+func TestNew(t *testing.T) {
+	id := New()
+	assert.Len(t, id, 32)
+	assert.True(t, Valid(id))
+	assert.NotEqual(t, id, New())
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid("3f2a-9c1e_req.1:2"))
+	assert.False(t, Valid(""))
+	assert.False(t, Valid("abc def"))
+	assert.False(t, Valid("abc\ninjected"))
+	assert.False(t, Valid(strings.Repeat("a", 129)))
+}
@@ -0,0 +1,116 @@
+package spell
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// layoutPenalty makes a switched layout lose ties against a correction of the query as it was typed.
+const layoutPenalty = 1
+
+type Corrector struct {
+	vocabulary map[string]float64
+}
+
+type candidate struct {
+	text    string
+	unknown int
+	cost    int
+}
+
+func (c candidate) betterThan(other candidate) bool {
+	if c.unknown != other.unknown {
+		return c.unknown < other.unknown
+	}
+	return c.cost < other.cost
+}
+
+// Correct returns the most likely intended query for a query that produced no results. Every word is matched
+// against the vocabulary as typed, with Arabic letters replaced by their Persian forms and as typed with the
+// other keyboard layout. It reports false when no variant contains more known words than the original.
+func (c *Corrector) Correct(query string) (string, bool) {
+	query = strings.ToLower(query)
+	original := c.correctWords(query, 0)
+
+	variants := []candidate{c.correctWords(Persian(query), 0)}
+	if switched, ok := SwitchLayout(query); ok {
+		variants = append(variants, c.correctWords(Persian(switched), layoutPenalty))
+	}
+
+	best := original
+	for _, variant := range variants {
+		if variant.betterThan(best) {
+			best = variant
+		}
+	}
+
+	if best.text == query || best.unknown >= c.unknownWords(query) {
+		return "", false
+	}
+	return best.text, true
+}
+
+func (c *Corrector) correctWords(query string, cost int) candidate {
+	words := strings.Fields(query)
+	result := candidate{cost: cost}
+
+	for i, word := range words {
+		corrected, distance, ok := c.correctWord(word)
+		if !ok {
+			result.unknown++
+			continue
+		}
+		words[i] = corrected
+		result.cost += distance
+	}
+
+	result.text = strings.Join(words, " ")
+	return result
+}
+
+func (c *Corrector) correctWord(word string) (string, int, bool) {
+	if _, ok := c.vocabulary[word]; ok {
+		return word, 0, true
+	}
+
+	limit := maxDistance(word)
+	if limit == 0 {
+		return "", 0, false
+	}
+
+	length := utf8.RuneCountInString(word)
+	best, bestDistance, bestScore := "", limit+1, 0.0
+	for term, score := range c.vocabulary {
+		diff := utf8.RuneCountInString(term) - length
+		if diff > limit || -diff > limit {
+			continue
+		}
+
+		d := Distance(word, term)
+		if d < bestDistance || d == bestDistance && (score > bestScore || score == bestScore && term < best) {
+			best, bestDistance, bestScore = term, d, score
+		}
+	}
+
+	if best == "" {
+		return "", 0, false
+	}
+	return best, bestDistance, true
+}
+
+func (c *Corrector) unknownWords(query string) int {
+	unknown := 0
+	for _, word := range strings.Fields(query) {
+		if _, ok := c.vocabulary[word]; !ok {
+			unknown++
+		}
+	}
+	return unknown
+}
+
+// NewCorrector builds a corrector over a vocabulary of lowercase words weighted by how often they occur.
+func NewCorrector(vocabulary map[string]float64) *Corrector {
+	return &Corrector{
+		vocabulary: vocabulary,
+	}
+}
@@ -0,0 +1,44 @@
+package spell
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrect(t *testing.T) {
+	vocabulary := map[string]float64{
+		"بوف":           3,
+		"کور":           3,
+		"کافکا":         5,
+		"ایران":         2,
+		"kafka":         4,
+		"shore":         1,
+		"metamorphosis": 2,
+	}
+	corrector := NewCorrector(vocabulary)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		ok       bool
+	}{
+		{"typo", "بوف کوز", "بوف کور", true},
+		{"transposition", "metamorhposis", "metamorphosis", true},
+		{"arabic letters", "كافكا", "کافکا", true},
+		{"english layout", "hdvhk", "ایران", true},
+		{"persian layout", "نشبنش", "kafka", true},
+		{"known query", "بوف کور", "", false},
+		{"unknown query", "xyzxyzxyz", "", false},
+		{"short words are not corrected", "بف", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corrected, ok := corrector.Correct(tt.query)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, corrected)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package spell
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// englishToPersian maps the keys of a QWERTY keyboard to the characters of the standard Persian layout.
+var englishToPersian = map[rune]rune{
+	'q': 'ض', 'w': 'ص', 'e': 'ث', 'r': 'ق', 't': 'ف', 'y': 'غ', 'u': 'ع', 'i': 'ه', 'o': 'خ', 'p': 'ح', '[': 'ج', ']': 'چ',
+	'a': 'ش', 's': 'س', 'd': 'ی', 'f': 'ب', 'g': 'ل', 'h': 'ا', 'j': 'ت', 'k': 'ن', 'l': 'م', ';': 'ک', '\'': 'گ',
+	'z': 'ظ', 'x': 'ط', 'c': 'ز', 'v': 'ر', 'b': 'ذ', 'n': 'د', 'm': 'پ', ',': 'و', 'C': 'ژ',
+}
+
+var persianToEnglish = invert(englishToPersian)
+
+var arabicReplacer = strings.NewReplacer(
+	"ي", "ی", "ى", "ی", "ئ", "ی", "ك", "ک", "ة", "ه", "أ", "ا", "إ", "ا", "ٱ", "ا",
+)
+
+func invert(m map[rune]rune) map[rune]rune {
+	inverted := make(map[rune]rune, len(m))
+	for k, v := range m {
+		inverted[v] = k
+	}
+	return inverted
+}
+
+// Persian replaces the Arabic forms of letters that Persian keyboards on some platforms still produce.
+func Persian(s string) string {
+	return arabicReplacer.Replace(s)
+}
+
+// SwitchLayout returns the text that would have been typed if s had been typed with the other keyboard
+// layout active. It reports false when s mixes characters of both layouts or uses keys outside of them.
+func SwitchLayout(s string) (string, bool) {
+	var b strings.Builder
+	var mapping map[rune]rune
+
+	for _, r := range s {
+		if r == ' ' {
+			b.WriteRune(r)
+			continue
+		}
+
+		if mapping == nil {
+			if _, ok := englishToPersian[r]; ok {
+				mapping = englishToPersian
+			} else if _, ok := persianToEnglish[r]; ok {
+				mapping = persianToEnglish
+			} else if _, ok := englishToPersian[toLower(r)]; ok {
+				mapping = englishToPersian
+			} else {
+				return "", false
+			}
+		}
+
+		mapped, ok := mapping[r]
+		if !ok {
+			mapped, ok = mapping[toLower(r)]
+		}
+		if !ok {
+			return "", false
+		}
+		b.WriteRune(mapped)
+	}
+
+	if mapping == nil {
+		return "", false
+	}
+	return b.String(), true
+}
+
+func toLower(r rune) rune {
+	if r >= 'A' && r <= 'Z' {
+		return r + 'a' - 'A'
+	}
+	return r
+}
+
+// Distance is the optimal string alignment distance between a and b, which counts a transposition of two
+// adjacent characters as a single edit.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(rb)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// maxDistance is how many edits a word of the given length may be away from a vocabulary word.
+func maxDistance(word string) int {
+	switch n := utf8.RuneCountInString(word); {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	default:
+		return 2
+	}
+}
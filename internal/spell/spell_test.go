@@ -0,0 +1,53 @@
+package spell
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"kafka", "kafka", 0},
+		{"kafka", "kafak", 1},
+		{"kafka", "kafk", 1},
+		{"kafka", "kavka", 1},
+		{"", "abc", 3},
+		{"مسخ", "مسح", 1},
+		{"بوف کور", "بوف کوز", 1},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Distance(tt.a, tt.b), tt.a+" -> "+tt.b)
+	}
+}
+
+func TestSwitchLayout(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"hdvhk", "ایران", true},
+		{"fDf ;Dv", "بیب کیر", true},
+		{"ایران", "hdvhk", true},
+		{"sghl lkd", "سلام منی", true},
+		{"kafka 2", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		switched, ok := SwitchLayout(tt.input)
+		assert.Equal(t, tt.ok, ok, tt.input)
+		assert.Equal(t, tt.expected, switched, tt.input)
+	}
+}
+
+func TestPersian(t *testing.T) {
+	assert.Equal(t, "کافکا در کرانه", Persian("كافكا در كرانه"))
+	assert.Equal(t, "زندگی", Persian("زندگي"))
+}
@@ -0,0 +1,72 @@
+package token
+
+import (
+	"sync"
+	"time"
+)
+
+// Keyring holds the secret used to sign new tokens. After a rotation the previous secret is still accepted
+// for a grace period, so tokens issued just before the rotation keep working.
+type Keyring interface {
+	Secret() string
+	Rotate(secret string)
+	ExtractUsername(signedToken string) (string, error)
+}
+
+type keyring struct {
+	mu            sync.RWMutex
+	current       string
+	previous      string
+	previousUntil time.Time
+	grace         time.Duration
+	now           func() time.Time
+}
+
+func (k *keyring) Secret() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current
+}
+
+// Rotate makes secret the current one. Rotating to the current secret does nothing.
+func (k *keyring) Rotate(secret string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if secret == k.current {
+		return
+	}
+	k.previous = k.current
+	k.previousUntil = k.now().Add(k.grace)
+	k.current = secret
+}
+
+// ExtractUsername verifies the token with the current secret, falling back to the previous one during the
+// grace period.
+func (k *keyring) ExtractUsername(signedToken string) (string, error) {
+	k.mu.RLock()
+	current, previous := k.current, k.previous
+	if !k.now().Before(k.previousUntil) {
+		previous = ""
+	}
+	k.mu.RUnlock()
+
+	username, err := ExtractUsername(signedToken, current)
+	if err == nil || previous == "" {
+		return username, err
+	}
+
+	username, prevErr := ExtractUsername(signedToken, previous)
+	if prevErr != nil {
+		return "", err
+	}
+	return username, nil
+}
+
+func NewKeyring(secret string, grace time.Duration) Keyring {
+	return &keyring{
+		current: secret,
+		grace:   grace,
+		now:     time.Now,
+	}
+}
@@ -0,0 +1,67 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	now := time.Now()
+	k := NewKeyring("old secret", time.Minute).(*keyring)
+	k.now = func() time.Time { return now }
+
+	oldToken, err := GenerateJWT("test", k.Secret(), 10*time.Minute)
+	require.NoError(t, err)
+
+	t.Run("current secret", func(t *testing.T) {
+		username, err := k.ExtractUsername(oldToken)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "test", username)
+	})
+
+	k.Rotate("new secret")
+	assert.Equal(t, "new secret", k.Secret())
+
+	newToken, err := GenerateJWT("test", k.Secret(), 10*time.Minute)
+	require.NoError(t, err)
+
+	t.Run("previous secret during grace period", func(t *testing.T) {
+		username, err := k.ExtractUsername(oldToken)
+		assert.NoError(t, err)
+		assert.Equal(t, "test", username)
+
+		username, err = k.ExtractUsername(newToken)
+		assert.NoError(t, err)
+		assert.Equal(t, "test", username)
+	})
+
+	t.Run("rotating to the same secret keeps the grace period", func(t *testing.T) {
+		k.Rotate("new secret")
+
+		_, err := k.ExtractUsername(oldToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("previous secret after grace period", func(t *testing.T) {
+		now = now.Add(time.Minute)
+
+		username, err := k.ExtractUsername(oldToken)
+		assert.ErrorContains(t, err, "signature is invalid")
+		assert.Empty(t, username)
+
+		_, err = k.ExtractUsername(newToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		k.Rotate("newest secret")
+
+		username, err := k.ExtractUsername("invalid token")
+		assert.Error(t, err)
+		assert.Empty(t, username)
+	})
+}
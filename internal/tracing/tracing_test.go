@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("disabled without an endpoint", func(t *testing.T) {
+		shutdown, err := Setup(context.TODO(), Config{})
+		assert.NoError(t, err)
+		assert.NoError(t, shutdown(context.TODO()))
+
+		header := http.Header{}
+		header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		ctx := otel.GetTextMapPropagator().Extract(context.TODO(), propagation.HeaderCarrier(header))
+
+		spanContext := trace.SpanContextFromContext(ctx)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanContext.TraceID().String())
+		assert.True(t, spanContext.IsRemote())
+	})
+}
+
+func TestNewTracerProvider(t *testing.T) {
+	t.Run("samples new traces by ratio", func(t *testing.T) {
+		exporter := tracetest.NewInMemoryExporter()
+		provider := NewTracerProvider(sdktrace.WithSyncer(exporter), 0)
+
+		_, span := provider.Tracer("test").Start(context.TODO(), "root")
+		span.End()
+
+		assert.False(t, span.SpanContext().IsSampled())
+		assert.Empty(t, exporter.GetSpans())
+	})
+
+	t.Run("follows the sampled parent", func(t *testing.T) {
+		exporter := tracetest.NewInMemoryExporter()
+		provider := NewTracerProvider(sdktrace.WithSyncer(exporter), 0)
+
+		traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+		parent := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+			Remote:     true,
+		})
+		ctx := trace.ContextWithRemoteSpanContext(context.TODO(), parent)
+
+		_, span := provider.Tracer("test").Start(ctx, "child")
+		span.End()
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, traceID, spans[0].SpanContext.TraceID())
+		assert.Equal(t, spanID, spans[0].Parent.SpanID())
+	})
+}
+
+func TestEnd(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewTracerProvider(sdktrace.WithSyncer(exporter), 1)
+
+	_, span := provider.Tracer("test").Start(context.TODO(), "failed")
+	End(span, errors.New("redis error"))
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "redis error", spans[0].Status.Description)
+	assert.Len(t, spans[0].Events, 1)
+}
@@ -0,0 +1,20 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsername(t *testing.T) {
+	username, ok := Username(WithUsername(context.TODO(), "test"))
+	assert.True(t, ok)
+	assert.Equal(t, "test", username)
+
+	_, ok = Username(context.TODO())
+	assert.False(t, ok)
+
+	_, ok = Username(WithUsername(context.TODO(), ""))
+	assert.False(t, ok)
+}
@@ -0,0 +1,73 @@
+package fidibosearch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+)
+
+type fidiboHit struct {
+	Score     float64             `json:"_score"`
+	Source    json.RawMessage     `json:"_source"`
+	Highlight map[string][]string `json:"highlight,omitempty"`
+}
+
+// hitFields are filled from the hit itself rather than from its _source.
+var hitFields = map[string]bool{
+	"score":     true,
+	"highlight": true,
+	"extra":     true,
+}
+
+var sourceFields = bookSourceFields()
+
+func bookSourceFields() map[string]int {
+	fields := map[string]int{}
+
+	t := reflect.TypeOf(domain.Book{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || hitFields[name] {
+			continue
+		}
+		fields[name] = i
+	}
+
+	return fields
+}
+
+// decodeBook fills the known fields of a book from its _source one by one, so a single field with an
+// unexpected type does not fail the whole response. Unknown and undecodable fields are kept in Extra.
+func decodeBook(hit fidiboHit) (domain.Book, error) {
+	raw := map[string]json.RawMessage{}
+	err := json.Unmarshal(hit.Source, &raw)
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	book := domain.Book{}
+	v := reflect.ValueOf(&book).Elem()
+	for name, value := range raw {
+		i, ok := sourceFields[name]
+		if !ok {
+			continue
+		}
+		field := v.Field(i)
+		err := json.Unmarshal(value, field.Addr().Interface())
+		if err != nil {
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+		delete(raw, name)
+	}
+
+	if len(raw) > 0 {
+		book.Extra = raw
+	}
+	book.Score = hit.Score
+	book.Highlight = hit.Highlight
+
+	return book, nil
+}
@@ -0,0 +1,441 @@
+package fidibosearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/metrics"
+)
+
+type BreakerState int
+
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+var ErrBulkheadFull = errors.New("fidibo search: too many concurrent requests")
+
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("fidibo search: circuit open, retry after %s", e.RetryAfter)
+}
+
+type BreakerConfig struct {
+	// WindowSize is the number of most recent calls the error and slow call rates are computed over.
+	WindowSize            int
+	MinRequests           int
+	ErrorRateThreshold    float64
+	SlowCallThreshold     time.Duration
+	SlowCallRateThreshold float64
+	OpenTimeout           time.Duration
+	HalfOpenMaxCalls      int
+	// MaxConcurrent must be positive.
+	MaxConcurrent int
+	MaxQueueWait  time.Duration
+	OnStateChange func(from, to BreakerState)
+}
+
+type BreakerStats struct {
+	State         string    `json:"state"`
+	Calls         int       `json:"calls"`
+	Failures      int       `json:"failures"`
+	SlowCalls     int       `json:"slow_calls"`
+	ErrorRate     float64   `json:"error_rate"`
+	SlowCallRate  float64   `json:"slow_call_rate"`
+	InFlight      int       `json:"in_flight"`
+	OpenedAt      time.Time `json:"opened_at,omitempty"`
+	Rejected      uint64    `json:"rejected"`
+	BulkheadFull  uint64    `json:"bulkhead_full"`
+	MaxConcurrent int       `json:"max_concurrent"`
+}
+
+type CircuitBreaker interface {
+	FidiboSearcher
+	State() BreakerState
+	Stats() BreakerStats
+	// Close stops the goroutine that calls OnStateChange. Later transitions are not reported.
+	Close()
+}
+
+type outcome struct {
+	failed bool
+	slow   bool
+}
+
+type stateChange struct {
+	from BreakerState
+	to   BreakerState
+}
+
+type circuitBreaker struct {
+	next FidiboSearcher
+	cfg  BreakerConfig
+	sem  chan struct{}
+	now  func() time.Time
+
+	mu             sync.Mutex
+	state          BreakerState
+	generation     uint64
+	window         []outcome
+	pos            int
+	count          int
+	failures       int
+	slowCalls      int
+	openedAt       time.Time
+	halfOpenCalls  int
+	halfOpenPassed int
+	rejected       uint64
+	bulkheadFull   uint64
+	changes        []stateChange
+	notify         chan struct{}
+	closed         bool
+}
+
+func (b *circuitBreaker) Search(ctx context.Context, req domain.SearchRequest) (domain.SearchResult, error) {
+	var res domain.SearchResult
+	err := b.call(ctx, func(ctx context.Context) error {
+		var err error
+		res, err = b.next.Search(ctx, req)
+		return err
+	})
+	return res, err
+}
+
+func (b *circuitBreaker) FindBook(ctx context.Context, lookup domain.BookLookup) (domain.Book, error) {
+	var book domain.Book
+	err := b.call(ctx, func(ctx context.Context) error {
+		var err error
+		book, err = b.next.FindBook(ctx, lookup)
+		return err
+	})
+	return book, err
+}
+
+func (b *circuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refreshState()
+	return b.state
+}
+
+func (b *circuitBreaker) Stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refreshState()
+
+	stats := BreakerStats{
+		State:         b.state.String(),
+		Calls:         b.count,
+		Failures:      b.failures,
+		SlowCalls:     b.slowCalls,
+		InFlight:      len(b.sem),
+		OpenedAt:      b.openedAt,
+		Rejected:      b.rejected,
+		BulkheadFull:  b.bulkheadFull,
+		MaxConcurrent: cap(b.sem),
+	}
+	if b.count > 0 {
+		stats.ErrorRate = float64(b.failures) / float64(b.count)
+		stats.SlowCallRate = float64(b.slowCalls) / float64(b.count)
+	}
+	return stats
+}
+
+func (b *circuitBreaker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		close(b.notify)
+	}
+}
+
+// StatsHandler reports the stats of the circuit breaker on GET.
+func StatsHandler(b CircuitBreaker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(b.Stats())
+	})
+}
+
+func (b *circuitBreaker) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	generation, err := b.allow()
+	if err != nil {
+		metrics.UpstreamErrors.WithLabelValues(reasonCircuitOpen).Inc()
+		return err
+	}
+
+	if err := b.acquire(ctx); err != nil {
+		b.release(generation)
+		if errors.Is(err, ErrBulkheadFull) {
+			metrics.UpstreamErrors.WithLabelValues(reasonBulkheadFull).Inc()
+		}
+		return err
+	}
+	defer func() { <-b.sem }()
+
+	start := b.now()
+	err = fn(ctx)
+	b.record(generation, err, b.now().Sub(start))
+
+	return err
+}
+
+func (b *circuitBreaker) allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refreshState()
+
+	switch b.state {
+	case StateOpen:
+		b.rejected++
+		return 0, &CircuitOpenError{RetryAfter: b.openedAt.Add(b.cfg.OpenTimeout).Sub(b.now())}
+	case StateHalfOpen:
+		if b.halfOpenCalls >= b.cfg.HalfOpenMaxCalls {
+			b.rejected++
+			return 0, &CircuitOpenError{RetryAfter: b.cfg.OpenTimeout}
+		}
+		b.halfOpenCalls++
+	}
+
+	return b.generation, nil
+}
+
+// release gives back a half-open probe slot that was never used because the bulkhead rejected the call.
+func (b *circuitBreaker) release(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation == b.generation && b.state == StateHalfOpen {
+		b.halfOpenCalls--
+	}
+}
+
+func (b *circuitBreaker) acquire(ctx context.Context) error {
+	select {
+	case b.sem <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if b.cfg.MaxQueueWait > 0 {
+		timer := time.NewTimer(b.cfg.MaxQueueWait)
+		defer timer.Stop()
+
+		select {
+		case b.sem <- struct{}{}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	b.mu.Lock()
+	b.bulkheadFull++
+	b.mu.Unlock()
+
+	return ErrBulkheadFull
+}
+
+func (b *circuitBreaker) record(generation uint64, err error, elapsed time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	o := outcome{
+		failed: isBreakerFailure(err),
+		slow:   b.cfg.SlowCallThreshold > 0 && elapsed >= b.cfg.SlowCallThreshold,
+	}
+
+	switch b.state {
+	case StateHalfOpen:
+		if o.failed || o.slow {
+			b.transition(StateOpen)
+			return
+		}
+		b.halfOpenPassed++
+		if b.halfOpenPassed >= b.cfg.HalfOpenMaxCalls {
+			b.transition(StateClosed)
+		}
+	case StateClosed:
+		b.push(o)
+		if b.shouldOpen() {
+			b.transition(StateOpen)
+		}
+	}
+}
+
+func (b *circuitBreaker) push(o outcome) {
+	if b.count == len(b.window) {
+		old := b.window[b.pos]
+		if old.failed {
+			b.failures--
+		}
+		if old.slow {
+			b.slowCalls--
+		}
+	} else {
+		b.count++
+	}
+
+	b.window[b.pos] = o
+	b.pos = (b.pos + 1) % len(b.window)
+
+	if o.failed {
+		b.failures++
+	}
+	if o.slow {
+		b.slowCalls++
+	}
+}
+
+func (b *circuitBreaker) shouldOpen() bool {
+	if b.count < b.cfg.MinRequests {
+		return false
+	}
+
+	errorRate := float64(b.failures) / float64(b.count)
+	if b.cfg.ErrorRateThreshold > 0 && errorRate >= b.cfg.ErrorRateThreshold {
+		return true
+	}
+
+	slowRate := float64(b.slowCalls) / float64(b.count)
+	return b.cfg.SlowCallRateThreshold > 0 && slowRate >= b.cfg.SlowCallRateThreshold
+}
+
+func (b *circuitBreaker) refreshState() {
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.cfg.OpenTimeout)) {
+		b.transition(StateHalfOpen)
+	}
+}
+
+func (b *circuitBreaker) transition(to BreakerState) {
+	from := b.state
+	if from == to {
+		return
+	}
+
+	b.state = to
+	b.generation++
+	metrics.UpstreamCircuitState.Set(float64(to))
+	b.halfOpenCalls = 0
+	b.halfOpenPassed = 0
+
+	switch to {
+	case StateOpen:
+		b.openedAt = b.now()
+	case StateClosed:
+		b.openedAt = time.Time{}
+		b.resetWindow()
+	}
+
+	if b.cfg.OnStateChange != nil && !b.closed {
+		b.changes = append(b.changes, stateChange{from: from, to: to})
+		select {
+		case b.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// notifyStateChanges calls OnStateChange for every transition, one at a time and in the order they happened,
+// outside of the lock so that a slow callback does not hold up calls.
+func (b *circuitBreaker) notifyStateChanges() {
+	for range b.notify {
+		b.mu.Lock()
+		changes := b.changes
+		b.changes = nil
+		b.mu.Unlock()
+
+		for _, change := range changes {
+			b.cfg.OnStateChange(change.from, change.to)
+		}
+	}
+}
+
+func (b *circuitBreaker) resetWindow() {
+	b.pos = 0
+	b.count = 0
+	b.failures = 0
+	b.slowCalls = 0
+}
+
+func isBreakerFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, domain.ErrBookNotFound) || errors.Is(err, ErrQuotaExceeded) {
+		return false
+	}
+
+	var statusErr *UpstreamStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError ||
+			statusErr.StatusCode == http.StatusTooManyRequests ||
+			statusErr.StatusCode == http.StatusRequestTimeout
+	}
+
+	return true
+}
+
+func NewCircuitBreaker(next FidiboSearcher, cfg BreakerConfig) CircuitBreaker {
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = 20
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 1
+	}
+	// The window never holds more calls than its size, so a larger minimum would keep the circuit closed forever.
+	if cfg.MinRequests > cfg.WindowSize {
+		cfg.MinRequests = cfg.WindowSize
+	}
+	if cfg.HalfOpenMaxCalls <= 0 {
+		cfg.HalfOpenMaxCalls = 1
+	}
+	if cfg.MaxConcurrent <= 0 {
+		panic(fmt.Sprintf("fidibo search: MaxConcurrent must be positive, got %d", cfg.MaxConcurrent))
+	}
+
+	b := &circuitBreaker{
+		next:   next,
+		cfg:    cfg,
+		sem:    make(chan struct{}, cfg.MaxConcurrent),
+		now:    time.Now,
+		window: make([]outcome, cfg.WindowSize),
+		notify: make(chan struct{}, 1),
+	}
+	if cfg.OnStateChange != nil {
+		go b.notifyStateChanges()
+	}
+	return b
+}
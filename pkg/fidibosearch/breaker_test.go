@@ -0,0 +1,349 @@
+package fidibosearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("passes through while closed", func(t *testing.T) {
+		expectedResult := domain.SearchResult{Books: []domain.Book{{ID: "123"}}}
+
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", context.TODO(), domain.SearchRequest{Keyword: "test"}).Return(expectedResult, nil)
+
+		b := NewCircuitBreaker(next, BreakerConfig{MaxConcurrent: 1})
+
+		res, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "test"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedResult, res)
+		assert.Equal(t, StateClosed, b.State())
+		next.AssertExpectations(t)
+	})
+
+	t.Run("opens after error rate threshold and fails fast", func(t *testing.T) {
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", context.TODO(), domain.SearchRequest{Keyword: "test"}).Return(domain.SearchResult{}, errors.New("upstream error")).Times(2)
+
+		b := NewCircuitBreaker(next, BreakerConfig{
+			WindowSize:         4,
+			MinRequests:        2,
+			ErrorRateThreshold: 0.5,
+			OpenTimeout:        time.Minute,
+			MaxConcurrent:      1,
+		})
+
+		for i := 0; i < 2; i++ {
+			_, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "test"})
+			assert.ErrorContains(t, err, "upstream error")
+		}
+		assert.Equal(t, StateOpen, b.State())
+
+		_, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "test"})
+
+		var openErr *CircuitOpenError
+		assert.ErrorAs(t, err, &openErr)
+		assert.Greater(t, openErr.RetryAfter, time.Duration(0))
+		assert.Equal(t, uint64(1), b.Stats().Rejected)
+		next.AssertExpectations(t)
+	})
+
+	t.Run("minimum requests above the window size", func(t *testing.T) {
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", context.TODO(), domain.SearchRequest{Keyword: "test"}).Return(domain.SearchResult{}, errors.New("upstream error")).Times(2)
+
+		b := NewCircuitBreaker(next, BreakerConfig{
+			WindowSize:         2,
+			MinRequests:        5,
+			ErrorRateThreshold: 1,
+			OpenTimeout:        time.Minute,
+			MaxConcurrent:      1,
+		})
+
+		for i := 0; i < 2; i++ {
+			_, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "test"})
+			assert.ErrorContains(t, err, "upstream error")
+		}
+		assert.Equal(t, StateOpen, b.State())
+		next.AssertExpectations(t)
+	})
+
+	t.Run("state changes are delivered in order", func(t *testing.T) {
+		now := time.Now()
+
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", context.TODO(), domain.SearchRequest{Keyword: "test"}).Return(domain.SearchResult{}, errors.New("upstream error"))
+
+		changes := make(chan [2]BreakerState, 10)
+		b := NewCircuitBreaker(next, BreakerConfig{
+			MinRequests:        1,
+			ErrorRateThreshold: 1,
+			OpenTimeout:        time.Minute,
+			MaxConcurrent:      1,
+			OnStateChange: func(from, to BreakerState) {
+				if to == StateOpen {
+					time.Sleep(10 * time.Millisecond)
+				}
+				changes <- [2]BreakerState{from, to}
+			},
+		}).(*circuitBreaker)
+		b.now = func() time.Time { return now }
+
+		for i := 0; i < 2; i++ {
+			_, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "test"})
+			assert.ErrorContains(t, err, "upstream error")
+			now = now.Add(time.Minute)
+			assert.Equal(t, StateHalfOpen, b.State())
+		}
+
+		expected := [][2]BreakerState{
+			{StateClosed, StateOpen},
+			{StateOpen, StateHalfOpen},
+			{StateHalfOpen, StateOpen},
+			{StateOpen, StateHalfOpen},
+		}
+		for _, change := range expected {
+			assert.Equal(t, change, <-changes)
+		}
+	})
+
+	t.Run("opens after slow call rate threshold", func(t *testing.T) {
+		now := time.Now()
+
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", context.TODO(), domain.SearchRequest{Keyword: "test"}).Return(domain.SearchResult{}, nil).Run(func(args mock.Arguments) {
+			now = now.Add(2 * time.Second)
+		})
+
+		b := NewCircuitBreaker(next, BreakerConfig{
+			MinRequests:           1,
+			SlowCallThreshold:     time.Second,
+			SlowCallRateThreshold: 1,
+			OpenTimeout:           time.Minute,
+			MaxConcurrent:         1,
+		}).(*circuitBreaker)
+		b.now = func() time.Time { return now }
+
+		_, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "test"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, StateOpen, b.State())
+		assert.Equal(t, 1, b.Stats().SlowCalls)
+	})
+
+	t.Run("half-open probe closes the circuit on success", func(t *testing.T) {
+		now := time.Now()
+
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", context.TODO(), domain.SearchRequest{Keyword: "fail"}).Return(domain.SearchResult{}, errors.New("upstream error")).Once()
+		next.On("Search", context.TODO(), domain.SearchRequest{Keyword: "ok"}).Return(domain.SearchResult{}, nil).Once()
+
+		var mu sync.Mutex
+		var transitions []BreakerState
+		done := make(chan struct{}, 3)
+
+		b := NewCircuitBreaker(next, BreakerConfig{
+			MinRequests:        1,
+			ErrorRateThreshold: 1,
+			OpenTimeout:        time.Minute,
+			MaxConcurrent:      1,
+			OnStateChange: func(from, to BreakerState) {
+				mu.Lock()
+				transitions = append(transitions, to)
+				mu.Unlock()
+				done <- struct{}{}
+			},
+		}).(*circuitBreaker)
+		b.now = func() time.Time { return now }
+
+		_, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "fail"})
+		assert.Error(t, err)
+		assert.Equal(t, StateOpen, b.State())
+
+		now = now.Add(time.Minute)
+		assert.Equal(t, StateHalfOpen, b.State())
+
+		_, err = b.Search(context.TODO(), domain.SearchRequest{Keyword: "ok"})
+		assert.NoError(t, err)
+		assert.Equal(t, StateClosed, b.State())
+
+		for i := 0; i < 3; i++ {
+			<-done
+		}
+		mu.Lock()
+		assert.Equal(t, []BreakerState{StateOpen, StateHalfOpen, StateClosed}, transitions)
+		mu.Unlock()
+		next.AssertExpectations(t)
+	})
+
+	t.Run("half-open probe reopens the circuit on failure", func(t *testing.T) {
+		now := time.Now()
+
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", context.TODO(), domain.SearchRequest{Keyword: "test"}).Return(domain.SearchResult{}, errors.New("upstream error")).Twice()
+
+		b := NewCircuitBreaker(next, BreakerConfig{
+			MinRequests:        1,
+			ErrorRateThreshold: 1,
+			OpenTimeout:        time.Minute,
+			MaxConcurrent:      1,
+		}).(*circuitBreaker)
+		b.now = func() time.Time { return now }
+
+		_, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "test"})
+		assert.Error(t, err)
+
+		now = now.Add(time.Minute)
+		_, err = b.Search(context.TODO(), domain.SearchRequest{Keyword: "test"})
+		assert.Error(t, err)
+		assert.Equal(t, StateOpen, b.State())
+		next.AssertExpectations(t)
+	})
+
+	t.Run("caller cancellation is not counted as failure", func(t *testing.T) {
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", context.TODO(), domain.SearchRequest{Keyword: "test"}).Return(domain.SearchResult{}, context.Canceled)
+
+		b := NewCircuitBreaker(next, BreakerConfig{
+			MinRequests:        1,
+			ErrorRateThreshold: 1,
+			MaxConcurrent:      1,
+		})
+
+		_, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "test"})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, StateClosed, b.State())
+		assert.Equal(t, 0, b.Stats().Failures)
+	})
+
+	t.Run("bulkhead rejects calls above the concurrency limit", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", context.TODO(), domain.SearchRequest{Keyword: "slow"}).Return(domain.SearchResult{}, nil).Run(func(args mock.Arguments) {
+			close(started)
+			<-release
+		})
+
+		b := NewCircuitBreaker(next, BreakerConfig{MaxConcurrent: 1})
+
+		errCh := make(chan error)
+		go func() {
+			_, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "slow"})
+			errCh <- err
+		}()
+		<-started
+
+		assert.Equal(t, 1, b.Stats().InFlight)
+
+		_, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "test"})
+		assert.ErrorIs(t, err, ErrBulkheadFull)
+
+		close(release)
+		assert.NoError(t, <-errCh)
+		assert.Equal(t, uint64(1), b.Stats().BulkheadFull)
+		next.AssertExpectations(t)
+	})
+
+	t.Run("client errors from upstream are not counted as failure", func(t *testing.T) {
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", context.TODO(), domain.SearchRequest{Keyword: "test"}).Return(domain.SearchResult{}, &UpstreamStatusError{StatusCode: http.StatusBadRequest})
+
+		b := NewCircuitBreaker(next, BreakerConfig{
+			MinRequests:        1,
+			ErrorRateThreshold: 1,
+			MaxConcurrent:      1,
+		})
+
+		_, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "test"})
+
+		assert.Error(t, err)
+		assert.Equal(t, StateClosed, b.State())
+	})
+	t.Run("book not found is not counted as failure", func(t *testing.T) {
+		next := &mocks.FidiboSearcher{}
+		next.On("FindBook", context.TODO(), domain.BookLookup{ID: "123"}).Return(domain.Book{}, domain.ErrBookNotFound)
+
+		b := NewCircuitBreaker(next, BreakerConfig{
+			MinRequests:        1,
+			ErrorRateThreshold: 1,
+			MaxConcurrent:      1,
+		})
+
+		_, err := b.FindBook(context.TODO(), domain.BookLookup{ID: "123"})
+
+		assert.ErrorIs(t, err, domain.ErrBookNotFound)
+		assert.Equal(t, StateClosed, b.State())
+		next.AssertExpectations(t)
+	})
+
+	t.Run("state changes after close are not delivered", func(t *testing.T) {
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", context.TODO(), domain.SearchRequest{Keyword: "test"}).Return(domain.SearchResult{}, errors.New("upstream error"))
+
+		changes := make(chan [2]BreakerState, 10)
+		b := NewCircuitBreaker(next, BreakerConfig{
+			MinRequests:        1,
+			ErrorRateThreshold: 1,
+			OpenTimeout:        time.Minute,
+			MaxConcurrent:      1,
+			OnStateChange: func(from, to BreakerState) {
+				changes <- [2]BreakerState{from, to}
+			},
+		})
+		b.Close()
+		b.Close()
+
+		_, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "test"})
+
+		assert.ErrorContains(t, err, "upstream error")
+		assert.Equal(t, StateOpen, b.State())
+		assert.Empty(t, changes)
+	})
+
+	t.Run("max concurrent must be positive", func(t *testing.T) {
+		assert.Panics(t, func() {
+			NewCircuitBreaker(&mocks.FidiboSearcher{}, BreakerConfig{})
+		})
+	})
+}
+
+func TestStatsHandler(t *testing.T) {
+	next := &mocks.FidiboSearcher{}
+	next.On("Search", context.TODO(), domain.SearchRequest{Keyword: "test"}).Return(domain.SearchResult{}, errors.New("upstream error"))
+
+	b := NewCircuitBreaker(next, BreakerConfig{MinRequests: 1, ErrorRateThreshold: 1, OpenTimeout: time.Minute, MaxConcurrent: 2})
+	_, err := b.Search(context.TODO(), domain.SearchRequest{Keyword: "test"})
+	assert.Error(t, err)
+
+	w := httptest.NewRecorder()
+	StatsHandler(b).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/breaker", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	stats := BreakerStats{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, "open", stats.State)
+	assert.Equal(t, 1, stats.Failures)
+	assert.Equal(t, 2, stats.MaxConcurrent)
+
+	w = httptest.NewRecorder()
+	StatsHandler(b).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/breaker", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET", w.Header().Get("Allow"))
+}
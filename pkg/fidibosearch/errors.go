@@ -0,0 +1,91 @@
+package fidibosearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxErrorBodySize = 512
+
+type UpstreamStatusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *UpstreamStatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("fidibo search: unexpected status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("fidibo search: unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+type UpstreamDecodeError struct {
+	Err error
+}
+
+func (e *UpstreamDecodeError) Error() string {
+	return fmt.Sprintf("fidibo search: could not decode response: %v", e.Err)
+}
+
+func (e *UpstreamDecodeError) Unwrap() error {
+	return e.Err
+}
+
+type UpstreamTimeoutError struct {
+	Err error
+}
+
+func (e *UpstreamTimeoutError) Error() string {
+	return fmt.Sprintf("fidibo search: request timed out: %v", e.Err)
+}
+
+func (e *UpstreamTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// UpstreamTransportError reports that the upstream could not be reached or its response could not be read,
+// such as a refused connection or a failed DNS lookup.
+type UpstreamTransportError struct {
+	Err error
+}
+
+func (e *UpstreamTransportError) Error() string {
+	return fmt.Sprintf("fidibo search: request failed: %v", e.Err)
+}
+
+func (e *UpstreamTransportError) Unwrap() error {
+	return e.Err
+}
+
+func newUpstreamStatusError(res *http.Response, body []byte) *UpstreamStatusError {
+	truncated := string(body)
+	if len(body) > maxErrorBodySize {
+		truncated = strings.ToValidUTF8(string(body[:maxErrorBodySize]), "") + "..."
+	}
+
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return &UpstreamStatusError{
+		StatusCode: res.StatusCode,
+		Body:       truncated,
+		RetryAfter: retryAfter,
+	}
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
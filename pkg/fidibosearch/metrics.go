@@ -0,0 +1,47 @@
+package fidibosearch
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/internal/metrics"
+)
+
+const (
+	reasonStatus        = "status"
+	reasonTimeout       = "timeout"
+	reasonDecode        = "decode"
+	reasonTransport     = "transport"
+	reasonCircuitOpen   = "circuit_open"
+	reasonBulkheadFull  = "bulkhead_full"
+	reasonQuotaExceeded = "quota_exceeded"
+)
+
+// observe records the latency of a request to Fidibo and, when it failed, why.
+func observe(elapsed time.Duration, err error) {
+	if err == nil {
+		metrics.UpstreamRequestDuration.WithLabelValues(metrics.ResultSuccess).Observe(elapsed.Seconds())
+		return
+	}
+
+	metrics.UpstreamRequestDuration.WithLabelValues(metrics.ResultError).Observe(elapsed.Seconds())
+	metrics.UpstreamErrors.WithLabelValues(errorReason(err)).Inc()
+}
+
+func errorReason(err error) string {
+	var (
+		statusErr  *UpstreamStatusError
+		timeoutErr *UpstreamTimeoutError
+		decodeErr  *UpstreamDecodeError
+	)
+
+	switch {
+	case errors.As(err, &statusErr):
+		return reasonStatus
+	case errors.As(err, &timeoutErr):
+		return reasonTimeout
+	case errors.As(err, &decodeErr):
+		return reasonDecode
+	}
+	return reasonTransport
+}
@@ -0,0 +1,37 @@
+package fidibosearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorReason(t *testing.T) {
+	assert.Equal(t, reasonStatus, errorReason(&UpstreamStatusError{StatusCode: http.StatusBadGateway}))
+	assert.Equal(t, reasonTimeout, errorReason(&UpstreamTimeoutError{Err: context.DeadlineExceeded}))
+	assert.Equal(t, reasonDecode, errorReason(&UpstreamDecodeError{Err: errors.New("unexpected EOF")}))
+	assert.Equal(t, reasonTransport, errorReason(errors.New("connection refused")))
+}
+
+func TestSearchMetrics(t *testing.T) {
+	route := "/search"
+
+	srv := newMockServer(route, http.StatusServiceUnavailable, "unavailable")
+	defer srv.Close()
+
+	statusErrors := metrics.UpstreamErrors.WithLabelValues(reasonStatus)
+	before := testutil.ToFloat64(statusErrors)
+
+	f := NewFidiboSearcher("q", srv.URL+route, time.Second)
+	_, err := f.Search(context.TODO(), domain.SearchRequest{Keyword: "test query"})
+
+	assert.Error(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(statusErrors))
+}
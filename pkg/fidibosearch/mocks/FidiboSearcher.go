@@ -15,23 +15,47 @@ type FidiboSearcher struct {
 	mock.Mock
 }
 
-// Search provides a mock function with given fields: ctx, query
-func (_m *FidiboSearcher) Search(ctx context.Context, query string) (domain.SearchResult, error) {
-	ret := _m.Called(ctx, query)
+// FindBook provides a mock function with given fields: ctx, lookup
+func (_m *FidiboSearcher) FindBook(ctx context.Context, lookup domain.BookLookup) (domain.Book, error) {
+	ret := _m.Called(ctx, lookup)
+
+	var r0 domain.Book
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BookLookup) (domain.Book, error)); ok {
+		return rf(ctx, lookup)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BookLookup) domain.Book); ok {
+		r0 = rf(ctx, lookup)
+	} else {
+		r0 = ret.Get(0).(domain.Book)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.BookLookup) error); ok {
+		r1 = rf(ctx, lookup)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, req
+func (_m *FidiboSearcher) Search(ctx context.Context, req domain.SearchRequest) (domain.SearchResult, error) {
+	ret := _m.Called(ctx, req)
 
 	var r0 domain.SearchResult
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.SearchResult, error)); ok {
-		return rf(ctx, query)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SearchRequest) (domain.SearchResult, error)); ok {
+		return rf(ctx, req)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) domain.SearchResult); ok {
-		r0 = rf(ctx, query)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SearchRequest) domain.SearchResult); ok {
+		r0 = rf(ctx, req)
 	} else {
 		r0 = ret.Get(0).(domain.SearchResult)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, query)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SearchRequest) error); ok {
+		r1 = rf(ctx, req)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,26 @@
+package fidibosearch
+
+import (
+	"context"
+	"net/http"
+)
+
+// Probe checks that the upstream is reachable without running a search. Any response below 500 counts,
+// since the search endpoint does not have to accept the probe itself.
+func Probe(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusInternalServerError {
+		return newUpstreamStatusError(res, nil)
+	}
+	return nil
+}
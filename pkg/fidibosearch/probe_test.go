@@ -0,0 +1,48 @@
+package fidibosearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbe(t *testing.T) {
+	client := &http.Client{Timeout: time.Second}
+
+	t.Run("reachable", func(t *testing.T) {
+		var method string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method = r.Method
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}))
+		defer srv.Close()
+
+		err := Probe(context.TODO(), client, srv.URL)
+		assert.NoError(t, err)
+		assert.Equal(t, http.MethodHead, method)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		err := Probe(context.TODO(), client, srv.URL)
+		var statusErr *UpstreamStatusError
+		assert.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadGateway, statusErr.StatusCode)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		srv.Close()
+
+		err := Probe(context.TODO(), client, srv.URL)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,151 @@
+package fidibosearch
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+const quotaKey = "fidibo:quota"
+
+// takeQuota counts a request in the current window, which starts with the first request after the previous one
+// expired so that every instance shares the window of the Redis server. When the window already holds the limit
+// the request is not counted and the milliseconds until the window ends are returned, otherwise 0.
+const takeQuotaScript = `
+local count = redis.call('INCR', KEYS[1])
+if count == 1 or redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+if count > tonumber(ARGV[2]) then
+	redis.call('DECR', KEYS[1])
+	return math.max(redis.call('PTTL', KEYS[1]), 1)
+end
+return 0
+`
+
+var takeQuota = redis.NewScript(takeQuotaScript)
+
+var ErrQuotaExceeded = errors.New("fidibo search: upstream quota exceeded")
+
+type Priority int
+
+const (
+	PriorityInteractive Priority = iota
+	PriorityBackground
+)
+
+func (p Priority) String() string {
+	if p == PriorityBackground {
+		return "background"
+	}
+	return "interactive"
+}
+
+type priorityKey struct{}
+
+// WithPriority marks the requests made with ctx, e.g. PriorityBackground for scheduled jobs. Requests are
+// interactive by default.
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+func PriorityFromContext(ctx context.Context) Priority {
+	priority, _ := ctx.Value(priorityKey{}).(Priority)
+	return priority
+}
+
+type QuotaConfig struct {
+	// Limit is the number of requests all instances together may send to Fidibo per Window.
+	Limit  int
+	Window time.Duration
+	// MaxWait is how long a request may wait for the next window once the quota is used up.
+	MaxWait time.Duration
+	// BackgroundShare is the fraction of Limit that background requests may use, keeping the rest of each
+	// window for interactive ones.
+	BackgroundShare float64
+}
+
+type quota struct {
+	next        FidiboSearcher
+	redisClient *redis.Client
+	cfg         QuotaConfig
+}
+
+func (q *quota) Search(ctx context.Context, req domain.SearchRequest) (domain.SearchResult, error) {
+	err := q.wait(ctx)
+	if err != nil {
+		return domain.SearchResult{}, err
+	}
+	return q.next.Search(ctx, req)
+}
+
+func (q *quota) FindBook(ctx context.Context, lookup domain.BookLookup) (domain.Book, error) {
+	err := q.wait(ctx)
+	if err != nil {
+		return domain.Book{}, err
+	}
+	return q.next.FindBook(ctx, lookup)
+}
+
+// wait takes a slot of the quota, waiting for the next window up to MaxWait when it is used up. The request is
+// let through when the quota cannot be checked, since Fidibo is better protected by the circuit breaker than
+// by failing every request while Redis is down.
+func (q *quota) wait(ctx context.Context) error {
+	priority := PriorityFromContext(ctx)
+	limit := q.cfg.Limit
+	if priority == PriorityBackground {
+		limit = int(math.Floor(float64(limit) * q.cfg.BackgroundShare))
+	}
+
+	start := time.Now()
+	deadline := start.Add(q.cfg.MaxWait)
+	delayed := false
+	for {
+		retryIn, err := takeQuota.Run(ctx, q.redisClient, []string{quotaKey}, q.cfg.Window.Milliseconds(), limit).Int64()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			metrics.UpstreamThrottled.WithLabelValues(priority.String(), metrics.ResultError).Inc()
+			return nil
+		}
+		if retryIn == 0 {
+			if delayed {
+				metrics.UpstreamThrottled.WithLabelValues(priority.String(), metrics.ResultDelayed).Inc()
+				metrics.UpstreamQuotaWait.WithLabelValues(priority.String()).Observe(time.Since(start).Seconds())
+			}
+			return nil
+		}
+
+		wait := time.Duration(retryIn) * time.Millisecond
+		if time.Now().Add(wait).After(deadline) {
+			metrics.UpstreamThrottled.WithLabelValues(priority.String(), metrics.ResultRejected).Inc()
+			metrics.UpstreamErrors.WithLabelValues(reasonQuotaExceeded).Inc()
+			return ErrQuotaExceeded
+		}
+
+		delayed = true
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// NewQuota limits the requests sent to Fidibo by all instances sharing the Redis server to cfg.Limit per
+// cfg.Window.
+func NewQuota(next FidiboSearcher, redisClient *redis.Client, cfg QuotaConfig) FidiboSearcher {
+	return &quota{
+		next:        next,
+		redisClient: redisClient,
+		cfg:         cfg,
+	}
+}
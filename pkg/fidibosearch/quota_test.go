@@ -0,0 +1,130 @@
+package fidibosearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/metrics"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch/mocks"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuota(t *testing.T) {
+	cfg := QuotaConfig{Limit: 10, Window: time.Second, MaxWait: 100 * time.Millisecond, BackgroundShare: 0.5}
+	keys := []string{quotaKey}
+	req := domain.SearchRequest{Keyword: "test"}
+	expectedResult := domain.SearchResult{Books: []domain.Book{{ID: "123"}}}
+
+	t.Run("allowed", func(t *testing.T) {
+		db, redisMock := redismock.NewClientMock()
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", context.TODO(), req).Return(expectedResult, nil)
+		redisMock.ExpectEvalSha(takeQuota.Hash(), keys, int64(1000), 10).SetVal(int64(0))
+
+		res, err := NewQuota(next, db, cfg).Search(context.TODO(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedResult, res)
+		assert.NoError(t, redisMock.ExpectationsWereMet())
+		next.AssertExpectations(t)
+	})
+
+	t.Run("waits for the next window", func(t *testing.T) {
+		delayed := metrics.UpstreamThrottled.WithLabelValues("interactive", metrics.ResultDelayed)
+		before := testutil.ToFloat64(delayed)
+
+		db, redisMock := redismock.NewClientMock()
+		next := &mocks.FidiboSearcher{}
+		next.On("FindBook", context.TODO(), domain.BookLookup{ID: "123"}).Return(domain.Book{ID: "123"}, nil)
+		redisMock.ExpectEvalSha(takeQuota.Hash(), keys, int64(1000), 10).SetVal(int64(20))
+		redisMock.ExpectEvalSha(takeQuota.Hash(), keys, int64(1000), 10).SetVal(int64(0))
+
+		start := time.Now()
+		book, err := NewQuota(next, db, cfg).FindBook(context.TODO(), domain.BookLookup{ID: "123"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "123", book.ID)
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+		assert.Equal(t, before+1, testutil.ToFloat64(delayed))
+		assert.NoError(t, redisMock.ExpectationsWereMet())
+		next.AssertExpectations(t)
+	})
+
+	t.Run("rejects when the next window is past the deadline", func(t *testing.T) {
+		rejected := metrics.UpstreamThrottled.WithLabelValues("interactive", metrics.ResultRejected)
+		before := testutil.ToFloat64(rejected)
+
+		db, redisMock := redismock.NewClientMock()
+		next := &mocks.FidiboSearcher{}
+		redisMock.ExpectEvalSha(takeQuota.Hash(), keys, int64(1000), 10).SetVal(int64(500))
+
+		start := time.Now()
+		_, err := NewQuota(next, db, cfg).Search(context.TODO(), req)
+
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+		assert.Less(t, time.Since(start), 50*time.Millisecond)
+		assert.Equal(t, before+1, testutil.ToFloat64(rejected))
+		assert.NoError(t, redisMock.ExpectationsWereMet())
+		next.AssertExpectations(t)
+	})
+
+	t.Run("background requests use a share of the limit", func(t *testing.T) {
+		db, redisMock := redismock.NewClientMock()
+		ctx := WithPriority(context.TODO(), PriorityBackground)
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", ctx, req).Return(expectedResult, nil)
+		redisMock.ExpectEvalSha(takeQuota.Hash(), keys, int64(1000), 5).SetVal(int64(0))
+
+		_, err := NewQuota(next, db, cfg).Search(ctx, req)
+
+		assert.NoError(t, err)
+		assert.NoError(t, redisMock.ExpectationsWereMet())
+		next.AssertExpectations(t)
+	})
+
+	t.Run("lets requests through when redis fails", func(t *testing.T) {
+		errored := metrics.UpstreamThrottled.WithLabelValues("interactive", metrics.ResultError)
+		before := testutil.ToFloat64(errored)
+
+		db, redisMock := redismock.NewClientMock()
+		next := &mocks.FidiboSearcher{}
+		next.On("Search", context.TODO(), req).Return(expectedResult, nil)
+		redisMock.ExpectEvalSha(takeQuota.Hash(), keys, int64(1000), 10).SetErr(errors.New("connection refused"))
+
+		res, err := NewQuota(next, db, cfg).Search(context.TODO(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedResult, res)
+		assert.Equal(t, before+1, testutil.ToFloat64(errored))
+		next.AssertExpectations(t)
+	})
+
+	t.Run("stops waiting when the context is done", func(t *testing.T) {
+		db, redisMock := redismock.NewClientMock()
+		next := &mocks.FidiboSearcher{}
+		redisMock.ExpectEvalSha(takeQuota.Hash(), keys, int64(1000), 10).SetVal(int64(50))
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+		defer cancel()
+		_, err := NewQuota(next, db, cfg).Search(ctx, req)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		next.AssertExpectations(t)
+	})
+}
+
+func TestPriority(t *testing.T) {
+	assert.Equal(t, PriorityInteractive, PriorityFromContext(context.TODO()))
+	assert.Equal(t, PriorityBackground, PriorityFromContext(WithPriority(context.TODO(), PriorityBackground)))
+	assert.Equal(t, "interactive", PriorityInteractive.String())
+	assert.Equal(t, "background", PriorityBackground.String())
+}
+
+func TestQuotaIsNotABreakerFailure(t *testing.T) {
+	assert.False(t, isBreakerFailure(ErrQuotaExceeded))
+}
@@ -4,106 +4,232 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	fromKey   = "from"
+	sizeKey   = "size"
+	formatKey = "format"
+	sortKey   = "sort"
+
+	lookupSize = 50
 )
 
 type fidiboResposne struct {
 	Books struct {
 		Hits struct {
-			Hits []struct {
-				Source domain.Book `json:"_source"`
-			} `json:"hits"`
+			Total hitsTotal   `json:"total"`
+			Hits  []fidiboHit `json:"hits"`
 		} `json:"hits"`
 	} `json:"books"`
 }
 
+type hitsTotal int64
+
+func (t *hitsTotal) UnmarshalJSON(data []byte) error {
+	var value int64
+	if err := json.Unmarshal(data, &value); err == nil {
+		*t = hitsTotal(value)
+		return nil
+	}
+
+	var total struct {
+		Value int64 `json:"value"`
+	}
+	if err := json.Unmarshal(data, &total); err != nil {
+		return err
+	}
+	*t = hitsTotal(total.Value)
+	return nil
+}
+
 type FidiboSearcher interface {
-	Search(ctx context.Context, query string) (domain.SearchResult, error)
+	Search(ctx context.Context, req domain.SearchRequest) (domain.SearchResult, error)
+	FindBook(ctx context.Context, lookup domain.BookLookup) (domain.Book, error)
 }
 
 type fidiboClient struct {
 	queryKey string
 	url      string
+	client   *http.Client
 }
 
-func (f *fidiboClient) Search(ctx context.Context, query string) (domain.SearchResult, error) {
-	req, err := f.createHTTPRequest(ctx, query)
+func (f *fidiboClient) Search(ctx context.Context, searchReq domain.SearchRequest) (domain.SearchResult, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "fidibo.search",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPMethod(http.MethodPost), semconv.HTTPURL(f.url)),
+	)
+
+	start := time.Now()
+	res, err := f.search(ctx, searchReq)
+	observe(time.Since(start), err)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (f *fidiboClient) search(ctx context.Context, searchReq domain.SearchRequest) (domain.SearchResult, error) {
+	req, err := f.createHTTPRequest(ctx, searchReq)
 	if err != nil {
 		return domain.SearchResult{}, err
 	}
 
 	res, err := f.doHTTPRequest(req)
 	if err != nil {
-		return domain.SearchResult{}, err
+		if isTimeout(err) {
+			return domain.SearchResult{}, &UpstreamTimeoutError{Err: err}
+		}
+		return domain.SearchResult{}, &UpstreamTransportError{Err: err}
 	}
 	defer res.Body.Close()
 
+	trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPStatusCode(res.StatusCode))
+
 	if res.StatusCode != http.StatusOK {
-		return domain.SearchResult{}, errors.New("did not receive any response")
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize+1))
+		return domain.SearchResult{}, newUpstreamStatusError(res, body)
 	}
 
-	return f.parseResponse(res.Body)
+	return f.parseResponse(res.Body, searchReq)
 }
 
-func (f *fidiboClient) convertFidiboResponseToDomainModel(r fidiboResposne) domain.SearchResult {
-	var result domain.SearchResult
+// FindBook looks a single book up by searching for its ID or the words of its slug, since the search API
+// has no endpoint for fetching a book directly.
+func (f *fidiboClient) FindBook(ctx context.Context, lookup domain.BookLookup) (domain.Book, error) {
+	keyword := lookup.ID
+	if keyword == "" {
+		keyword = strings.ReplaceAll(lookup.Slug, "-", " ")
+	}
+
+	res, err := f.Search(ctx, domain.SearchRequest{Keyword: keyword, Page: 1, Size: lookupSize})
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	for _, book := range res.Books {
+		if lookup.Matches(book) {
+			return book, nil
+		}
+	}
+	return domain.Book{}, domain.ErrBookNotFound
+}
+
+func (f *fidiboClient) convertFidiboResponseToDomainModel(r fidiboResposne, req domain.SearchRequest) (domain.SearchResult, error) {
+	result := domain.SearchResult{
+		Total:     int64(r.Books.Hits.Total),
+		Page:      req.Page,
+		Size:      req.Size,
+		FetchedAt: time.Now().UTC(),
+	}
 	for _, v := range r.Books.Hits.Hits {
-		result.Books = append(result.Books, v.Source)
+		book, err := decodeBook(v)
+		if err != nil {
+			return domain.SearchResult{}, err
+		}
+		result.Books = append(result.Books, book)
 	}
-	return result
+
+	result.HasMore = int64(offset(req)+len(result.Books)) < result.Total
+
+	return result, nil
 }
 
-func (f *fidiboClient) createHTTPRequest(ctx context.Context, query string) (*http.Request, error) {
+func (f *fidiboClient) createHTTPRequest(ctx context.Context, searchReq domain.SearchRequest) (*http.Request, error) {
 	payload := &bytes.Buffer{}
 	writer := multipart.NewWriter(payload)
-	if query != "" {
-		err := writer.WriteField(f.queryKey, query)
+	if searchReq.Keyword != "" {
+		err := writer.WriteField(f.queryKey, searchReq.Keyword)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if searchReq.Size > 0 {
+		err := writer.WriteField(fromKey, strconv.Itoa(offset(searchReq)))
+		if err != nil {
+			return nil, err
+		}
+		err = writer.WriteField(sizeKey, strconv.Itoa(searchReq.Size))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if searchReq.Format != "" {
+		err := writer.WriteField(formatKey, searchReq.Format)
 		if err != nil {
 			return nil, err
 		}
-		err = writer.Close()
+	}
+	if searchReq.Sort != "" && searchReq.Sort != domain.SortRelevance {
+		err := writer.WriteField(sortKey, searchReq.Sort)
 		if err != nil {
 			return nil, err
 		}
 	}
+	err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url, payload)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
 	return req, nil
 }
 
 func (f *fidiboClient) doHTTPRequest(req *http.Request) (*http.Response, error) {
-	client := http.Client{}
-	return client.Do(req)
+	return f.client.Do(req)
 }
 
-func (f *fidiboClient) parseResponse(body io.Reader) (domain.SearchResult, error) {
+func (f *fidiboClient) parseResponse(body io.Reader, req domain.SearchRequest) (domain.SearchResult, error) {
 	res, err := io.ReadAll(body)
 	if err != nil {
-		return domain.SearchResult{}, err
+		if isTimeout(err) {
+			return domain.SearchResult{}, &UpstreamTimeoutError{Err: err}
+		}
+		return domain.SearchResult{}, &UpstreamTransportError{Err: err}
 	}
 
 	fidiboResponse := fidiboResposne{}
 	err = json.Unmarshal(res, &fidiboResponse)
 	if err != nil {
-		return domain.SearchResult{}, err
+		return domain.SearchResult{}, &UpstreamDecodeError{Err: err}
 	}
 
-	return f.convertFidiboResponseToDomainModel(fidiboResponse), nil
+	result, err := f.convertFidiboResponseToDomainModel(fidiboResponse, req)
+	if err != nil {
+		return domain.SearchResult{}, &UpstreamDecodeError{Err: err}
+	}
+
+	return result, nil
+}
+
+func offset(req domain.SearchRequest) int {
+	if req.Page <= 1 {
+		return 0
+	}
+	return (req.Page - 1) * req.Size
 }
 
-func NewFidiboSearcher(queryKey, url string) FidiboSearcher {
+func NewFidiboSearcher(queryKey, url string, timeout time.Duration) FidiboSearcher {
 	return &fidiboClient{
 		queryKey: queryKey,
 		url:      url,
+		client:   &http.Client{Timeout: timeout},
 	}
 }
@@ -2,11 +2,23 @@ package fidibosearch
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/tracing"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestFidiboSearch(t *testing.T) {
@@ -24,11 +36,12 @@ func TestFidiboSearch(t *testing.T) {
 				{Name: "author name"},
 			},
 		}
+		source, err := json.Marshal(book)
+		assert.NoError(t, err)
+
 		apiRes := fidiboResposne{}
-		apiRes.Books.Hits.Hits = append(apiRes.Books.Hits.Hits, struct {
-			Source domain.Book `json:"_source"`
-		}{
-			Source: book,
+		apiRes.Books.Hits.Hits = append(apiRes.Books.Hits.Hits, fidiboHit{
+			Source: source,
 		})
 
 		route := "/search"
@@ -36,41 +49,300 @@ func TestFidiboSearch(t *testing.T) {
 		srv := newMockServer(route, http.StatusOK, apiRes)
 		defer srv.Close()
 
-		f := NewFidiboSearcher("q", srv.URL+route)
+		f := NewFidiboSearcher("q", srv.URL+route, time.Second)
 
-		res, err := f.Search(context.TODO(), "test query")
+		res, err := f.Search(context.TODO(), domain.SearchRequest{Keyword: "test query"})
 
 		assert.NoError(t, err)
 		assert.Len(t, res.Books, 1)
 		assert.Equal(t, res.Books[0], book)
 	})
 
+	t.Run("full book metadata", func(t *testing.T) {
+		route := "/search"
+
+		apiRes := `{"books":{"hits":{"total":1,"hits":[{
+			"_score": 12.5,
+			"highlight": {"title": ["<em>test</em> title"]},
+			"_source": {
+				"id": "123",
+				"title": "test title",
+				"slug": "test",
+				"authors": [{"name": "author name"}],
+				"translators": [{"name": "translator name"}],
+				"narrators": [{"name": "narrator name"}],
+				"price": 45000,
+				"format": "audiobook",
+				"page_count": "unknown",
+				"publish_date": "2020-01-02",
+				"rating": 4.5,
+				"language": "fa",
+				"categories": [{"title": "novel"}],
+				"isbn": "978-600"
+			}
+		}]}}}`
+
+		srv := newMockServer(route, http.StatusOK, apiRes)
+		defer srv.Close()
+
+		f := NewFidiboSearcher("q", srv.URL+route, time.Second)
+
+		res, err := f.Search(context.TODO(), domain.SearchRequest{Keyword: "test query"})
+
+		assert.NoError(t, err)
+		assert.Len(t, res.Books, 1)
+
+		book := res.Books[0]
+		assert.Equal(t, "123", book.ID)
+		assert.Equal(t, []domain.Translator{{Name: "translator name"}}, book.Translators)
+		assert.Equal(t, []domain.Narrator{{Name: "narrator name"}}, book.Narrators)
+		assert.Equal(t, 45000.0, *book.Price)
+		assert.Equal(t, "audiobook", book.Format)
+		assert.Equal(t, 0, book.PageCount)
+		assert.Equal(t, "2020-01-02", book.PublishDate)
+		assert.Equal(t, 4.5, book.Rating)
+		assert.Equal(t, "fa", book.Language)
+		assert.Equal(t, []domain.Category{{Title: "novel"}}, book.Categories)
+		assert.Equal(t, 12.5, book.Score)
+		assert.Equal(t, map[string][]string{"title": {"<em>test</em> title"}}, book.Highlight)
+		assert.Len(t, book.Extra, 2)
+		assert.JSONEq(t, `"978-600"`, string(book.Extra["isbn"]))
+		assert.JSONEq(t, `"unknown"`, string(book.Extra["page_count"]))
+	})
+
 	t.Run("fidibo server error", func(t *testing.T) {
 		route := "/search"
 
-		srv := newMockServer(route, http.StatusInternalServerError, "")
+		srv := newMockServer(route, http.StatusInternalServerError, "upstream failure")
+		defer srv.Close()
+
+		f := NewFidiboSearcher("q", srv.URL+route, time.Second)
+
+		res, err := f.Search(context.TODO(), domain.SearchRequest{Keyword: "test query"})
+
+		var statusErr *UpstreamStatusError
+		assert.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+		assert.Equal(t, "upstream failure", statusErr.Body)
+		assert.Len(t, res.Books, 0)
+	})
+
+	t.Run("fidibo error body is truncated", func(t *testing.T) {
+		route := "/search"
+
+		srv := newMockServer(route, http.StatusTooManyRequests, strings.Repeat("a", 2*maxErrorBodySize))
+		defer srv.Close()
+
+		f := NewFidiboSearcher("q", srv.URL+route, time.Second)
+
+		_, err := f.Search(context.TODO(), domain.SearchRequest{Keyword: "test query"})
+
+		var statusErr *UpstreamStatusError
+		assert.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusTooManyRequests, statusErr.StatusCode)
+		assert.Equal(t, strings.Repeat("a", maxErrorBodySize)+"...", statusErr.Body)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
 		defer srv.Close()
+		defer close(release)
 
-		f := NewFidiboSearcher("q", srv.URL+route)
+		f := NewFidiboSearcher("q", srv.URL, 50*time.Millisecond)
 
-		res, err := f.Search(context.TODO(), "test query")
+		res, err := f.Search(context.TODO(), domain.SearchRequest{Keyword: "test query"})
 
-		assert.Error(t, err)
-		assert.ErrorContains(t, err, "did not receive any response")
+		var timeoutErr *UpstreamTimeoutError
+		assert.ErrorAs(t, err, &timeoutErr)
 		assert.Len(t, res.Books, 0)
 	})
 
+	t.Run("connection refused", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		srv.Close()
+
+		f := NewFidiboSearcher("q", srv.URL, time.Second)
+
+		_, err := f.Search(context.TODO(), domain.SearchRequest{Keyword: "test query"})
+
+		var transportErr *UpstreamTransportError
+		assert.ErrorAs(t, err, &transportErr)
+	})
+
 	t.Run("unmarshall error", func(t *testing.T) {
 		route := "/search"
 
 		srv := newMockServer(route, http.StatusOK, "response")
 		defer srv.Close()
 
-		f := NewFidiboSearcher("q", srv.URL+route)
+		f := NewFidiboSearcher("q", srv.URL+route, time.Second)
 
-		res, err := f.Search(context.TODO(), "test query")
+		res, err := f.Search(context.TODO(), domain.SearchRequest{Keyword: "test query"})
 
-		assert.Error(t, err)
+		var decodeErr *UpstreamDecodeError
+		assert.ErrorAs(t, err, &decodeErr)
 		assert.Len(t, res.Books, 0)
 	})
+	t.Run("pagination", func(t *testing.T) {
+		var form url.Values
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := r.ParseMultipartForm(1 << 20)
+			assert.NoError(t, err)
+			form = r.MultipartForm.Value
+
+			w.Write([]byte(`{"books":{"hits":{"total":{"value":45,"relation":"eq"},"hits":[{"_source":{"id":"1"}},{"_source":{"id":"2"}}]}}}`))
+		}))
+		defer srv.Close()
+
+		f := NewFidiboSearcher("q", srv.URL, time.Second)
+
+		res, err := f.Search(context.TODO(), domain.SearchRequest{Keyword: "test query", Page: 3, Size: 20})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"test query"}, form["q"])
+		assert.Equal(t, []string{"40"}, form["from"])
+		assert.Equal(t, []string{"20"}, form["size"])
+		assert.Len(t, res.Books, 2)
+		assert.Equal(t, int64(45), res.Total)
+		assert.Equal(t, 3, res.Page)
+		assert.Equal(t, 20, res.Size)
+		assert.True(t, res.HasMore)
+	})
+
+	t.Run("format and sort are forwarded", func(t *testing.T) {
+		var form url.Values
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := r.ParseMultipartForm(1 << 20)
+			assert.NoError(t, err)
+			form = r.MultipartForm.Value
+
+			w.Write([]byte(`{"books":{"hits":{"total":0,"hits":[]}}}`))
+		}))
+		defer srv.Close()
+
+		f := NewFidiboSearcher("q", srv.URL, time.Second)
+
+		_, err := f.Search(context.TODO(), domain.SearchRequest{Keyword: "test query", Format: domain.FormatEbook, Sort: domain.SortDate, Author: "kafka"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ebook"}, form["format"])
+		assert.Equal(t, []string{"date"}, form["sort"])
+		assert.NotContains(t, form, "author")
+	})
+
+	t.Run("last page with numeric total", func(t *testing.T) {
+		route := "/search"
+
+		srv := newMockServer(route, http.StatusOK, `{"books":{"hits":{"total":21,"hits":[{"_source":{"id":"21"}}]}}}`)
+		defer srv.Close()
+
+		f := NewFidiboSearcher("q", srv.URL+route, time.Second)
+
+		res, err := f.Search(context.TODO(), domain.SearchRequest{Keyword: "test query", Page: 2, Size: 20})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(21), res.Total)
+		assert.False(t, res.HasMore)
+	})
+}
+
+func TestFidiboSearchTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(tracing.NewTracerProvider(sdktrace.WithSyncer(exporter), 1))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	_, err := tracing.Setup(context.TODO(), tracing.Config{})
+	assert.NoError(t, err)
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Write([]byte(`{"books":{"hits":{"total":0,"hits":[]}}}`))
+	}))
+	defer srv.Close()
+
+	f := NewFidiboSearcher("q", srv.URL, time.Second)
+
+	_, err = f.Search(context.TODO(), domain.SearchRequest{Keyword: "test query"})
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "fidibo.search", spans[0].Name)
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind)
+	assert.Contains(t, spans[0].Attributes, semconv.HTTPStatusCode(http.StatusOK))
+
+	expected := fmt.Sprintf("00-%s-%s-01", spans[0].SpanContext.TraceID(), spans[0].SpanContext.SpanID())
+	assert.Equal(t, expected, traceparent)
+}
+
+func TestFidiboFindBook(t *testing.T) {
+	t.Run("by id", func(t *testing.T) {
+		var form url.Values
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := r.ParseMultipartForm(1 << 20)
+			assert.NoError(t, err)
+			form = r.MultipartForm.Value
+
+			w.Write([]byte(`{"books":{"hits":{"total":2,"hits":[{"_source":{"id":"1234"}},{"_source":{"id":"123","title":"test title"}}]}}}`))
+		}))
+		defer srv.Close()
+
+		f := NewFidiboSearcher("q", srv.URL, time.Second)
+
+		book, err := f.FindBook(context.TODO(), domain.BookLookup{ID: "123"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"123"}, form["q"])
+		assert.Equal(t, domain.Book{ID: "123", Title: "test title"}, book)
+	})
+
+	t.Run("by slug", func(t *testing.T) {
+		var form url.Values
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := r.ParseMultipartForm(1 << 20)
+			assert.NoError(t, err)
+			form = r.MultipartForm.Value
+
+			w.Write([]byte(`{"books":{"hits":{"total":1,"hits":[{"_source":{"id":"123","slug":"the-blind-owl"}}]}}}`))
+		}))
+		defer srv.Close()
+
+		f := NewFidiboSearcher("q", srv.URL, time.Second)
+
+		book, err := f.FindBook(context.TODO(), domain.BookLookup{Slug: "the-blind-owl"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"the blind owl"}, form["q"])
+		assert.Equal(t, "123", book.ID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		route := "/search"
+
+		srv := newMockServer(route, http.StatusOK, `{"books":{"hits":{"total":1,"hits":[{"_source":{"id":"1234"}}]}}}`)
+		defer srv.Close()
+
+		f := NewFidiboSearcher("q", srv.URL+route, time.Second)
+
+		_, err := f.FindBook(context.TODO(), domain.BookLookup{ID: "123"})
+
+		assert.ErrorIs(t, err, domain.ErrBookNotFound)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		route := "/search"
+
+		srv := newMockServer(route, http.StatusInternalServerError, "internal server error")
+		defer srv.Close()
+
+		f := NewFidiboSearcher("q", srv.URL+route, time.Second)
+
+		_, err := f.FindBook(context.TODO(), domain.BookLookup{ID: "123"})
+
+		var statusErr *UpstreamStatusError
+		assert.ErrorAs(t, err, &statusErr)
+	})
 }
@@ -0,0 +1,54 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	ratelimit "github.com/kavehjamshidi/fidibo-challenge/pkg/ratelimit"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Limiter is an autogenerated mock type for the Limiter type
+type Limiter struct {
+	mock.Mock
+}
+
+// Allow provides a mock function with given fields: ctx, key, limit
+func (_m *Limiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	ret := _m.Called(ctx, key, limit)
+
+	var r0 ratelimit.Result
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ratelimit.Limit) (ratelimit.Result, error)); ok {
+		return rf(ctx, key, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ratelimit.Limit) ratelimit.Result); ok {
+		r0 = rf(ctx, key, limit)
+	} else {
+		r0 = ret.Get(0).(ratelimit.Result)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ratelimit.Limit) error); ok {
+		r1 = rf(ctx, key, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewLimiter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewLimiter creates a new instance of Limiter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewLimiter(t mockConstructorTestingTNewLimiter) *Limiter {
+	mock := &Limiter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "ratelimit:"
+
+// slidingWindow keeps the requests of the last window in a sorted set scored by their time, taken from the
+// Redis clock so every instance shares one. A request is added only when the set holds fewer than the limit.
+// It returns whether the request was allowed, the remaining requests and the microseconds until the oldest
+// request leaves the window.
+const slidingWindowScript = `
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, now .. ':' .. count)
+	redis.call('PEXPIRE', KEYS[1], math.ceil(window / 1000))
+	count = count + 1
+	allowed = 1
+end
+
+local reset = window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, limit - count, reset}
+`
+
+var slidingWindow = redis.NewScript(slidingWindowScript)
+
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is the time until the oldest request counted leaves the window, freeing a slot.
+	ResetAfter time.Duration
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type redisLimiter struct {
+	redisClient *redis.Client
+}
+
+// Allow counts a request for key if it is within limit over the sliding window, across all instances sharing
+// the Redis server.
+func (l *redisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	res, err := slidingWindow.Run(ctx, l.redisClient, []string{keyPrefix + key}, limit.Window.Microseconds(), limit.Requests).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:    res[0] == 1,
+		Limit:      limit.Requests,
+		Remaining:  int(res[1]),
+		ResetAfter: time.Duration(res[2]) * time.Microsecond,
+	}, nil
+}
+
+func NewRedisLimiter(redisClient *redis.Client) Limiter {
+	return &redisLimiter{
+		redisClient: redisClient,
+	}
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllow(t *testing.T) {
+	limit := Limit{Requests: 10, Window: time.Minute}
+	keys := []string{"ratelimit:search:user:test"}
+
+	t.Run("allowed", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		limiter := NewRedisLimiter(db)
+
+		mock.ExpectEvalSha(slidingWindow.Hash(), keys, int64(60000000), 10).SetVal([]interface{}{int64(1), int64(9), int64(60000000)})
+
+		res, err := limiter.Allow(context.TODO(), "search:user:test", limit)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Result{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: time.Minute}, res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		limiter := NewRedisLimiter(db)
+
+		mock.ExpectEvalSha(slidingWindow.Hash(), keys, int64(60000000), 10).SetVal([]interface{}{int64(0), int64(0), int64(1500000)})
+
+		res, err := limiter.Allow(context.TODO(), "search:user:test", limit)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Result{Allowed: false, Limit: 10, Remaining: 0, ResetAfter: 1500 * time.Millisecond}, res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+		limiter := NewRedisLimiter(db)
+
+		mock.ExpectEvalSha(slidingWindow.Hash(), keys, int64(60000000), 10).SetErr(errors.New("connection refused"))
+
+		res, err := limiter.Allow(context.TODO(), "search:user:test", limit)
+
+		assert.ErrorContains(t, err, "connection refused")
+		assert.Equal(t, Result{}, res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
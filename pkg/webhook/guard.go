@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const dialTimeout = 30 * time.Second
+
+var (
+	ErrInvalidURL        = errors.New("webhook URL must be an http or https URL")
+	ErrAddressNotAllowed = errors.New("webhook address is not allowed")
+	ErrHostNotResolvable = errors.New("webhook host could not be resolved")
+)
+
+// deniedPrefixes are the special-purpose ranges that are global unicast by the standard library's account but
+// do not reach a public host: shared carrier-grade NAT space, benchmarking, documentation and reserved ranges,
+// and the IPv6 translation and tunnelling prefixes that can wrap any IPv4 address, private ones included.
+var deniedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.88.99.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/96"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("fec0::/10"),
+}
+
+// Guard keeps deliveries away from the service's own network: loopback, private, link-local, unspecified and
+// other special-purpose addresses, such as the admin listener, Redis or the cloud metadata endpoint, are
+// refused.
+type Guard interface {
+	// CheckURL resolves the host of rawURL and fails when any of its addresses is not allowed.
+	CheckURL(ctx context.Context, rawURL string) error
+	// DialContext connects like net.Dialer but refuses addresses that are not allowed. The address is checked
+	// after the host is resolved, right before connecting, so a host that is rebound to a private address
+	// after CheckURL is still refused.
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+type guard struct {
+	allowPrivate bool
+	resolver     *net.Resolver
+	dialer       *net.Dialer
+}
+
+func (g *guard) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+	if g.allowPrivate {
+		return nil
+	}
+
+	host := u.Hostname()
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return g.checkAddr(ip)
+	}
+
+	ips, err := g.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrHostNotResolvable, err)
+	}
+	for _, ip := range ips {
+		err := g.checkAddr(ip)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *guard) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return g.dialer.DialContext(ctx, network, address)
+}
+
+// control runs for every address the dialer tries, after DNS resolution.
+func (g *guard) control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	return g.checkAddr(addrPort.Addr())
+}
+
+func (g *guard) checkAddr(ip netip.Addr) error {
+	if g.allowPrivate {
+		return nil
+	}
+
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, ip)
+	}
+	for _, prefix := range deniedPrefixes {
+		if prefix.Contains(ip) {
+			return fmt.Errorf("%w: %s", ErrAddressNotAllowed, ip)
+		}
+	}
+	return nil
+}
+
+// NewGuard returns a Guard for webhook deliveries. allowPrivate lifts the address checks, for receivers on
+// the local network during development and tests.
+func NewGuard(allowPrivate bool) Guard {
+	g := &guard{
+		allowPrivate: allowPrivate,
+		resolver:     net.DefaultResolver,
+	}
+	g.dialer = &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   g.control,
+	}
+	return g
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardCheckURL(t *testing.T) {
+	guard := NewGuard(false)
+
+	tests := []struct {
+		url string
+		err error
+	}{
+		{url: "https://93.184.216.34/hook", err: nil},
+		{url: "ftp://93.184.216.34/hook", err: ErrInvalidURL},
+		{url: "https:///hook", err: ErrInvalidURL},
+		{url: "http://127.0.0.1:9090/log-level", err: ErrAddressNotAllowed},
+		{url: "http://localhost:6379", err: ErrAddressNotAllowed},
+		{url: "http://10.0.0.5/hook", err: ErrAddressNotAllowed},
+		{url: "http://192.168.1.1/hook", err: ErrAddressNotAllowed},
+		{url: "http://169.254.169.254/latest/meta-data", err: ErrAddressNotAllowed},
+		{url: "http://0.0.0.0/hook", err: ErrAddressNotAllowed},
+		{url: "http://[::1]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[::ffff:127.0.0.1]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[fe80::1]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[fd00::1]/hook", err: ErrAddressNotAllowed},
+		{url: "http://0.1.2.3/hook", err: ErrAddressNotAllowed},
+		{url: "http://100.64.0.1/hook", err: ErrAddressNotAllowed},
+		{url: "http://100.127.255.254/hook", err: ErrAddressNotAllowed},
+		{url: "http://192.0.0.8/hook", err: ErrAddressNotAllowed},
+		{url: "http://192.0.2.1/hook", err: ErrAddressNotAllowed},
+		{url: "http://192.88.99.1/hook", err: ErrAddressNotAllowed},
+		{url: "http://198.18.0.1/hook", err: ErrAddressNotAllowed},
+		{url: "http://198.19.255.1/hook", err: ErrAddressNotAllowed},
+		{url: "http://198.51.100.1/hook", err: ErrAddressNotAllowed},
+		{url: "http://203.0.113.1/hook", err: ErrAddressNotAllowed},
+		{url: "http://240.0.0.1/hook", err: ErrAddressNotAllowed},
+		{url: "http://255.255.255.255/hook", err: ErrAddressNotAllowed},
+		{url: "http://224.0.0.1/hook", err: ErrAddressNotAllowed},
+		{url: "http://[::]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[::a00:5]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[64:ff9b::a00:5]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[64:ff9b::7f00:1]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[64:ff9b:1::a00:5]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[100::1]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[2001::a00:5]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[2001:db8::1]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[2002:a00:5::1]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[fec0::1]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[ff02::1]/hook", err: ErrAddressNotAllowed},
+		{url: "http://[2606:4700:4700::1111]/hook", err: nil},
+		{url: "http://100.128.0.1/hook", err: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := guard.CheckURL(context.TODO(), tt.url)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+
+	t.Run("private addresses allowed", func(t *testing.T) {
+		assert.NoError(t, NewGuard(true).CheckURL(context.TODO(), "http://127.0.0.1:9090/log-level"))
+		assert.ErrorIs(t, NewGuard(true).CheckURL(context.TODO(), "ftp://127.0.0.1"), ErrInvalidURL)
+	})
+}
+
+func TestGuardDialContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	t.Run("refused", func(t *testing.T) {
+		_, err := NewGuard(false).DialContext(context.TODO(), "tcp", srv.Listener.Addr().String())
+
+		assert.ErrorIs(t, err, ErrAddressNotAllowed)
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		conn, err := NewGuard(true).DialContext(context.TODO(), "tcp", srv.Listener.Addr().String())
+
+		assert.NoError(t, err)
+		conn.Close()
+	})
+}
@@ -0,0 +1,70 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	net "net"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Guard is an autogenerated mock type for the Guard type
+type Guard struct {
+	mock.Mock
+}
+
+// CheckURL provides a mock function with given fields: ctx, rawURL
+func (_m *Guard) CheckURL(ctx context.Context, rawURL string) error {
+	ret := _m.Called(ctx, rawURL)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, rawURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DialContext provides a mock function with given fields: ctx, network, address
+func (_m *Guard) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	ret := _m.Called(ctx, network, address)
+
+	var r0 net.Conn
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (net.Conn, error)); ok {
+		return rf(ctx, network, address)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) net.Conn); ok {
+		r0 = rf(ctx, network, address)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(net.Conn)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, network, address)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewGuard interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewGuard creates a new instance of Guard. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewGuard(t mockConstructorTestingTNewGuard) *Guard {
+	mock := &Guard{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '1672531200.{"id":"1"}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=0e6da84eace6ffe181e3ebc3a9dab2ed978556911614f665a962b0e6ea5397d1", Sign("secret", 1672531200, []byte(`{"id":"1"}`)))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1672531200, 0)
+	body := []byte(`{"id":"1"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := Sign("secret", now.Unix(), body)
+
+	t.Run("valid", func(t *testing.T) {
+		err := Verify("secret", timestamp, signature, body, 5*time.Minute, now.Add(time.Minute))
+		assert.NoError(t, err)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		err := Verify("other", timestamp, signature, body, 5*time.Minute, now)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("modified body", func(t *testing.T) {
+		err := Verify("secret", timestamp, signature, []byte(`{"id":"2"}`), 5*time.Minute, now)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("stale timestamp", func(t *testing.T) {
+		err := Verify("secret", timestamp, signature, body, 5*time.Minute, now.Add(10*time.Minute))
+		assert.ErrorIs(t, err, ErrInvalidTimestamp)
+	})
+
+	t.Run("malformed timestamp", func(t *testing.T) {
+		err := Verify("secret", "yesterday", signature, body, 0, now)
+		assert.ErrorIs(t, err, ErrInvalidTimestamp)
+	})
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	favoritesKeyPrefix     = "favorites:"
+	favoriteBooksKeySuffix = ":books"
+)
+
+// addFavoriteScript adds a favorite unless it is already there or the user has reached the limit, so that
+// concurrent requests cannot push a user past it.
+const addFavoriteScript = `
+if redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	return 0
+end
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[3]) then
+	return -1
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[4])
+return 1
+`
+
+type FavoriteRepository interface {
+	Add(ctx context.Context, username string, favorite domain.Favorite) error
+	Remove(ctx context.Context, username string, bookID string) error
+	List(ctx context.Context, username string) ([]domain.Favorite, error)
+}
+
+type redisFavoriteRepository struct {
+	redisClient *redis.Client
+	limit       int
+}
+
+func (r *redisFavoriteRepository) Add(ctx context.Context, username string, favorite domain.Favorite) error {
+	data, err := json.Marshal(favorite)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.redisClient.Eval(ctx, addFavoriteScript, favoriteKeys(username),
+		favorite.Book.ID, favorite.AddedAt.UnixMilli(), r.limit, data).Int()
+	if err != nil {
+		return err
+	}
+
+	switch res {
+	case 0:
+		return domain.ErrFavoriteExists
+	case -1:
+		return domain.ErrFavoritesLimitReached
+	}
+	return nil
+}
+
+func (r *redisFavoriteRepository) Remove(ctx context.Context, username string, bookID string) error {
+	keys := favoriteKeys(username)
+
+	pipe := r.redisClient.TxPipeline()
+	removed := pipe.ZRem(ctx, keys[0], bookID)
+	pipe.HDel(ctx, keys[1], bookID)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	if removed.Val() == 0 {
+		return domain.ErrFavoriteNotFound
+	}
+	return nil
+}
+
+func (r *redisFavoriteRepository) List(ctx context.Context, username string) ([]domain.Favorite, error) {
+	keys := favoriteKeys(username)
+
+	ids, err := r.redisClient.ZRevRange(ctx, keys[0], 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	favorites := make([]domain.Favorite, 0, len(ids))
+	if len(ids) == 0 {
+		return favorites, nil
+	}
+
+	values, err := r.redisClient.HMGet(ctx, keys[1], ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		favorite := domain.Favorite{}
+		err := json.Unmarshal([]byte(data), &favorite)
+		if err != nil {
+			return nil, err
+		}
+		favorites = append(favorites, favorite)
+	}
+
+	return favorites, nil
+}
+
+func favoriteKeys(username string) []string {
+	key := favoritesKeyPrefix + username
+	return []string{key, key + favoriteBooksKeySuffix}
+}
+
+func NewFavoriteRepository(redisClient *redis.Client, limit int) FavoriteRepository {
+	return &redisFavoriteRepository{
+		redisClient: redisClient,
+		limit:       limit,
+	}
+}
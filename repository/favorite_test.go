@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFavoriteAdd(t *testing.T) {
+	favorite := domain.Favorite{Book: domain.Book{ID: "123", Title: "test title"}, AddedAt: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)}
+	data, err := json.Marshal(favorite)
+	assert.NoError(t, err)
+
+	keys := []string{"favorites:test", "favorites:test:books"}
+
+	tests := []struct {
+		name     string
+		result   int64
+		expected error
+	}{
+		{"added", 1, nil},
+		{"already added", 0, domain.ErrFavoriteExists},
+		{"limit reached", -1, domain.ErrFavoritesLimitReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := redismock.NewClientMock()
+
+			repo := NewFavoriteRepository(db, 100)
+
+			mock.ExpectEval(addFavoriteScript, keys, "123", favorite.AddedAt.UnixMilli(), 100, data).SetVal(tt.result)
+
+			err := repo.Add(context.TODO(), "test", favorite)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("redis error", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewFavoriteRepository(db, 100)
+
+		errorMsg := "redis error"
+		mock.ExpectEval(addFavoriteScript, keys, "123", favorite.AddedAt.UnixMilli(), 100, data).SetErr(errors.New(errorMsg))
+
+		err := repo.Add(context.TODO(), "test", favorite)
+		assert.ErrorContains(t, err, errorMsg)
+	})
+}
+
+func TestFavoriteRemove(t *testing.T) {
+	t.Run("removed", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewFavoriteRepository(db, 100)
+
+		mock.ExpectTxPipeline()
+		mock.ExpectZRem("favorites:test", "123").SetVal(1)
+		mock.ExpectHDel("favorites:test:books", "123").SetVal(1)
+		mock.ExpectTxPipelineExec()
+
+		err := repo.Remove(context.TODO(), "test", "123")
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewFavoriteRepository(db, 100)
+
+		mock.ExpectTxPipeline()
+		mock.ExpectZRem("favorites:test", "123").SetVal(0)
+		mock.ExpectHDel("favorites:test:books", "123").SetVal(0)
+		mock.ExpectTxPipelineExec()
+
+		err := repo.Remove(context.TODO(), "test", "123")
+		assert.ErrorIs(t, err, domain.ErrFavoriteNotFound)
+	})
+}
+
+func TestFavoriteList(t *testing.T) {
+	t.Run("newest first", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewFavoriteRepository(db, 100)
+
+		older := domain.Favorite{Book: domain.Book{ID: "1"}, AddedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+		newer := domain.Favorite{Book: domain.Book{ID: "2"}, AddedAt: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)}
+		olderData, err := json.Marshal(older)
+		assert.NoError(t, err)
+		newerData, err := json.Marshal(newer)
+		assert.NoError(t, err)
+
+		mock.ExpectZRevRange("favorites:test", 0, -1).SetVal([]string{"2", "1", "3"})
+		mock.ExpectHMGet("favorites:test:books", "2", "1", "3").SetVal([]interface{}{string(newerData), string(olderData), nil})
+
+		favorites, err := repo.List(context.TODO(), "test")
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Favorite{newer, older}, favorites)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewFavoriteRepository(db, 100)
+
+		mock.ExpectZRevRange("favorites:test", 0, -1).SetVal([]string{})
+
+		favorites, err := repo.List(context.TODO(), "test")
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Favorite{}, favorites)
+	})
+}
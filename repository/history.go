@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	historyKeyPrefix     = "history:"
+	preferencesKeyPrefix = "preferences:"
+	historyEnabledField  = "search_history"
+)
+
+type HistoryRepository interface {
+	Add(ctx context.Context, username string, entry domain.SearchHistoryEntry) error
+	List(ctx context.Context, username string) ([]domain.SearchHistoryEntry, error)
+	Clear(ctx context.Context, username string) error
+	Enabled(ctx context.Context, username string) (bool, error)
+	SetEnabled(ctx context.Context, username string, enabled bool) error
+}
+
+type redisHistoryRepository struct {
+	redisClient *redis.Client
+	size        int
+}
+
+func (r *redisHistoryRepository) Add(ctx context.Context, username string, entry domain.SearchHistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	key := historyKeyPrefix + username
+
+	pipe := r.redisClient.Pipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, int64(r.size-1))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (r *redisHistoryRepository) List(ctx context.Context, username string) ([]domain.SearchHistoryEntry, error) {
+	values, err := r.redisClient.LRange(ctx, historyKeyPrefix+username, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]domain.SearchHistoryEntry, 0, len(values))
+	for _, value := range values {
+		entry := domain.SearchHistoryEntry{}
+		err := json.Unmarshal([]byte(value), &entry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (r *redisHistoryRepository) Clear(ctx context.Context, username string) error {
+	return r.redisClient.Del(ctx, historyKeyPrefix+username).Err()
+}
+
+func (r *redisHistoryRepository) Enabled(ctx context.Context, username string) (bool, error) {
+	val, err := r.redisClient.HGet(ctx, preferencesKeyPrefix+username, historyEnabledField).Result()
+	if errors.Is(err, redis.Nil) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return val != "0", nil
+}
+
+func (r *redisHistoryRepository) SetEnabled(ctx context.Context, username string, enabled bool) error {
+	val := "1"
+	if !enabled {
+		val = "0"
+	}
+	return r.redisClient.HSet(ctx, preferencesKeyPrefix+username, historyEnabledField, val).Err()
+}
+
+func NewHistoryRepository(redisClient *redis.Client, size int) HistoryRepository {
+	return &redisHistoryRepository{
+		redisClient: redisClient,
+		size:        size,
+	}
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryAdd(t *testing.T) {
+	t.Run("pushes and trims", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewHistoryRepository(db, 50)
+
+		entry := domain.SearchHistoryEntry{Query: "test", ResultCount: 3, SearchedAt: time.Now().UTC()}
+		data, err := json.Marshal(entry)
+		assert.NoError(t, err)
+
+		mock.ExpectLPush("history:test", data).SetVal(1)
+		mock.ExpectLTrim("history:test", 0, 49).SetVal("OK")
+
+		err = repo.Add(context.TODO(), "test", entry)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestHistoryList(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewHistoryRepository(db, 50)
+
+		entry := domain.SearchHistoryEntry{Query: "test", ResultCount: 3, SearchedAt: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)}
+		data, err := json.Marshal(entry)
+		assert.NoError(t, err)
+
+		mock.ExpectLRange("history:test", 0, -1).SetVal([]string{string(data)})
+
+		entries, err := repo.List(context.TODO(), "test")
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.SearchHistoryEntry{entry}, entries)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewHistoryRepository(db, 50)
+
+		mock.ExpectLRange("history:test", 0, -1).SetVal([]string{})
+
+		entries, err := repo.List(context.TODO(), "test")
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.SearchHistoryEntry{}, entries)
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewHistoryRepository(db, 50)
+
+		errorMsg := "redis error"
+		mock.ExpectLRange("history:test", 0, -1).SetErr(errors.New(errorMsg))
+
+		_, err := repo.List(context.TODO(), "test")
+		assert.ErrorContains(t, err, errorMsg)
+	})
+}
+
+func TestHistoryClear(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+
+	repo := NewHistoryRepository(db, 50)
+
+	mock.ExpectDel("history:test").SetVal(1)
+
+	err := repo.Clear(context.TODO(), "test")
+	assert.NoError(t, err)
+}
+
+func TestHistoryPreference(t *testing.T) {
+	t.Run("enabled by default", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewHistoryRepository(db, 50)
+
+		mock.ExpectHGet("preferences:test", "search_history").RedisNil()
+
+		enabled, err := repo.Enabled(context.TODO(), "test")
+		assert.NoError(t, err)
+		assert.True(t, enabled)
+	})
+
+	t.Run("opted out", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewHistoryRepository(db, 50)
+
+		mock.ExpectHSet("preferences:test", "search_history", "0").SetVal(1)
+		mock.ExpectHGet("preferences:test", "search_history").SetVal("0")
+
+		err := repo.SetEnabled(context.TODO(), "test", false)
+		assert.NoError(t, err)
+
+		enabled, err := repo.Enabled(context.TODO(), "test")
+		assert.NoError(t, err)
+		assert.False(t, enabled)
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewHistoryRepository(db, 50)
+
+		errorMsg := "redis error"
+		mock.ExpectHGet("preferences:test", "search_history").SetErr(errors.New(errorMsg))
+
+		_, err := repo.Enabled(context.TODO(), "test")
+		assert.ErrorContains(t, err, errorMsg)
+	})
+}
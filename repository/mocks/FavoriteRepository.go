@@ -0,0 +1,84 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// FavoriteRepository is an autogenerated mock type for the FavoriteRepository type
+type FavoriteRepository struct {
+	mock.Mock
+}
+
+// Add provides a mock function with given fields: ctx, username, favorite
+func (_m *FavoriteRepository) Add(ctx context.Context, username string, favorite domain.Favorite) error {
+	ret := _m.Called(ctx, username, favorite)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Favorite) error); ok {
+		r0 = rf(ctx, username, favorite)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// List provides a mock function with given fields: ctx, username
+func (_m *FavoriteRepository) List(ctx context.Context, username string) ([]domain.Favorite, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 []domain.Favorite
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.Favorite, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.Favorite); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Favorite)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Remove provides a mock function with given fields: ctx, username, bookID
+func (_m *FavoriteRepository) Remove(ctx context.Context, username string, bookID string) error {
+	ret := _m.Called(ctx, username, bookID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, username, bookID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewFavoriteRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewFavoriteRepository creates a new instance of FavoriteRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewFavoriteRepository(t mockConstructorTestingTNewFavoriteRepository) *FavoriteRepository {
+	mock := &FavoriteRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,122 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// HistoryRepository is an autogenerated mock type for the HistoryRepository type
+type HistoryRepository struct {
+	mock.Mock
+}
+
+// Add provides a mock function with given fields: ctx, username, entry
+func (_m *HistoryRepository) Add(ctx context.Context, username string, entry domain.SearchHistoryEntry) error {
+	ret := _m.Called(ctx, username, entry)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.SearchHistoryEntry) error); ok {
+		r0 = rf(ctx, username, entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Clear provides a mock function with given fields: ctx, username
+func (_m *HistoryRepository) Clear(ctx context.Context, username string) error {
+	ret := _m.Called(ctx, username)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Enabled provides a mock function with given fields: ctx, username
+func (_m *HistoryRepository) Enabled(ctx context.Context, username string) (bool, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, username
+func (_m *HistoryRepository) List(ctx context.Context, username string) ([]domain.SearchHistoryEntry, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 []domain.SearchHistoryEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.SearchHistoryEntry, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.SearchHistoryEntry); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SearchHistoryEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetEnabled provides a mock function with given fields: ctx, username, enabled
+func (_m *HistoryRepository) SetEnabled(ctx context.Context, username string, enabled bool) error {
+	ret := _m.Called(ctx, username, enabled)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) error); ok {
+		r0 = rf(ctx, username, enabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewHistoryRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewHistoryRepository creates a new instance of HistoryRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewHistoryRepository(t mockConstructorTestingTNewHistoryRepository) *HistoryRepository {
+	mock := &HistoryRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
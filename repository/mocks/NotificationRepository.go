@@ -0,0 +1,70 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NotificationRepository is an autogenerated mock type for the NotificationRepository type
+type NotificationRepository struct {
+	mock.Mock
+}
+
+// Add provides a mock function with given fields: ctx, username, notification
+func (_m *NotificationRepository) Add(ctx context.Context, username string, notification domain.Notification) error {
+	ret := _m.Called(ctx, username, notification)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Notification) error); ok {
+		r0 = rf(ctx, username, notification)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// List provides a mock function with given fields: ctx, username
+func (_m *NotificationRepository) List(ctx context.Context, username string) ([]domain.Notification, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 []domain.Notification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.Notification, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.Notification); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Notification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewNotificationRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewNotificationRepository creates a new instance of NotificationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewNotificationRepository(t mockConstructorTestingTNewNotificationRepository) *NotificationRepository {
+	mock := &NotificationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,245 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// SavedSearchRepository is an autogenerated mock type for the SavedSearchRepository type
+type SavedSearchRepository struct {
+	mock.Mock
+}
+
+// AcquireRunLock provides a mock function with given fields: ctx, token, ttl
+func (_m *SavedSearchRepository) AcquireRunLock(ctx context.Context, token string, ttl time.Duration) (bool, error) {
+	ret := _m.Called(ctx, token, ttl)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) (bool, error)); ok {
+		return rf(ctx, token, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) bool); ok {
+		r0 = rf(ctx, token, ttl)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = rf(ctx, token, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddSeenBooks provides a mock function with given fields: ctx, search, bookIDs, seenAt
+func (_m *SavedSearchRepository) AddSeenBooks(ctx context.Context, search domain.SavedSearch, bookIDs []string, seenAt time.Time) error {
+	ret := _m.Called(ctx, search, bookIDs, seenAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SavedSearch, []string, time.Time) error); ok {
+		r0 = rf(ctx, search, bookIDs, seenAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// All provides a mock function with given fields: ctx
+func (_m *SavedSearchRepository) All(ctx context.Context) ([]domain.SavedSearch, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []domain.SavedSearch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.SavedSearch, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.SavedSearch); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SavedSearch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, search
+func (_m *SavedSearchRepository) Create(ctx context.Context, search domain.SavedSearch) error {
+	ret := _m.Called(ctx, search)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SavedSearch) error); ok {
+		r0 = rf(ctx, search)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, username, id
+func (_m *SavedSearchRepository) Delete(ctx context.Context, username string, id string) error {
+	ret := _m.Called(ctx, username, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, username, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: ctx, username, id
+func (_m *SavedSearchRepository) Get(ctx context.Context, username string, id string) (domain.SavedSearch, error) {
+	ret := _m.Called(ctx, username, id)
+
+	var r0 domain.SavedSearch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (domain.SavedSearch, error)); ok {
+		return rf(ctx, username, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) domain.SavedSearch); ok {
+		r0 = rf(ctx, username, id)
+	} else {
+		r0 = ret.Get(0).(domain.SavedSearch)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, username, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, username
+func (_m *SavedSearchRepository) List(ctx context.Context, username string) ([]domain.SavedSearch, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 []domain.SavedSearch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.SavedSearch, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.SavedSearch); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SavedSearch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RenewRunLock provides a mock function with given fields: ctx, token, ttl
+func (_m *SavedSearchRepository) RenewRunLock(ctx context.Context, token string, ttl time.Duration) (bool, error) {
+	ret := _m.Called(ctx, token, ttl)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) (bool, error)); ok {
+		return rf(ctx, token, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) bool); ok {
+		r0 = rf(ctx, token, ttl)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = rf(ctx, token, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SeenBooks provides a mock function with given fields: ctx, search
+func (_m *SavedSearchRepository) SeenBooks(ctx context.Context, search domain.SavedSearch) ([]string, bool, error) {
+	ret := _m.Called(ctx, search)
+
+	var r0 []string
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SavedSearch) ([]string, bool, error)); ok {
+		return rf(ctx, search)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SavedSearch) []string); ok {
+		r0 = rf(ctx, search)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SavedSearch) bool); ok {
+		r1 = rf(ctx, search)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, domain.SavedSearch) error); ok {
+		r2 = rf(ctx, search)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Update provides a mock function with given fields: ctx, search
+func (_m *SavedSearchRepository) Update(ctx context.Context, search domain.SavedSearch) error {
+	ret := _m.Called(ctx, search)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SavedSearch) error); ok {
+		r0 = rf(ctx, search)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewSavedSearchRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSavedSearchRepository creates a new instance of SavedSearchRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSavedSearchRepository(t mockConstructorTestingTNewSavedSearchRepository) *SavedSearchRepository {
+	mock := &SavedSearchRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
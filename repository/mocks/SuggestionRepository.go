@@ -0,0 +1,96 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SuggestionRepository is an autogenerated mock type for the SuggestionRepository type
+type SuggestionRepository struct {
+	mock.Mock
+}
+
+// Index provides a mock function with given fields: ctx, books
+func (_m *SuggestionRepository) Index(ctx context.Context, books []domain.Book) error {
+	ret := _m.Called(ctx, books)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.Book) error); ok {
+		r0 = rf(ctx, books)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Suggest provides a mock function with given fields: ctx, prefix, limit
+func (_m *SuggestionRepository) Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error) {
+	ret := _m.Called(ctx, prefix, limit)
+
+	var r0 []domain.Suggestion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]domain.Suggestion, error)); ok {
+		return rf(ctx, prefix, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []domain.Suggestion); ok {
+		r0 = rf(ctx, prefix, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Suggestion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, prefix, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Vocabulary provides a mock function with given fields: ctx
+func (_m *SuggestionRepository) Vocabulary(ctx context.Context) (map[string]float64, error) {
+	ret := _m.Called(ctx)
+
+	var r0 map[string]float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (map[string]float64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) map[string]float64); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]float64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewSuggestionRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSuggestionRepository creates a new instance of SuggestionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSuggestionRepository(t mockConstructorTestingTNewSuggestionRepository) *SuggestionRepository {
+	mock := &SuggestionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,171 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// WebhookDeliveryRepository is an autogenerated mock type for the WebhookDeliveryRepository type
+type WebhookDeliveryRepository struct {
+	mock.Mock
+}
+
+// Claim provides a mock function with given fields: ctx, now, lease, limit
+func (_m *WebhookDeliveryRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	ret := _m.Called(ctx, now, lease, limit)
+
+	var r0 []domain.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Duration, int) ([]domain.WebhookDelivery, error)); ok {
+		return rf(ctx, now, lease, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Duration, int) []domain.WebhookDelivery); ok {
+		r0 = rf(ctx, now, lease, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Duration, int) error); ok {
+		r1 = rf(ctx, now, lease, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Complete provides a mock function with given fields: ctx, delivery
+func (_m *WebhookDeliveryRepository) Complete(ctx context.Context, delivery domain.WebhookDelivery) error {
+	ret := _m.Called(ctx, delivery)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.WebhookDelivery) error); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeadLetter provides a mock function with given fields: ctx, delivery
+func (_m *WebhookDeliveryRepository) DeadLetter(ctx context.Context, delivery domain.WebhookDelivery) error {
+	ret := _m.Called(ctx, delivery)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.WebhookDelivery) error); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Enqueue provides a mock function with given fields: ctx, deliveries
+func (_m *WebhookDeliveryRepository) Enqueue(ctx context.Context, deliveries ...domain.WebhookDelivery) error {
+	_va := make([]interface{}, len(deliveries))
+	for _i := range deliveries {
+		_va[_i] = deliveries[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...domain.WebhookDelivery) error); ok {
+		r0 = rf(ctx, deliveries...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: ctx, id
+func (_m *WebhookDeliveryRepository) Get(ctx context.Context, id string) (domain.WebhookDelivery, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.WebhookDelivery, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.WebhookDelivery); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.WebhookDelivery)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Log provides a mock function with given fields: ctx, webhookID
+func (_m *WebhookDeliveryRepository) Log(ctx context.Context, webhookID string) ([]domain.WebhookDelivery, error) {
+	ret := _m.Called(ctx, webhookID)
+
+	var r0 []domain.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.WebhookDelivery, error)); ok {
+		return rf(ctx, webhookID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.WebhookDelivery); ok {
+		r0 = rf(ctx, webhookID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, webhookID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Retry provides a mock function with given fields: ctx, delivery
+func (_m *WebhookDeliveryRepository) Retry(ctx context.Context, delivery domain.WebhookDelivery) error {
+	ret := _m.Called(ctx, delivery)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.WebhookDelivery) error); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewWebhookDeliveryRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWebhookDeliveryRepository creates a new instance of WebhookDeliveryRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWebhookDeliveryRepository(t mockConstructorTestingTNewWebhookDeliveryRepository) *WebhookDeliveryRepository {
+	mock := &WebhookDeliveryRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
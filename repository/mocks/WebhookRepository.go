@@ -0,0 +1,122 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WebhookRepository is an autogenerated mock type for the WebhookRepository type
+type WebhookRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, webhook
+func (_m *WebhookRepository) Create(ctx context.Context, webhook domain.Webhook) error {
+	ret := _m.Called(ctx, webhook)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Webhook) error); ok {
+		r0 = rf(ctx, webhook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, username, id
+func (_m *WebhookRepository) Delete(ctx context.Context, username string, id string) error {
+	ret := _m.Called(ctx, username, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, username, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: ctx, id
+func (_m *WebhookRepository) Get(ctx context.Context, id string) (domain.Webhook, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.Webhook, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.Webhook); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Webhook)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, username
+func (_m *WebhookRepository) List(ctx context.Context, username string) ([]domain.Webhook, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 []domain.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.Webhook, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.Webhook); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, webhook
+func (_m *WebhookRepository) Update(ctx context.Context, webhook domain.Webhook) error {
+	ret := _m.Called(ctx, webhook)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Webhook) error); ok {
+		r0 = rf(ctx, webhook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewWebhookRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWebhookRepository(t mockConstructorTestingTNewWebhookRepository) *WebhookRepository {
+	mock := &WebhookRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
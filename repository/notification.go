@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const notificationsKeyPrefix = "notifications:"
+
+type NotificationRepository interface {
+	Add(ctx context.Context, username string, notification domain.Notification) error
+	List(ctx context.Context, username string) ([]domain.Notification, error)
+}
+
+type redisNotificationRepository struct {
+	redisClient *redis.Client
+	size        int
+}
+
+func (r *redisNotificationRepository) Add(ctx context.Context, username string, notification domain.Notification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	key := notificationsKeyPrefix + username
+
+	pipe := r.redisClient.Pipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, int64(r.size-1))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (r *redisNotificationRepository) List(ctx context.Context, username string) ([]domain.Notification, error) {
+	values, err := r.redisClient.LRange(ctx, notificationsKeyPrefix+username, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make([]domain.Notification, 0, len(values))
+	for _, value := range values {
+		notification := domain.Notification{}
+		err := json.Unmarshal([]byte(value), &notification)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+func NewNotificationRepository(redisClient *redis.Client, size int) NotificationRepository {
+	return &redisNotificationRepository{
+		redisClient: redisClient,
+		size:        size,
+	}
+}
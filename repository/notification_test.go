@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationAdd(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+
+	repo := NewNotificationRepository(db, 100)
+
+	notification := domain.Notification{ID: "1", SavedSearchID: "2", Keyword: "test", CreatedAt: time.Now().UTC()}
+	data, err := json.Marshal(notification)
+	assert.NoError(t, err)
+
+	mock.ExpectLPush("notifications:test", data).SetVal(1)
+	mock.ExpectLTrim("notifications:test", 0, 99).SetVal("OK")
+
+	err = repo.Add(context.TODO(), "test", notification)
+	assert.NoError(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestNotificationList(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewNotificationRepository(db, 100)
+
+		notification := domain.Notification{ID: "1", Keyword: "test", NewBooks: []domain.Book{{ID: "123"}}, CreatedAt: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)}
+		data, err := json.Marshal(notification)
+		assert.NoError(t, err)
+
+		mock.ExpectLRange("notifications:test", 0, -1).SetVal([]string{string(data)})
+
+		notifications, err := repo.List(context.TODO(), "test")
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Notification{notification}, notifications)
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewNotificationRepository(db, 100)
+
+		errorMsg := "redis error"
+		mock.ExpectLRange("notifications:test", 0, -1).SetErr(errors.New(errorMsg))
+
+		_, err := repo.List(context.TODO(), "test")
+		assert.ErrorContains(t, err, errorMsg)
+	})
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	savedSearchesKeyPrefix = "saved-searches:"
+	allSavedSearchesKey    = "saved-searches:all"
+	seenBooksKeyPrefix     = "saved-searches:seen:"
+	savedSearchRunLockKey  = "saved-searches:lock"
+
+	// seenBooksLimit is how many of the books most recently found by a saved search are remembered.
+	seenBooksLimit = 500
+
+	// seenBooksPlaceholder keeps the seen books of a search that found nothing from being an absent key, so
+	// that its first results are still reported as new.
+	seenBooksPlaceholder = ""
+)
+
+// createSavedSearchScript stores a saved search unless the user already has as many as allowed.
+const createSavedSearchScript = `
+if redis.call('HLEN', KEYS[1]) >= tonumber(ARGV[3]) then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('SADD', KEYS[2], ARGV[4])
+return 1
+`
+
+// updateSavedSearchScript replaces a saved search and drops its seen books when the keyword changed, so that
+// the next run starts over instead of reporting every result of the new keyword as new.
+const updateSavedSearchScript = `
+local current = redis.call('HGET', KEYS[1], ARGV[1])
+if not current then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+if cjson.decode(current).keyword ~= ARGV[3] then
+	redis.call('DEL', KEYS[2])
+end
+return 1
+`
+
+// renewRunLockScript extends the run lock if it is still held with the token in ARGV[1].
+const renewRunLockScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`
+
+type SavedSearchRepository interface {
+	Create(ctx context.Context, search domain.SavedSearch) error
+	Get(ctx context.Context, username string, id string) (domain.SavedSearch, error)
+	List(ctx context.Context, username string) ([]domain.SavedSearch, error)
+	Update(ctx context.Context, search domain.SavedSearch) error
+	Delete(ctx context.Context, username string, id string) error
+	All(ctx context.Context) ([]domain.SavedSearch, error)
+	SeenBooks(ctx context.Context, search domain.SavedSearch) ([]string, bool, error)
+	AddSeenBooks(ctx context.Context, search domain.SavedSearch, bookIDs []string, seenAt time.Time) error
+	AcquireRunLock(ctx context.Context, token string, ttl time.Duration) (bool, error)
+	RenewRunLock(ctx context.Context, token string, ttl time.Duration) (bool, error)
+}
+
+type redisSavedSearchRepository struct {
+	redisClient *redis.Client
+	limit       int
+}
+
+func (r *redisSavedSearchRepository) Create(ctx context.Context, search domain.SavedSearch) error {
+	data, err := json.Marshal(search)
+	if err != nil {
+		return err
+	}
+
+	created, err := r.redisClient.Eval(ctx, createSavedSearchScript,
+		[]string{savedSearchesKeyPrefix + search.Username, allSavedSearchesKey},
+		search.ID, data, r.limit, savedSearchMember(search.Username, search.ID)).Int()
+	if err != nil {
+		return err
+	}
+	if created == 0 {
+		return domain.ErrSavedSearchLimitReached
+	}
+	return nil
+}
+
+func (r *redisSavedSearchRepository) Get(ctx context.Context, username string, id string) (domain.SavedSearch, error) {
+	val, err := r.redisClient.HGet(ctx, savedSearchesKeyPrefix+username, id).Result()
+	if errors.Is(err, redis.Nil) {
+		return domain.SavedSearch{}, domain.ErrSavedSearchNotFound
+	}
+	if err != nil {
+		return domain.SavedSearch{}, err
+	}
+
+	return decodeSavedSearch(username, val)
+}
+
+func (r *redisSavedSearchRepository) List(ctx context.Context, username string) ([]domain.SavedSearch, error) {
+	values, err := r.redisClient.HVals(ctx, savedSearchesKeyPrefix+username).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	searches := make([]domain.SavedSearch, 0, len(values))
+	for _, val := range values {
+		search, err := decodeSavedSearch(username, val)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+
+	return searches, nil
+}
+
+func (r *redisSavedSearchRepository) Update(ctx context.Context, search domain.SavedSearch) error {
+	data, err := json.Marshal(search)
+	if err != nil {
+		return err
+	}
+
+	updated, err := r.redisClient.Eval(ctx, updateSavedSearchScript,
+		[]string{savedSearchesKeyPrefix + search.Username, seenBooksKey(search.Username, search.ID)},
+		search.ID, data, search.Keyword).Int()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return domain.ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+func (r *redisSavedSearchRepository) Delete(ctx context.Context, username string, id string) error {
+	pipe := r.redisClient.TxPipeline()
+	removed := pipe.HDel(ctx, savedSearchesKeyPrefix+username, id)
+	pipe.SRem(ctx, allSavedSearchesKey, savedSearchMember(username, id))
+	pipe.Del(ctx, seenBooksKey(username, id))
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	if removed.Val() == 0 {
+		return domain.ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+// All returns the saved searches of every user, skipping the ones deleted since they were listed.
+func (r *redisSavedSearchRepository) All(ctx context.Context) ([]domain.SavedSearch, error) {
+	members, err := r.redisClient.SMembers(ctx, allSavedSearchesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	searches := make([]domain.SavedSearch, 0, len(members))
+	for _, member := range members {
+		i := strings.LastIndex(member, memberSeparator)
+		if i < 0 {
+			continue
+		}
+		username, id := member[:i], member[i+len(memberSeparator):]
+
+		search, err := r.Get(ctx, username, id)
+		if errors.Is(err, domain.ErrSavedSearchNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+
+	return searches, nil
+}
+
+// SeenBooks returns the IDs of the books recently found by a saved search and whether it has run before.
+func (r *redisSavedSearchRepository) SeenBooks(ctx context.Context, search domain.SavedSearch) ([]string, bool, error) {
+	members, err := r.redisClient.ZRange(ctx, seenBooksKey(search.Username, search.ID), 0, -1).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	bookIDs := make([]string, 0, len(members))
+	for _, id := range members {
+		if id != seenBooksPlaceholder {
+			bookIDs = append(bookIDs, id)
+		}
+	}
+
+	return bookIDs, len(members) > 0, nil
+}
+
+// AddSeenBooks records that a run of a saved search found the books at seenAt. Only the seenBooksLimit books
+// found most recently are kept, so a book that drops out of the results and comes back is not reported as new
+// again unless other books pushed it out in the meantime.
+func (r *redisSavedSearchRepository) AddSeenBooks(ctx context.Context, search domain.SavedSearch, bookIDs []string, seenAt time.Time) error {
+	key := seenBooksKey(search.Username, search.ID)
+	score := float64(seenAt.UnixMilli())
+
+	members := make([]redis.Z, 0, len(bookIDs)+1)
+	members = append(members, redis.Z{Score: score, Member: seenBooksPlaceholder})
+	for _, id := range bookIDs {
+		members = append(members, redis.Z{Score: score, Member: id})
+	}
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.ZAdd(ctx, key, members...)
+	pipe.ZRemRangeByRank(ctx, key, 0, -seenBooksLimit-2)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// AcquireRunLock makes sure only one instance runs the saved searches in each interval. The lock is held with
+// token, which RenewRunLock needs to extend it.
+func (r *redisSavedSearchRepository) AcquireRunLock(ctx context.Context, token string, ttl time.Duration) (bool, error) {
+	return r.redisClient.SetNX(ctx, savedSearchRunLockKey, token, ttl).Result()
+}
+
+// RenewRunLock extends the run lock to ttl and reports false if it is no longer held with token.
+func (r *redisSavedSearchRepository) RenewRunLock(ctx context.Context, token string, ttl time.Duration) (bool, error) {
+	renewed, err := r.redisClient.Eval(ctx, renewRunLockScript, []string{savedSearchRunLockKey}, token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return renewed == 1, nil
+}
+
+func decodeSavedSearch(username, val string) (domain.SavedSearch, error) {
+	search := domain.SavedSearch{}
+	err := json.Unmarshal([]byte(val), &search)
+	if err != nil {
+		return domain.SavedSearch{}, err
+	}
+	search.Username = username
+	return search, nil
+}
+
+func savedSearchMember(username, id string) string {
+	return username + memberSeparator + id
+}
+
+func seenBooksKey(username, id string) string {
+	return seenBooksKeyPrefix + username + ":" + id
+}
+
+func NewSavedSearchRepository(redisClient *redis.Client, limit int) SavedSearchRepository {
+	return &redisSavedSearchRepository{
+		redisClient: redisClient,
+		limit:       limit,
+	}
+}
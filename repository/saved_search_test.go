@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedSearchCreate(t *testing.T) {
+	search := domain.SavedSearch{ID: "abc", Username: "test", Keyword: "kafka", CreatedAt: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)}
+	data, err := json.Marshal(search)
+	assert.NoError(t, err)
+
+	keys := []string{"saved-searches:test", "saved-searches:all"}
+
+	t.Run("created", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSavedSearchRepository(db, 20)
+
+		mock.ExpectEval(createSavedSearchScript, keys, "abc", data, 20, "test|abc").SetVal(int64(1))
+
+		err := repo.Create(context.TODO(), search)
+		assert.NoError(t, err)
+	})
+
+	t.Run("limit reached", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSavedSearchRepository(db, 20)
+
+		mock.ExpectEval(createSavedSearchScript, keys, "abc", data, 20, "test|abc").SetVal(int64(0))
+
+		err := repo.Create(context.TODO(), search)
+		assert.ErrorIs(t, err, domain.ErrSavedSearchLimitReached)
+	})
+}
+
+func TestSavedSearchGet(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSavedSearchRepository(db, 20)
+
+		mock.ExpectHGet("saved-searches:test", "abc").SetVal(`{"id":"abc","keyword":"kafka"}`)
+
+		search, err := repo.Get(context.TODO(), "test", "abc")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.SavedSearch{ID: "abc", Username: "test", Keyword: "kafka"}, search)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSavedSearchRepository(db, 20)
+
+		mock.ExpectHGet("saved-searches:test", "abc").RedisNil()
+
+		_, err := repo.Get(context.TODO(), "test", "abc")
+		assert.ErrorIs(t, err, domain.ErrSavedSearchNotFound)
+	})
+}
+
+func TestSavedSearchUpdate(t *testing.T) {
+	search := domain.SavedSearch{ID: "abc", Username: "test", Keyword: "kafka"}
+	data, err := json.Marshal(search)
+	assert.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSavedSearchRepository(db, 20)
+
+		mock.ExpectEval(updateSavedSearchScript, []string{"saved-searches:test", "saved-searches:seen:test:abc"}, "abc", data, "kafka").SetVal(int64(1))
+
+		err := repo.Update(context.TODO(), search)
+		assert.NoError(t, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSavedSearchRepository(db, 20)
+
+		mock.ExpectEval(updateSavedSearchScript, []string{"saved-searches:test", "saved-searches:seen:test:abc"}, "abc", data, "kafka").SetVal(int64(0))
+
+		err := repo.Update(context.TODO(), search)
+		assert.ErrorIs(t, err, domain.ErrSavedSearchNotFound)
+	})
+}
+
+func TestSavedSearchDelete(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+
+	repo := NewSavedSearchRepository(db, 20)
+
+	mock.ExpectTxPipeline()
+	mock.ExpectHDel("saved-searches:test", "abc").SetVal(0)
+	mock.ExpectSRem("saved-searches:all", "test|abc").SetVal(0)
+	mock.ExpectDel("saved-searches:seen:test:abc").SetVal(0)
+	mock.ExpectTxPipelineExec()
+
+	err := repo.Delete(context.TODO(), "test", "abc")
+	assert.ErrorIs(t, err, domain.ErrSavedSearchNotFound)
+}
+
+func TestSavedSearchAll(t *testing.T) {
+	t.Run("skips deleted searches", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSavedSearchRepository(db, 20)
+
+		mock.ExpectSMembers("saved-searches:all").SetVal([]string{"a|b|abc", "test|def"})
+		mock.ExpectHGet("saved-searches:a|b", "abc").SetVal(`{"id":"abc","keyword":"kafka"}`)
+		mock.ExpectHGet("saved-searches:test", "def").RedisNil()
+
+		searches, err := repo.All(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.SavedSearch{{ID: "abc", Username: "a|b", Keyword: "kafka"}}, searches)
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSavedSearchRepository(db, 20)
+
+		errorMsg := "redis error"
+		mock.ExpectSMembers("saved-searches:all").SetErr(errors.New(errorMsg))
+
+		_, err := repo.All(context.TODO())
+		assert.ErrorContains(t, err, errorMsg)
+	})
+}
+
+func TestSavedSearchSeenBooks(t *testing.T) {
+	search := domain.SavedSearch{ID: "abc", Username: "test"}
+
+	t.Run("read", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSavedSearchRepository(db, 20)
+
+		mock.ExpectZRange("saved-searches:seen:test:abc", 0, -1).SetVal([]string{"1", "2", ""})
+
+		ids, ok, err := repo.SeenBooks(context.TODO(), search)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"1", "2"}, ids)
+	})
+
+	t.Run("never run", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSavedSearchRepository(db, 20)
+
+		mock.ExpectZRange("saved-searches:seen:test:abc", 0, -1).SetVal([]string{})
+
+		ids, ok, err := repo.SeenBooks(context.TODO(), search)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("write", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSavedSearchRepository(db, 20)
+		seenAt := time.UnixMilli(1700000000000)
+
+		mock.ExpectTxPipeline()
+		mock.ExpectZAdd("saved-searches:seen:test:abc",
+			redis.Z{Score: 1700000000000, Member: ""},
+			redis.Z{Score: 1700000000000, Member: "1"},
+			redis.Z{Score: 1700000000000, Member: "2"},
+		).SetVal(3)
+		mock.ExpectZRemRangeByRank("saved-searches:seen:test:abc", 0, -502).SetVal(0)
+		mock.ExpectTxPipelineExec()
+
+		err := repo.AddSeenBooks(context.TODO(), search, []string{"1", "2"}, seenAt)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestSavedSearchRunLock(t *testing.T) {
+	t.Run("acquire", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSavedSearchRepository(db, 20)
+
+		mock.ExpectSetNX("saved-searches:lock", "token", time.Minute).SetVal(true)
+
+		acquired, err := repo.AcquireRunLock(context.TODO(), "token", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+	})
+
+	t.Run("renew", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSavedSearchRepository(db, 20)
+
+		mock.ExpectEval(renewRunLockScript, []string{"saved-searches:lock"}, "token", int64(60000)).SetVal(int64(1))
+		mock.ExpectEval(renewRunLockScript, []string{"saved-searches:lock"}, "token", int64(60000)).SetVal(int64(0))
+
+		renewed, err := repo.RenewRunLock(context.TODO(), "token", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, renewed)
+
+		renewed, err = repo.RenewRunLock(context.TODO(), "token", time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, renewed)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/normalize"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	suggestionKeyPrefix = "suggest:prefix:"
+	vocabularyKey       = "suggest:vocabulary"
+	vocabularySeenKey   = "suggest:vocabulary:seen"
+
+	minIndexedPrefix   = 1
+	maxIndexedPrefix   = 20
+	maxSuggestionsKept = 50
+	minVocabularyWord  = 2
+	maxVocabularySize  = 5000
+
+	memberSeparator = "|"
+
+	suggestionTTL = 30 * 24 * time.Hour
+)
+
+// indexSuggestionScript counts a suggestion under a prefix and keeps the prefix to its most indexed suggestions.
+// The suggestion just indexed is never the one evicted, so that new titles are not dropped before they can
+// gather a score. Prefixes that are not indexed again expire.
+const indexSuggestionScript = `
+redis.call('ZINCRBY', KEYS[1], 1, ARGV[1])
+local excess = redis.call('ZCARD', KEYS[1]) - tonumber(ARGV[2])
+if excess > 0 then
+	for _, member in ipairs(redis.call('ZRANGE', KEYS[1], 0, excess)) do
+		if excess == 0 then
+			break
+		end
+		if member ~= ARGV[1] then
+			redis.call('ZREM', KEYS[1], member)
+			excess = excess - 1
+		end
+	end
+end
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+return 1
+`
+
+var indexSuggestion = redis.NewScript(indexSuggestionScript)
+
+// addVocabularyScript counts the words of ARGV from the second on, records when each was last seen and keeps
+// the vocabulary to its ARGV[1] most recently seen words. Trimming by count would evict the words that were
+// just added, since they have the lowest counts.
+const addVocabularyScript = `
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+for i = 2, #ARGV do
+	redis.call('ZINCRBY', KEYS[1], 1, ARGV[i])
+	redis.call('ZADD', KEYS[2], now, ARGV[i])
+end
+local excess = redis.call('ZCARD', KEYS[2]) - tonumber(ARGV[1])
+if excess > 0 then
+	for _, word in ipairs(redis.call('ZRANGE', KEYS[2], 0, excess - 1)) do
+		redis.call('ZREM', KEYS[1], word)
+		redis.call('ZREM', KEYS[2], word)
+	end
+end
+return 1
+`
+
+var addVocabulary = redis.NewScript(addVocabularyScript)
+
+type SuggestionRepository interface {
+	Index(ctx context.Context, books []domain.Book) error
+	Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error)
+	Vocabulary(ctx context.Context) (map[string]float64, error)
+}
+
+type redisSuggestionRepository struct {
+	redisClient *redis.Client
+}
+
+func (r *redisSuggestionRepository) Index(ctx context.Context, books []domain.Book) error {
+	pipe := r.redisClient.Pipeline()
+
+	seen := map[string]bool{}
+	words := []interface{}{maxVocabularySize}
+	for _, book := range books {
+		words = r.indexTerm(ctx, pipe, seen, words, domain.Suggestion{Text: book.Title, Type: domain.SuggestionTypeTitle})
+		for _, author := range book.Authors {
+			words = r.indexTerm(ctx, pipe, seen, words, domain.Suggestion{Text: author.Name, Type: domain.SuggestionTypeAuthor})
+		}
+	}
+
+	if pipe.Len() == 0 {
+		return nil
+	}
+	cmds, err := pipe.Exec(ctx)
+	if err != nil && redis.HasErrorPrefix(err, "NOSCRIPT") {
+		err = r.indexWithScript(ctx, cmds)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(words) == 1 {
+		return nil
+	}
+	return addVocabulary.Run(ctx, r.redisClient, []string{vocabularyKey, vocabularySeenKey}, words...).Err()
+}
+
+// indexWithScript runs the prefix updates that failed because Redis did not have the script cached, such as
+// after a restart, again with the script's source, which caches it for the next calls.
+func (r *redisSuggestionRepository) indexWithScript(ctx context.Context, cmds []redis.Cmder) error {
+	pipe := r.redisClient.Pipeline()
+	for _, cmd := range cmds {
+		if !redis.HasErrorPrefix(cmd.Err(), "NOSCRIPT") {
+			continue
+		}
+		// The arguments of EVALSHA are the hash, the number of keys, the key and the script arguments.
+		args := cmd.Args()
+		indexSuggestion.Eval(ctx, pipe, []string{args[3].(string)}, args[4:]...)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// indexTerm queues the prefixes of a suggestion on pipe and returns words with the vocabulary words of the
+// suggestion appended.
+func (r *redisSuggestionRepository) indexTerm(ctx context.Context, pipe redis.Pipeliner, seen map[string]bool, words []interface{}, s domain.Suggestion) []interface{} {
+	s.Text = normalize.Query(s.Text)
+	member := encodeSuggestion(s)
+	if s.Text == "" || seen[member] {
+		return words
+	}
+	seen[member] = true
+
+	for _, prefix := range prefixes(suggestionTerm(s.Text)) {
+		indexSuggestion.EvalSha(ctx, pipe, []string{suggestionKeyPrefix + prefix}, member, maxSuggestionsKept, int(suggestionTTL.Seconds()))
+	}
+
+	for _, word := range strings.Fields(suggestionTerm(s.Text)) {
+		if utf8.RuneCountInString(word) >= minVocabularyWord {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func (r *redisSuggestionRepository) Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error) {
+	term := suggestionTerm(prefix)
+	if utf8.RuneCountInString(term) < minIndexedPrefix {
+		return []domain.Suggestion{}, nil
+	}
+
+	key := term
+	if utf8.RuneCountInString(term) > maxIndexedPrefix {
+		key = string([]rune(term)[:maxIndexedPrefix])
+	}
+
+	members, err := r.redisClient.ZRevRange(ctx, suggestionKeyPrefix+key, 0, maxSuggestionsKept-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []domain.Suggestion{}
+	for _, member := range members {
+		s, ok := decodeSuggestion(member)
+		if !ok || !strings.HasPrefix(suggestionTerm(s.Text), term) {
+			continue
+		}
+		suggestions = append(suggestions, s)
+		if len(suggestions) == limit {
+			break
+		}
+	}
+
+	return suggestions, nil
+}
+
+func (r *redisSuggestionRepository) Vocabulary(ctx context.Context) (map[string]float64, error) {
+	words, err := r.redisClient.ZRangeWithScores(ctx, vocabularyKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	vocabulary := make(map[string]float64, len(words))
+	for _, word := range words {
+		member, ok := word.Member.(string)
+		if ok {
+			vocabulary[member] = word.Score
+		}
+	}
+
+	return vocabulary, nil
+}
+
+func suggestionTerm(s string) string {
+	return strings.ToLower(normalize.Query(s))
+}
+
+func prefixes(term string) []string {
+	runes := []rune(term)
+
+	var result []string
+	for i := minIndexedPrefix; i <= len(runes) && i <= maxIndexedPrefix; i++ {
+		result = append(result, string(runes[:i]))
+	}
+	return result
+}
+
+func encodeSuggestion(s domain.Suggestion) string {
+	return s.Type + memberSeparator + s.Text
+}
+
+func decodeSuggestion(member string) (domain.Suggestion, bool) {
+	parts := strings.SplitN(member, memberSeparator, 2)
+	if len(parts) != 2 {
+		return domain.Suggestion{}, false
+	}
+	return domain.Suggestion{Type: parts[0], Text: parts[1]}, true
+}
+
+func NewSuggestionRepository(redisClient *redis.Client) SuggestionRepository {
+	return &redisSuggestionRepository{
+		redisClient: redisClient,
+	}
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestionIndex(t *testing.T) {
+	t.Run("indexes title and author prefixes", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSuggestionRepository(db)
+
+		books := []domain.Book{
+			{ID: "1", Title: "Ab", Authors: []domain.Author{{Name: "c"}}},
+			{ID: "2", Title: "Ab", Authors: []domain.Author{{Name: "c"}}},
+		}
+
+		mock.ExpectEvalSha(indexSuggestion.Hash(), []string{"suggest:prefix:a"}, "title|Ab", 50, 2592000).SetVal(int64(1))
+		mock.ExpectEvalSha(indexSuggestion.Hash(), []string{"suggest:prefix:ab"}, "title|Ab", 50, 2592000).SetVal(int64(1))
+		mock.ExpectEvalSha(indexSuggestion.Hash(), []string{"suggest:prefix:c"}, "author|c", 50, 2592000).SetVal(int64(1))
+		mock.ExpectEvalSha(addVocabulary.Hash(), []string{"suggest:vocabulary", "suggest:vocabulary:seen"}, 5000, "ab").SetVal(int64(1))
+
+		err := repo.Index(context.TODO(), books)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("nothing to index", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSuggestionRepository(db)
+
+		err := repo.Index(context.TODO(), []domain.Book{{ID: "1"}})
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestSuggest(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSuggestionRepository(db)
+
+		mock.ExpectZRevRange("suggest:prefix:ka", 0, 49).SetVal([]string{"author|Kafka", "title|Kafka on the Shore", "invalid"})
+
+		suggestions, err := repo.Suggest(context.TODO(), " Ka", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Suggestion{
+			{Text: "Kafka", Type: domain.SuggestionTypeAuthor},
+			{Text: "Kafka on the Shore", Type: domain.SuggestionTypeTitle},
+		}, suggestions)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSuggestionRepository(db)
+
+		mock.ExpectZRevRange("suggest:prefix:ka", 0, 49).SetVal([]string{"author|Kafka", "title|Kafka on the Shore"})
+
+		suggestions, err := repo.Suggest(context.TODO(), "ka", 1)
+		assert.NoError(t, err)
+		assert.Len(t, suggestions, 1)
+	})
+
+	t.Run("long prefix is filtered after lookup", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSuggestionRepository(db)
+
+		mock.ExpectZRevRange("suggest:prefix:the metamorphosis an", 0, 49).SetVal([]string{
+			"title|The Metamorphosis and Other Stories",
+			"title|The Metamorphosis Annotated",
+		})
+
+		suggestions, err := repo.Suggest(context.TODO(), "the metamorphosis and", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Suggestion{{Text: "The Metamorphosis and Other Stories", Type: domain.SuggestionTypeTitle}}, suggestions)
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSuggestionRepository(db)
+
+		errorMsg := "redis error"
+		mock.ExpectZRevRange("suggest:prefix:ka", 0, 49).SetErr(errors.New(errorMsg))
+
+		_, err := repo.Suggest(context.TODO(), "ka", 10)
+		assert.ErrorContains(t, err, errorMsg)
+	})
+}
+
+func TestVocabulary(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSuggestionRepository(db)
+
+		mock.ExpectZRangeWithScores("suggest:vocabulary", 0, -1).SetVal([]redis.Z{
+			{Member: "کافکا", Score: 3},
+			{Member: "مسخ", Score: 1},
+		})
+
+		vocabulary, err := repo.Vocabulary(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]float64{"کافکا": 3, "مسخ": 1}, vocabulary)
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewSuggestionRepository(db)
+
+		errorMsg := "redis error"
+		mock.ExpectZRangeWithScores("suggest:vocabulary", 0, -1).SetErr(errors.New(errorMsg))
+
+		_, err := repo.Vocabulary(context.TODO())
+		assert.ErrorContains(t, err, errorMsg)
+	})
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const usersKey = "users"
+
+type UserRepository interface {
+	Register(ctx context.Context, username string) (bool, error)
+}
+
+type redisUserRepository struct {
+	redisClient *redis.Client
+}
+
+// Register remembers a username and reports whether it had not been seen before.
+func (r *redisUserRepository) Register(ctx context.Context, username string) (bool, error) {
+	added, err := r.redisClient.SAdd(ctx, usersKey, username).Result()
+	if err != nil {
+		return false, err
+	}
+	return added > 0, nil
+}
+
+func NewUserRepository(redisClient *redis.Client) UserRepository {
+	return &redisUserRepository{
+		redisClient: redisClient,
+	}
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRegister(t *testing.T) {
+	t.Run("new user", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewUserRepository(db)
+
+		mock.ExpectSAdd("users", "test").SetVal(1)
+
+		created, err := repo.Register(context.TODO(), "test")
+		assert.NoError(t, err)
+		assert.True(t, created)
+	})
+
+	t.Run("known user", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewUserRepository(db)
+
+		mock.ExpectSAdd("users", "test").SetVal(0)
+
+		created, err := repo.Register(context.TODO(), "test")
+		assert.NoError(t, err)
+		assert.False(t, created)
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewUserRepository(db)
+
+		errorMsg := "redis error"
+		mock.ExpectSAdd("users", "test").SetErr(errors.New(errorMsg))
+
+		_, err := repo.Register(context.TODO(), "test")
+		assert.ErrorContains(t, err, errorMsg)
+	})
+}
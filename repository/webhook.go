@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	webhooksKey           = "webhooks"
+	webhooksUserKeyPrefix = "webhooks:user:"
+)
+
+// createWebhookScript stores a webhook and adds it to the index of its user, unless the user already has as
+// many as allowed.
+const createWebhookScript = `
+if redis.call('SCARD', KEYS[2]) >= tonumber(ARGV[3]) then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('SADD', KEYS[2], ARGV[1])
+return 1
+`
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook domain.Webhook) error
+	Get(ctx context.Context, id string) (domain.Webhook, error)
+	List(ctx context.Context, username string) ([]domain.Webhook, error)
+	Update(ctx context.Context, webhook domain.Webhook) error
+	Delete(ctx context.Context, username string, id string) error
+}
+
+type redisWebhookRepository struct {
+	redisClient *redis.Client
+	limit       int
+}
+
+// Create stores a webhook. The system webhook belongs to no user, so it is stored, or replaced, without being
+// indexed or counted.
+func (r *redisWebhookRepository) Create(ctx context.Context, webhook domain.Webhook) error {
+	data, err := json.Marshal(webhook)
+	if err != nil {
+		return err
+	}
+
+	if webhook.CreatedBy == "" {
+		return r.redisClient.HSet(ctx, webhooksKey, webhook.ID, data).Err()
+	}
+
+	created, err := r.redisClient.Eval(ctx, createWebhookScript,
+		[]string{webhooksKey, webhooksUserKeyPrefix + webhook.CreatedBy},
+		webhook.ID, data, r.limit).Int()
+	if err != nil {
+		return err
+	}
+	if created == 0 {
+		return domain.ErrWebhooksLimitReached
+	}
+	return nil
+}
+
+func (r *redisWebhookRepository) Get(ctx context.Context, id string) (domain.Webhook, error) {
+	val, err := r.redisClient.HGet(ctx, webhooksKey, id).Result()
+	if errors.Is(err, redis.Nil) {
+		return domain.Webhook{}, domain.ErrWebhookNotFound
+	}
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	webhook := domain.Webhook{}
+	err = json.Unmarshal([]byte(val), &webhook)
+	return webhook, err
+}
+
+// List returns the webhooks of username, oldest first.
+func (r *redisWebhookRepository) List(ctx context.Context, username string) ([]domain.Webhook, error) {
+	ids, err := r.redisClient.SMembers(ctx, webhooksUserKeyPrefix+username).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []domain.Webhook{}, nil
+	}
+
+	values, err := r.redisClient.HMGet(ctx, webhooksKey, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]domain.Webhook, 0, len(values))
+	for _, val := range values {
+		data, ok := val.(string)
+		if !ok {
+			continue
+		}
+
+		webhook := domain.Webhook{}
+		err := json.Unmarshal([]byte(data), &webhook)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+
+	return webhooks, nil
+}
+
+func (r *redisWebhookRepository) Update(ctx context.Context, webhook domain.Webhook) error {
+	data, err := json.Marshal(webhook)
+	if err != nil {
+		return err
+	}
+
+	exists, err := r.redisClient.HExists(ctx, webhooksKey, webhook.ID).Result()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrWebhookNotFound
+	}
+
+	return r.redisClient.HSet(ctx, webhooksKey, webhook.ID, data).Err()
+}
+
+func (r *redisWebhookRepository) Delete(ctx context.Context, username string, id string) error {
+	pipe := r.redisClient.TxPipeline()
+	removed := pipe.HDel(ctx, webhooksKey, id)
+	pipe.SRem(ctx, webhooksUserKeyPrefix+username, id)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	if removed.Val() == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func NewWebhookRepository(redisClient *redis.Client, limit int) WebhookRepository {
+	return &redisWebhookRepository{
+		redisClient: redisClient,
+		limit:       limit,
+	}
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	webhookDeliveryKeyPrefix    = "webhooks:delivery:"
+	webhookDeliveryLogKeyPrefix = "webhooks:deliveries:"
+	webhookQueueKey             = "webhooks:queue"
+	webhookDeadLettersKey       = "webhooks:dead-letters"
+)
+
+// claimWebhookDeliveriesScript takes the deliveries that are due and pushes their next attempt back by the
+// lease, so that other instances do not send them too and they are picked up again if this one dies before
+// it records the outcome.
+const claimWebhookDeliveriesScript = `
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+local deliveries = {}
+for _, id in ipairs(ids) do
+	local data = redis.call('GET', ARGV[4] .. id)
+	if data then
+		redis.call('ZADD', KEYS[1], ARGV[3], id)
+		table.insert(deliveries, data)
+	else
+		redis.call('ZREM', KEYS[1], id)
+	end
+end
+return deliveries
+`
+
+// WebhookDeliveryRepository is the delivery queue of the webhooks. Each delivery is stored on its own for the
+// retention period; the queue holds the IDs of the pending ones ordered by their next attempt.
+type WebhookDeliveryRepository interface {
+	Enqueue(ctx context.Context, deliveries ...domain.WebhookDelivery) error
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error)
+	Get(ctx context.Context, id string) (domain.WebhookDelivery, error)
+	Complete(ctx context.Context, delivery domain.WebhookDelivery) error
+	Retry(ctx context.Context, delivery domain.WebhookDelivery) error
+	DeadLetter(ctx context.Context, delivery domain.WebhookDelivery) error
+	Log(ctx context.Context, webhookID string) ([]domain.WebhookDelivery, error)
+}
+
+type redisWebhookDeliveryRepository struct {
+	redisClient *redis.Client
+	logSize     int
+	retention   time.Duration
+}
+
+func (r *redisWebhookDeliveryRepository) Enqueue(ctx context.Context, deliveries ...domain.WebhookDelivery) error {
+	pipe := r.redisClient.TxPipeline()
+	for _, delivery := range deliveries {
+		data, err := json.Marshal(delivery)
+		if err != nil {
+			return err
+		}
+
+		logKey := webhookDeliveryLogKeyPrefix + delivery.WebhookID
+
+		pipe.Set(ctx, webhookDeliveryKeyPrefix+delivery.ID, data, r.retention)
+		pipe.ZAdd(ctx, webhookQueueKey, redis.Z{Score: float64(delivery.NextAttemptAt.UnixMilli()), Member: delivery.ID})
+		pipe.LPush(ctx, logKey, delivery.ID)
+		pipe.LTrim(ctx, logKey, 0, int64(r.logSize-1))
+		pipe.Expire(ctx, logKey, r.retention)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *redisWebhookDeliveryRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	values, err := r.redisClient.Eval(ctx, claimWebhookDeliveriesScript, []string{webhookQueueKey},
+		now.UnixMilli(), limit, now.Add(lease).UnixMilli(), webhookDeliveryKeyPrefix).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]domain.WebhookDelivery, 0, len(values))
+	for _, val := range values {
+		delivery := domain.WebhookDelivery{}
+		err := json.Unmarshal([]byte(val), &delivery)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+func (r *redisWebhookDeliveryRepository) Get(ctx context.Context, id string) (domain.WebhookDelivery, error) {
+	val, err := r.redisClient.Get(ctx, webhookDeliveryKeyPrefix+id).Result()
+	if errors.Is(err, redis.Nil) {
+		return domain.WebhookDelivery{}, domain.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	delivery := domain.WebhookDelivery{}
+	err = json.Unmarshal([]byte(val), &delivery)
+	return delivery, err
+}
+
+// Complete records a delivery that will not be attempted again and takes it off the queue.
+func (r *redisWebhookDeliveryRepository) Complete(ctx context.Context, delivery domain.WebhookDelivery) error {
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.Set(ctx, webhookDeliveryKeyPrefix+delivery.ID, data, r.retention)
+	pipe.ZRem(ctx, webhookQueueKey, delivery.ID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Retry records a delivery and schedules it for its next attempt, taking it out of the dead letters if it
+// was there.
+func (r *redisWebhookDeliveryRepository) Retry(ctx context.Context, delivery domain.WebhookDelivery) error {
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.Set(ctx, webhookDeliveryKeyPrefix+delivery.ID, data, r.retention)
+	pipe.ZAdd(ctx, webhookQueueKey, redis.Z{Score: float64(delivery.NextAttemptAt.UnixMilli()), Member: delivery.ID})
+	pipe.LRem(ctx, webhookDeadLettersKey, 0, delivery.ID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// DeadLetter records a delivery that has failed for good, takes it off the queue and adds it to the dead
+// letters.
+func (r *redisWebhookDeliveryRepository) DeadLetter(ctx context.Context, delivery domain.WebhookDelivery) error {
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.Set(ctx, webhookDeliveryKeyPrefix+delivery.ID, data, r.retention)
+	pipe.ZRem(ctx, webhookQueueKey, delivery.ID)
+	pipe.LPush(ctx, webhookDeadLettersKey, delivery.ID)
+	pipe.LTrim(ctx, webhookDeadLettersKey, 0, int64(r.logSize-1))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Log returns the latest deliveries of a webhook, newest first.
+func (r *redisWebhookDeliveryRepository) Log(ctx context.Context, webhookID string) ([]domain.WebhookDelivery, error) {
+	ids, err := r.redisClient.LRange(ctx, webhookDeliveryLogKeyPrefix+webhookID, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []domain.WebhookDelivery{}, nil
+	}
+
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, webhookDeliveryKeyPrefix+id)
+	}
+
+	values, err := r.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]domain.WebhookDelivery, 0, len(values))
+	for _, val := range values {
+		data, ok := val.(string)
+		if !ok {
+			continue
+		}
+
+		delivery := domain.WebhookDelivery{}
+		err := json.Unmarshal([]byte(data), &delivery)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+func NewWebhookDeliveryRepository(redisClient *redis.Client, logSize int, retention time.Duration) WebhookDeliveryRepository {
+	return &redisWebhookDeliveryRepository{
+		redisClient: redisClient,
+		logSize:     logSize,
+		retention:   retention,
+	}
+}
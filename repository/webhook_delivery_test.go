@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookDeliveryEnqueue(t *testing.T) {
+	delivery := domain.WebhookDelivery{
+		ID:            "d1",
+		WebhookID:     "abc",
+		Status:        domain.DeliveryStatusPending,
+		NextAttemptAt: time.UnixMilli(1672531200000).UTC(),
+	}
+	data, err := json.Marshal(delivery)
+	assert.NoError(t, err)
+
+	db, mock := redismock.NewClientMock()
+
+	repo := NewWebhookDeliveryRepository(db, 100, time.Hour)
+
+	mock.ExpectTxPipeline()
+	mock.ExpectSet("webhooks:delivery:d1", data, time.Hour).SetVal("OK")
+	mock.ExpectZAdd("webhooks:queue", redis.Z{Score: 1672531200000, Member: "d1"}).SetVal(1)
+	mock.ExpectLPush("webhooks:deliveries:abc", "d1").SetVal(1)
+	mock.ExpectLTrim("webhooks:deliveries:abc", 0, 99).SetVal("OK")
+	mock.ExpectExpire("webhooks:deliveries:abc", time.Hour).SetVal(true)
+	mock.ExpectTxPipelineExec()
+
+	err = repo.Enqueue(context.TODO(), delivery)
+	assert.NoError(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestWebhookDeliveryClaim(t *testing.T) {
+	now := time.UnixMilli(1672531200000)
+
+	t.Run("success", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookDeliveryRepository(db, 100, time.Hour)
+
+		mock.ExpectEval(claimWebhookDeliveriesScript, []string{"webhooks:queue"},
+			int64(1672531200000), 10, int64(1672531230000), "webhooks:delivery:").
+			SetVal([]interface{}{`{"id":"d1","webhook_id":"abc","attempts":1}`})
+
+		deliveries, err := repo.Claim(context.TODO(), now, 30*time.Second, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.WebhookDelivery{{ID: "d1", WebhookID: "abc", Attempts: 1}}, deliveries)
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookDeliveryRepository(db, 100, time.Hour)
+
+		errorMsg := "redis error"
+		mock.ExpectEval(claimWebhookDeliveriesScript, []string{"webhooks:queue"},
+			int64(1672531200000), 10, int64(1672531230000), "webhooks:delivery:").
+			SetErr(errors.New(errorMsg))
+
+		_, err := repo.Claim(context.TODO(), now, 30*time.Second, 10)
+		assert.ErrorContains(t, err, errorMsg)
+	})
+}
+
+func TestWebhookDeliveryGet(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+
+	repo := NewWebhookDeliveryRepository(db, 100, time.Hour)
+
+	mock.ExpectGet("webhooks:delivery:d1").RedisNil()
+
+	_, err := repo.Get(context.TODO(), "d1")
+	assert.ErrorIs(t, err, domain.ErrWebhookDeliveryNotFound)
+}
+
+func TestWebhookDeliveryOutcomes(t *testing.T) {
+	delivery := domain.WebhookDelivery{ID: "d1", WebhookID: "abc", NextAttemptAt: time.UnixMilli(1672531200000).UTC()}
+
+	t.Run("complete", func(t *testing.T) {
+		delivery := delivery
+		delivery.Status = domain.DeliveryStatusSucceeded
+		data, err := json.Marshal(delivery)
+		assert.NoError(t, err)
+
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookDeliveryRepository(db, 100, time.Hour)
+
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("webhooks:delivery:d1", data, time.Hour).SetVal("OK")
+		mock.ExpectZRem("webhooks:queue", "d1").SetVal(1)
+		mock.ExpectTxPipelineExec()
+
+		err = repo.Complete(context.TODO(), delivery)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		data, err := json.Marshal(delivery)
+		assert.NoError(t, err)
+
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookDeliveryRepository(db, 100, time.Hour)
+
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("webhooks:delivery:d1", data, time.Hour).SetVal("OK")
+		mock.ExpectZAdd("webhooks:queue", redis.Z{Score: 1672531200000, Member: "d1"}).SetVal(0)
+		mock.ExpectLRem("webhooks:dead-letters", 0, "d1").SetVal(0)
+		mock.ExpectTxPipelineExec()
+
+		err = repo.Retry(context.TODO(), delivery)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("dead letter", func(t *testing.T) {
+		delivery := delivery
+		delivery.Status = domain.DeliveryStatusDead
+		data, err := json.Marshal(delivery)
+		assert.NoError(t, err)
+
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookDeliveryRepository(db, 100, time.Hour)
+
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("webhooks:delivery:d1", data, time.Hour).SetVal("OK")
+		mock.ExpectZRem("webhooks:queue", "d1").SetVal(1)
+		mock.ExpectLPush("webhooks:dead-letters", "d1").SetVal(1)
+		mock.ExpectLTrim("webhooks:dead-letters", 0, 99).SetVal("OK")
+		mock.ExpectTxPipelineExec()
+
+		err = repo.DeadLetter(context.TODO(), delivery)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestWebhookDeliveryLog(t *testing.T) {
+	t.Run("skips expired deliveries", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookDeliveryRepository(db, 100, time.Hour)
+
+		mock.ExpectLRange("webhooks:deliveries:abc", 0, -1).SetVal([]string{"d2", "d1"})
+		mock.ExpectMGet("webhooks:delivery:d2", "webhooks:delivery:d1").SetVal([]interface{}{`{"id":"d2"}`, nil})
+
+		deliveries, err := repo.Log(context.TODO(), "abc")
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.WebhookDelivery{{ID: "d2"}}, deliveries)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookDeliveryRepository(db, 100, time.Hour)
+
+		mock.ExpectLRange("webhooks:deliveries:abc", 0, -1).SetVal([]string{})
+
+		deliveries, err := repo.Log(context.TODO(), "abc")
+		assert.NoError(t, err)
+		assert.Empty(t, deliveries)
+		assert.NotNil(t, deliveries)
+	})
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookCreate(t *testing.T) {
+	webhook := domain.Webhook{ID: "abc", URL: "https://example.com/hook", Events: []string{domain.EventSavedSearchNewResults}, Active: true, CreatedBy: "test"}
+	data, err := json.Marshal(webhook)
+	assert.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookRepository(db, 20)
+
+		mock.ExpectEval(createWebhookScript, []string{"webhooks", "webhooks:user:test"}, "abc", data, 20).SetVal(int64(1))
+
+		err := repo.Create(context.TODO(), webhook)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("limit reached", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookRepository(db, 20)
+
+		mock.ExpectEval(createWebhookScript, []string{"webhooks", "webhooks:user:test"}, "abc", data, 20).SetVal(int64(0))
+
+		err := repo.Create(context.TODO(), webhook)
+		assert.ErrorIs(t, err, domain.ErrWebhooksLimitReached)
+	})
+
+	t.Run("system webhook", func(t *testing.T) {
+		system := domain.Webhook{ID: domain.SystemWebhookID, URL: "https://example.com/hook", Active: true}
+		data, err := json.Marshal(system)
+		assert.NoError(t, err)
+
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookRepository(db, 20)
+
+		mock.ExpectHSet("webhooks", domain.SystemWebhookID, data).SetVal(1)
+
+		err = repo.Create(context.TODO(), system)
+		assert.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestWebhookGet(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookRepository(db, 20)
+
+		mock.ExpectHGet("webhooks", "abc").SetVal(`{"id":"abc","url":"https://example.com/hook","active":true}`)
+
+		webhook, err := repo.Get(context.TODO(), "abc")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.Webhook{ID: "abc", URL: "https://example.com/hook", Active: true}, webhook)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookRepository(db, 20)
+
+		mock.ExpectHGet("webhooks", "abc").RedisNil()
+
+		_, err := repo.Get(context.TODO(), "abc")
+		assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+	})
+}
+
+func TestWebhookList(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookRepository(db, 20)
+
+		mock.ExpectSMembers("webhooks:user:test").SetVal([]string{"new", "old", "removed"})
+		mock.ExpectHMGet("webhooks", "new", "old", "removed").SetVal([]interface{}{
+			`{"id":"new","created_at":"2023-01-02T00:00:00Z"}`,
+			`{"id":"old","created_at":"2023-01-01T00:00:00Z"}`,
+			nil,
+		})
+
+		webhooks, err := repo.List(context.TODO(), "test")
+		assert.NoError(t, err)
+		assert.Len(t, webhooks, 2)
+		assert.Equal(t, "old", webhooks[0].ID)
+		assert.Equal(t, "new", webhooks[1].ID)
+	})
+
+	t.Run("no webhooks", func(t *testing.T) {
+		db, mock := redismock.NewClientMock()
+
+		repo := NewWebhookRepository(db, 20)
+
+		mock.ExpectSMembers("webhooks:user:test").SetVal([]string{})
+
+		webhooks, err := repo.List(context.TODO(), "test")
+		assert.NoError(t, err)
+		assert.Empty(t, webhooks)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestWebhookUpdate(t *testing.T) {
+	webhook := domain.Webhook{ID: "abc", CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	db, mock := redismock.NewClientMock()
+
+	repo := NewWebhookRepository(db, 20)
+
+	mock.ExpectHExists("webhooks", "abc").SetVal(false)
+
+	err := repo.Update(context.TODO(), webhook)
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+}
+
+func TestWebhookDelete(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+
+	repo := NewWebhookRepository(db, 20)
+
+	mock.ExpectTxPipeline()
+	mock.ExpectHDel("webhooks", "abc").SetVal(0)
+	mock.ExpectSRem("webhooks:user:test", "abc").SetVal(0)
+	mock.ExpectTxPipelineExec()
+
+	err := repo.Delete(context.TODO(), "test", "abc")
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+}
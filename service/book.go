@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/kavehjamshidi/fidibo-challenge/cache"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch"
+)
+
+type BookService interface {
+	Get(ctx context.Context, lookup domain.BookLookup) (domain.Book, error)
+}
+
+type bookService struct {
+	books        cache.BookCacher
+	fidiboSearch fidibosearch.FidiboSearcher
+	logger       *slog.Logger
+}
+
+func (s *bookService) Get(ctx context.Context, lookup domain.BookLookup) (domain.Book, error) {
+	book, err := s.books.Get(ctx, lookup)
+	if err == nil {
+		return book, nil
+	} else {
+		s.logger.DebugContext(ctx, "book cache miss", "error", err)
+	}
+
+	book, err = s.fidiboSearch.FindBook(ctx, lookup)
+	if err != nil {
+		if errors.Is(err, domain.ErrBookNotFound) {
+			return domain.Book{}, err
+		}
+		s.logger.ErrorContext(ctx, "fidibo book lookup failed", "error", err)
+		return domain.Book{}, fmt.Errorf("service unavailable: %w", err)
+	}
+	book.Score = 0
+	book.Highlight = nil
+
+	err = s.books.Store(ctx, []domain.Book{book})
+	if err != nil {
+		s.logger.WarnContext(ctx, "could not store book in cache", "error", err)
+	}
+
+	return book, nil
+}
+
+func NewBookService(books cache.BookCacher, fidiboSearch fidibosearch.FidiboSearcher, logger *slog.Logger) BookService {
+	return &bookService{
+		books:        books,
+		fidiboSearch: fidiboSearch,
+		logger:       logger.With("component", "book"),
+	}
+}
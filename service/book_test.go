@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	cacheMock "github.com/kavehjamshidi/fidibo-challenge/cache/mocks"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	fidiboMock "github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch/mocks"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBook(t *testing.T) {
+	t.Run("successful cache hit", func(t *testing.T) {
+		lookup := domain.BookLookup{ID: "123"}
+		expectedBook := domain.Book{ID: "123", Title: "test title"}
+
+		books := &cacheMock.BookCacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+
+		books.On("Get", context.TODO(), lookup).Return(expectedBook, nil)
+
+		svc := NewBookService(books, fidiboClient, slog.Default())
+		book, err := svc.Get(context.TODO(), lookup)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedBook, book)
+		books.AssertExpectations(t)
+		fidiboClient.AssertExpectations(t)
+	})
+
+	t.Run("cache miss, found upstream and stored on cache", func(t *testing.T) {
+		lookup := domain.BookLookup{Slug: "test"}
+		expectedBook := domain.Book{ID: "123", Title: "test title", Slug: "test"}
+
+		books := &cacheMock.BookCacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+
+		upstreamBook := expectedBook
+		upstreamBook.Score = 2.5
+
+		books.On("Get", context.TODO(), lookup).Return(domain.Book{}, redis.Nil)
+		fidiboClient.On("FindBook", context.TODO(), lookup).Return(upstreamBook, nil)
+		books.On("Store", context.TODO(), []domain.Book{expectedBook}).Return(nil)
+
+		svc := NewBookService(books, fidiboClient, slog.Default())
+		book, err := svc.Get(context.TODO(), lookup)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedBook, book)
+		books.AssertExpectations(t)
+		fidiboClient.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		lookup := domain.BookLookup{ID: "123"}
+
+		books := &cacheMock.BookCacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+
+		books.On("Get", context.TODO(), lookup).Return(domain.Book{}, redis.Nil)
+		fidiboClient.On("FindBook", context.TODO(), lookup).Return(domain.Book{}, domain.ErrBookNotFound)
+
+		svc := NewBookService(books, fidiboClient, slog.Default())
+		_, err := svc.Get(context.TODO(), lookup)
+
+		assert.ErrorIs(t, err, domain.ErrBookNotFound)
+		books.AssertExpectations(t)
+		fidiboClient.AssertExpectations(t)
+	})
+
+	t.Run("upstream error", func(t *testing.T) {
+		lookup := domain.BookLookup{ID: "123"}
+		errorMsg := "internal server error"
+
+		books := &cacheMock.BookCacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+
+		books.On("Get", context.TODO(), lookup).Return(domain.Book{}, redis.Nil)
+		fidiboClient.On("FindBook", context.TODO(), lookup).Return(domain.Book{}, errors.New(errorMsg))
+
+		svc := NewBookService(books, fidiboClient, slog.Default())
+		_, err := svc.Get(context.TODO(), lookup)
+
+		assert.ErrorContains(t, err, errorMsg)
+		assert.ErrorContains(t, err, "service unavailable")
+		books.AssertExpectations(t)
+		fidiboClient.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/cache"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/normalize"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch"
+)
+
+const (
+	catalogCacheKeyPrefix = "catalog:"
+	authorCatalog         = "author"
+	publisherCatalog      = "publisher"
+
+	maxCatalogPages = 5
+	catalogPageSize = 50
+)
+
+type CatalogService interface {
+	AuthorBooks(ctx context.Context, req domain.CatalogRequest) (domain.SearchResult, error)
+	PublisherBooks(ctx context.Context, req domain.CatalogRequest) (domain.SearchResult, error)
+}
+
+type catalogService struct {
+	cache        cache.Cacher
+	fidiboSearch fidibosearch.FidiboSearcher
+	books        cache.BookCacher
+	logger       *slog.Logger
+}
+
+func (s *catalogService) AuthorBooks(ctx context.Context, req domain.CatalogRequest) (domain.SearchResult, error) {
+	return s.browse(ctx, authorCatalog, req, func(book domain.Book) bool {
+		for _, author := range book.Authors {
+			if sameName(author.Name, req.Name) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (s *catalogService) PublisherBooks(ctx context.Context, req domain.CatalogRequest) (domain.SearchResult, error) {
+	return s.browse(ctx, publisherCatalog, req, func(book domain.Book) bool {
+		return sameName(book.Publishers.Title, req.Name)
+	})
+}
+
+// browse collects every book of an author or publisher once and serves the pages of the collected list, so
+// pages stay stable while the upstream relevance order shifts between requests.
+func (s *catalogService) browse(ctx context.Context, catalog string, req domain.CatalogRequest, matches func(domain.Book) bool) (domain.SearchResult, error) {
+	key := catalogCacheKeyPrefix + catalog + ":" + strings.ToLower(normalize.Query(req.Name))
+
+	all, err := s.cache.Get(ctx, key)
+	if err != nil {
+		s.logger.DebugContext(ctx, "catalog cache miss", "catalog", catalog, "error", err)
+
+		all, err = s.aggregate(ctx, req.Name, matches)
+		if err != nil {
+			return domain.SearchResult{}, err
+		}
+
+		err = s.books.Store(ctx, all.Books)
+		if err != nil {
+			s.logger.WarnContext(ctx, "could not store books in cache", "error", err)
+		}
+
+		err = s.cache.Store(ctx, key, all)
+		if err != nil {
+			s.logger.WarnContext(ctx, "could not store catalog in cache", "catalog", catalog, "error", err)
+		}
+	}
+
+	return paginate(all, req.Page, req.Size), nil
+}
+
+func (s *catalogService) aggregate(ctx context.Context, name string, matches func(domain.Book) bool) (domain.SearchResult, error) {
+	books := []domain.Book{}
+	seen := map[string]bool{}
+
+	for page := 1; page <= maxCatalogPages; page++ {
+		res, err := s.fidiboSearch.Search(ctx, domain.SearchRequest{Keyword: name, Page: page, Size: catalogPageSize})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "fidibo search failed", "page", page, "error", err)
+			return domain.SearchResult{}, fmt.Errorf("service unavailable: %w", err)
+		}
+
+		for _, book := range res.Books {
+			if book.ID == "" || seen[book.ID] || !matches(book) {
+				continue
+			}
+			seen[book.ID] = true
+			book.Score = 0
+			book.Highlight = nil
+			books = append(books, book)
+		}
+
+		if !res.HasMore {
+			break
+		}
+	}
+
+	return domain.SearchResult{
+		Books:     books,
+		Total:     int64(len(books)),
+		FetchedAt: time.Now().UTC(),
+	}, nil
+}
+
+func paginate(all domain.SearchResult, page, size int) domain.SearchResult {
+	// The page is compared before it is multiplied, so a huge page number cannot overflow the offset.
+	start := len(all.Books)
+	if page-1 <= len(all.Books)/size {
+		start = (page - 1) * size
+	}
+	if start > len(all.Books) {
+		start = len(all.Books)
+	}
+	end := start + size
+	if end > len(all.Books) {
+		end = len(all.Books)
+	}
+
+	return domain.SearchResult{
+		Books:     append([]domain.Book{}, all.Books[start:end]...),
+		Total:     int64(len(all.Books)),
+		Page:      page,
+		Size:      size,
+		HasMore:   end < len(all.Books),
+		FetchedAt: all.FetchedAt,
+	}
+}
+
+func sameName(a, b string) bool {
+	return strings.EqualFold(normalize.Query(a), normalize.Query(b))
+}
+
+func NewCatalogService(cache cache.Cacher,
+	fidiboSearch fidibosearch.FidiboSearcher,
+	books cache.BookCacher,
+	logger *slog.Logger) CatalogService {
+	return &catalogService{
+		cache:        cache,
+		fidiboSearch: fidiboSearch,
+		books:        books,
+		logger:       logger.With("component", "catalog"),
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	cacheMock "github.com/kavehjamshidi/fidibo-challenge/cache/mocks"
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	fidiboMock "github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch/mocks"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthorBooks(t *testing.T) {
+	t.Run("aggregates pages, deduplicates and paginates", func(t *testing.T) {
+		req := domain.CatalogRequest{Name: "Franz Kafka", Page: 2, Size: 1}
+		key := "catalog:author:franz kafka"
+
+		cache := &cacheMock.Cacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		books := &cacheMock.BookCacher{}
+
+		kafka := []domain.Author{{Name: "franz kafka"}}
+		trial := domain.Book{ID: "1", Title: "The Trial", Authors: kafka}
+		castle := domain.Book{ID: "2", Title: "The Castle", Authors: kafka}
+		scored := castle
+		scored.Score = 3
+
+		fidiboClient.On("Search", context.TODO(), domain.SearchRequest{Keyword: "Franz Kafka", Page: 1, Size: 50}).Return(domain.SearchResult{
+			Books: []domain.Book{
+				trial,
+				{ID: "3", Title: "Kafka on the Shore", Authors: []domain.Author{{Name: "Haruki Murakami"}}},
+			},
+			HasMore: true,
+		}, nil)
+		fidiboClient.On("Search", context.TODO(), domain.SearchRequest{Keyword: "Franz Kafka", Page: 2, Size: 50}).Return(domain.SearchResult{
+			Books: []domain.Book{trial, scored},
+		}, nil)
+
+		cache.On("Get", context.TODO(), key).Return(domain.SearchResult{}, redis.Nil)
+		books.On("Store", context.TODO(), []domain.Book{trial, castle}).Return(nil)
+		cache.On("Store", context.TODO(), key, mock.MatchedBy(func(res domain.SearchResult) bool {
+			return assert.ObjectsAreEqual([]domain.Book{trial, castle}, res.Books) && res.Total == 2
+		})).Return(nil)
+
+		svc := NewCatalogService(cache, fidiboClient, books, slog.Default())
+		res, err := svc.AuthorBooks(context.TODO(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Book{castle}, res.Books)
+		assert.Equal(t, int64(2), res.Total)
+		assert.Equal(t, 2, res.Page)
+		assert.Equal(t, 1, res.Size)
+		assert.False(t, res.HasMore)
+		cache.AssertExpectations(t)
+		fidiboClient.AssertExpectations(t)
+		books.AssertExpectations(t)
+	})
+
+	t.Run("served from cache", func(t *testing.T) {
+		req := domain.CatalogRequest{Name: "Franz Kafka", Page: 1, Size: 1}
+
+		cache := &cacheMock.Cacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		books := &cacheMock.BookCacher{}
+
+		fetchedAt := time.Now().UTC()
+		cache.On("Get", context.TODO(), "catalog:author:franz kafka").Return(domain.SearchResult{
+			Books:     []domain.Book{{ID: "1"}, {ID: "2"}},
+			Total:     2,
+			FetchedAt: fetchedAt,
+		}, nil)
+
+		svc := NewCatalogService(cache, fidiboClient, books, slog.Default())
+		res, err := svc.AuthorBooks(context.TODO(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.SearchResult{
+			Books:     []domain.Book{{ID: "1"}},
+			Total:     2,
+			Page:      1,
+			Size:      1,
+			HasMore:   true,
+			FetchedAt: fetchedAt,
+		}, res)
+		cache.AssertExpectations(t)
+		fidiboClient.AssertExpectations(t)
+	})
+
+	t.Run("page out of range", func(t *testing.T) {
+		res := paginate(domain.SearchResult{Books: []domain.Book{{ID: "1"}}}, 3, 20)
+
+		assert.Equal(t, []domain.Book{}, res.Books)
+		assert.Equal(t, int64(1), res.Total)
+		assert.False(t, res.HasMore)
+
+		res = paginate(domain.SearchResult{Books: []domain.Book{{ID: "1"}}}, 4611686018427387905, 50)
+
+		assert.Equal(t, []domain.Book{}, res.Books)
+		assert.False(t, res.HasMore)
+	})
+
+	t.Run("upstream error", func(t *testing.T) {
+		req := domain.CatalogRequest{Name: "Franz Kafka", Page: 1, Size: 20}
+		errorMsg := "internal server error"
+
+		cache := &cacheMock.Cacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		books := &cacheMock.BookCacher{}
+
+		cache.On("Get", context.TODO(), "catalog:author:franz kafka").Return(domain.SearchResult{}, redis.Nil)
+		fidiboClient.On("Search", context.TODO(), domain.SearchRequest{Keyword: "Franz Kafka", Page: 1, Size: 50}).Return(domain.SearchResult{}, errors.New(errorMsg))
+
+		svc := NewCatalogService(cache, fidiboClient, books, slog.Default())
+		_, err := svc.AuthorBooks(context.TODO(), req)
+
+		assert.ErrorContains(t, err, errorMsg)
+		cache.AssertExpectations(t)
+		fidiboClient.AssertExpectations(t)
+		books.AssertExpectations(t)
+	})
+}
+
+func TestPublisherBooks(t *testing.T) {
+	t.Run("matches publisher title", func(t *testing.T) {
+		req := domain.CatalogRequest{Name: "نشر  چشمه", Page: 1, Size: 20}
+		key := "catalog:publisher:نشر چشمه"
+
+		cache := &cacheMock.Cacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		books := &cacheMock.BookCacher{}
+
+		cheshmeh := domain.Book{ID: "1", Publishers: domain.Publisher{Title: "نشر چشمه"}}
+
+		cache.On("Get", context.TODO(), key).Return(domain.SearchResult{}, redis.Nil)
+		fidiboClient.On("Search", context.TODO(), domain.SearchRequest{Keyword: req.Name, Page: 1, Size: 50}).Return(domain.SearchResult{
+			Books: []domain.Book{cheshmeh, {ID: "2", Publishers: domain.Publisher{Title: "نشر ثالث"}}},
+		}, nil)
+		books.On("Store", context.TODO(), []domain.Book{cheshmeh}).Return(nil)
+		cache.On("Store", context.TODO(), key, mock.Anything).Return(errors.New("redis error"))
+
+		svc := NewCatalogService(cache, fidiboClient, books, slog.Default())
+		res, err := svc.PublisherBooks(context.TODO(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Book{cheshmeh}, res.Books)
+		assert.Equal(t, int64(1), res.Total)
+		cache.AssertExpectations(t)
+		fidiboClient.AssertExpectations(t)
+		books.AssertExpectations(t)
+	})
+}
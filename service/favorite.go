@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/repository"
+)
+
+type FavoriteService interface {
+	Add(ctx context.Context, username string, bookID string) (domain.Favorite, error)
+	Remove(ctx context.Context, username string, bookID string) error
+	List(ctx context.Context, username string) (domain.FavoritesResponse, error)
+}
+
+type favoriteService struct {
+	repo   repository.FavoriteRepository
+	books  BookService
+	logger *slog.Logger
+}
+
+// Add stores a snapshot of the book as it is when it is added, so the list still shows it if the book later
+// disappears from Fidibo.
+func (f *favoriteService) Add(ctx context.Context, username string, bookID string) (domain.Favorite, error) {
+	book, err := f.books.Get(ctx, domain.BookLookup{ID: bookID})
+	if err != nil {
+		return domain.Favorite{}, err
+	}
+
+	favorite := domain.Favorite{
+		Book:    book,
+		AddedAt: time.Now().UTC(),
+	}
+
+	err = f.repo.Add(ctx, username, favorite)
+	if errors.Is(err, domain.ErrFavoriteExists) || errors.Is(err, domain.ErrFavoritesLimitReached) {
+		return domain.Favorite{}, err
+	}
+	if err != nil {
+		f.logger.ErrorContext(ctx, "could not add favorite", "error", err)
+		return domain.Favorite{}, err
+	}
+
+	return favorite, nil
+}
+
+func (f *favoriteService) Remove(ctx context.Context, username string, bookID string) error {
+	err := f.repo.Remove(ctx, username, bookID)
+	if err != nil && !errors.Is(err, domain.ErrFavoriteNotFound) {
+		f.logger.ErrorContext(ctx, "could not remove favorite", "error", err)
+	}
+	return err
+}
+
+func (f *favoriteService) List(ctx context.Context, username string) (domain.FavoritesResponse, error) {
+	favorites, err := f.repo.List(ctx, username)
+	if err != nil {
+		f.logger.ErrorContext(ctx, "could not list favorites", "error", err)
+		return domain.FavoritesResponse{}, err
+	}
+
+	return domain.FavoritesResponse{Favorites: favorites}, nil
+}
+
+func NewFavoriteService(repo repository.FavoriteRepository, books BookService, logger *slog.Logger) FavoriteService {
+	return &favoriteService{
+		repo:   repo,
+		books:  books,
+		logger: logger.With("component", "favorite"),
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	repositoryMock "github.com/kavehjamshidi/fidibo-challenge/repository/mocks"
+	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddFavorite(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		book := domain.Book{ID: "123", Title: "test title"}
+
+		repo := &repositoryMock.FavoriteRepository{}
+		books := &mocks.BookService{}
+
+		books.On("Get", context.TODO(), domain.BookLookup{ID: "123"}).Return(book, nil)
+		repo.On("Add", context.TODO(), "test", mock.MatchedBy(func(favorite domain.Favorite) bool {
+			return favorite.Book.ID == "123" && time.Since(favorite.AddedAt) < time.Minute
+		})).Return(nil)
+
+		svc := NewFavoriteService(repo, books, slog.Default())
+		favorite, err := svc.Add(context.TODO(), "test", "123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, book, favorite.Book)
+		repo.AssertExpectations(t)
+		books.AssertExpectations(t)
+	})
+
+	t.Run("book not found", func(t *testing.T) {
+		repo := &repositoryMock.FavoriteRepository{}
+		books := &mocks.BookService{}
+
+		books.On("Get", context.TODO(), domain.BookLookup{ID: "123"}).Return(domain.Book{}, domain.ErrBookNotFound)
+
+		svc := NewFavoriteService(repo, books, slog.Default())
+		_, err := svc.Add(context.TODO(), "test", "123")
+
+		assert.ErrorIs(t, err, domain.ErrBookNotFound)
+		repo.AssertExpectations(t)
+		books.AssertExpectations(t)
+	})
+
+	t.Run("limit reached", func(t *testing.T) {
+		repo := &repositoryMock.FavoriteRepository{}
+		books := &mocks.BookService{}
+
+		books.On("Get", context.TODO(), domain.BookLookup{ID: "123"}).Return(domain.Book{ID: "123"}, nil)
+		repo.On("Add", context.TODO(), "test", mock.Anything).Return(domain.ErrFavoritesLimitReached)
+
+		logs := &bytes.Buffer{}
+		svc := NewFavoriteService(repo, books, slog.New(slog.NewTextHandler(logs, nil)))
+		_, err := svc.Add(context.TODO(), "test", "123")
+
+		assert.ErrorIs(t, err, domain.ErrFavoritesLimitReached)
+		assert.Empty(t, logs.String())
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestRemoveFavorite(t *testing.T) {
+	repo := &repositoryMock.FavoriteRepository{}
+	books := &mocks.BookService{}
+
+	repo.On("Remove", context.TODO(), "test", "123").Return(domain.ErrFavoriteNotFound)
+
+	svc := NewFavoriteService(repo, books, slog.Default())
+	err := svc.Remove(context.TODO(), "test", "123")
+
+	assert.ErrorIs(t, err, domain.ErrFavoriteNotFound)
+	repo.AssertExpectations(t)
+}
+
+func TestListFavorites(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		favorites := []domain.Favorite{{Book: domain.Book{ID: "123"}, AddedAt: time.Now().UTC()}}
+
+		repo := &repositoryMock.FavoriteRepository{}
+		books := &mocks.BookService{}
+
+		repo.On("List", context.TODO(), "test").Return(favorites, nil)
+
+		svc := NewFavoriteService(repo, books, slog.Default())
+		res, err := svc.List(context.TODO(), "test")
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.FavoritesResponse{Favorites: favorites}, res)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo := &repositoryMock.FavoriteRepository{}
+		books := &mocks.BookService{}
+
+		repo.On("List", context.TODO(), "test").Return(nil, errors.New("redis error"))
+
+		svc := NewFavoriteService(repo, books, slog.Default())
+		_, err := svc.List(context.TODO(), "test")
+
+		assert.ErrorContains(t, err, "redis error")
+		repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+)
+
+func filterBooks(req domain.SearchRequest, books []domain.Book) []domain.Book {
+	filtered := make([]domain.Book, 0, len(books))
+	for _, book := range books {
+		if matchesFilters(req, book) {
+			filtered = append(filtered, book)
+		}
+	}
+	return filtered
+}
+
+func matchesFilters(req domain.SearchRequest, book domain.Book) bool {
+	if req.Author != "" && !anyAuthorContains(book.Authors, req.Author) {
+		return false
+	}
+	if req.Publisher != "" && !containsFold(book.Publishers.Title, req.Publisher) {
+		return false
+	}
+	if req.Format != "" && !strings.EqualFold(book.Format, req.Format) {
+		return false
+	}
+	if req.Language != "" && !strings.EqualFold(book.Language, req.Language) {
+		return false
+	}
+	if req.MinPrice != nil && (book.Price == nil || *book.Price < *req.MinPrice) {
+		return false
+	}
+	if req.MaxPrice != nil && (book.Price == nil || *book.Price > *req.MaxPrice) {
+		return false
+	}
+	return true
+}
+
+func anyAuthorContains(authors []domain.Author, name string) bool {
+	for _, author := range authors {
+		if containsFold(author.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(strings.TrimSpace(substr)))
+}
+
+func sortBooks(sortBy string, books []domain.Book) {
+	switch sortBy {
+	case domain.SortTitle:
+		sort.SliceStable(books, func(i, j int) bool {
+			return books[i].Title < books[j].Title
+		})
+	case domain.SortDate:
+		sort.SliceStable(books, func(i, j int) bool {
+			return books[i].PublishDate > books[j].PublishDate
+		})
+	}
+}
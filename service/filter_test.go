@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterBooks(t *testing.T) {
+	price := func(p float64) *float64 { return &p }
+
+	books := []domain.Book{
+		{
+			ID:         "1",
+			Title:      "The Trial",
+			Publishers: domain.Publisher{Title: "Nashr-e Cheshmeh"},
+			Authors:    []domain.Author{{Name: "Franz Kafka"}},
+			Format:     domain.FormatEbook,
+			Language:   "fa",
+			Price:      price(50000),
+		},
+		{
+			ID:         "2",
+			Title:      "The Castle",
+			Publishers: domain.Publisher{Title: "Nashr-e Ofogh"},
+			Authors:    []domain.Author{{Name: "Franz Kafka"}},
+			Format:     domain.FormatAudiobook,
+			Language:   "fa",
+			Price:      price(120000),
+		},
+		{
+			ID:         "3",
+			Title:      "The Stranger",
+			Publishers: domain.Publisher{Title: "Nashr-e Cheshmeh"},
+			Authors:    []domain.Author{{Name: "Albert Camus"}},
+			Format:     domain.FormatEbook,
+			Language:   "en",
+		},
+	}
+
+	cases := []struct {
+		name     string
+		req      domain.SearchRequest
+		expected []string
+	}{
+		{name: "no filters", req: domain.SearchRequest{}, expected: []string{"1", "2", "3"}},
+		{name: "author", req: domain.SearchRequest{Author: "kafka"}, expected: []string{"1", "2"}},
+		{name: "publisher", req: domain.SearchRequest{Publisher: "Cheshmeh"}, expected: []string{"1", "3"}},
+		{name: "format", req: domain.SearchRequest{Format: domain.FormatAudiobook}, expected: []string{"2"}},
+		{name: "language", req: domain.SearchRequest{Language: "EN"}, expected: []string{"3"}},
+		{name: "min price excludes books without price", req: domain.SearchRequest{MinPrice: price(0)}, expected: []string{"1", "2"}},
+		{name: "price range", req: domain.SearchRequest{MinPrice: price(10000), MaxPrice: price(100000)}, expected: []string{"1"}},
+		{name: "combined", req: domain.SearchRequest{Author: "Kafka", Format: domain.FormatEbook}, expected: []string{"1"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ids []string
+			for _, book := range filterBooks(tc.req, books) {
+				ids = append(ids, book.ID)
+			}
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestSortBooks(t *testing.T) {
+	newBooks := func() []domain.Book {
+		return []domain.Book{
+			{ID: "1", Title: "B", PublishDate: "2019-05-01"},
+			{ID: "2", Title: "C", PublishDate: "2021-01-01"},
+			{ID: "3", Title: "A", PublishDate: "2020-03-01"},
+		}
+	}
+	ids := func(books []domain.Book) []string {
+		var ids []string
+		for _, book := range books {
+			ids = append(ids, book.ID)
+		}
+		return ids
+	}
+
+	books := newBooks()
+	sortBooks(domain.SortRelevance, books)
+	assert.Equal(t, []string{"1", "2", "3"}, ids(books))
+
+	books = newBooks()
+	sortBooks(domain.SortTitle, books)
+	assert.Equal(t, []string{"3", "1", "2"}, ids(books))
+
+	books = newBooks()
+	sortBooks(domain.SortDate, books)
+	assert.Equal(t, []string{"2", "3", "1"}, ids(books))
+}
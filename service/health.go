@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+)
+
+// HealthCheck probes one dependency. A failing optional check is reported without failing readiness.
+type HealthCheck struct {
+	Name     string
+	Optional bool
+	Check    func(ctx context.Context) error
+}
+
+type HealthService interface {
+	Live(ctx context.Context) domain.HealthResponse
+	Ready(ctx context.Context) (domain.HealthResponse, bool)
+	Drain()
+}
+
+type healthService struct {
+	checks   []HealthCheck
+	timeout  time.Duration
+	draining atomic.Bool
+	logger   *slog.Logger
+}
+
+func (h *healthService) Live(ctx context.Context) domain.HealthResponse {
+	return domain.HealthResponse{Status: domain.HealthStatusUp}
+}
+
+// Ready runs every check concurrently, each within the timeout, and reports whether the instance should
+// receive traffic.
+func (h *healthService) Ready(ctx context.Context) (domain.HealthResponse, bool) {
+	results := make([]domain.DependencyHealth, len(h.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			results[i] = h.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	res := domain.HealthResponse{Status: domain.HealthStatusUp, Checks: make(map[string]domain.DependencyHealth, len(h.checks))}
+	ready := true
+	for i, check := range h.checks {
+		res.Checks[check.Name] = results[i]
+		if results[i].Status != domain.HealthStatusUp && !check.Optional {
+			ready = false
+		}
+	}
+
+	if !ready {
+		res.Status = domain.HealthStatusDown
+	}
+	if h.draining.Load() {
+		res.Status = domain.HealthStatusDraining
+		ready = false
+	}
+
+	return res, ready
+}
+
+// Drain makes readiness fail from now on, so that traffic is moved away before the instance stops.
+func (h *healthService) Drain() {
+	h.draining.Store(true)
+}
+
+func (h *healthService) run(ctx context.Context, check HealthCheck) domain.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+	res := domain.DependencyHealth{
+		Status:    domain.HealthStatusUp,
+		Optional:  check.Optional,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		h.logger.WarnContext(ctx, "health check failed", "check", check.Name, "error", err)
+		res.Status = domain.HealthStatusDown
+	}
+	return res
+}
+
+func NewHealthService(checks []HealthCheck, timeout time.Duration, logger *slog.Logger) HealthService {
+	return &healthService{
+		checks:  checks,
+		timeout: timeout,
+		logger:  logger.With("component", "health"),
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthService(t *testing.T) {
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	t.Run("live", func(t *testing.T) {
+		svc := NewHealthService(nil, time.Second, slog.Default())
+
+		assert.Equal(t, domain.HealthResponse{Status: domain.HealthStatusUp}, svc.Live(context.TODO()))
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		svc := NewHealthService([]HealthCheck{{Name: "redis", Check: up}}, time.Second, slog.Default())
+
+		res, ready := svc.Ready(context.TODO())
+		assert.True(t, ready)
+		assert.Equal(t, domain.HealthStatusUp, res.Status)
+		assert.Equal(t, domain.HealthStatusUp, res.Checks["redis"].Status)
+	})
+
+	t.Run("dependency down", func(t *testing.T) {
+		svc := NewHealthService([]HealthCheck{{Name: "redis", Check: down}}, time.Second, slog.Default())
+
+		res, ready := svc.Ready(context.TODO())
+		assert.False(t, ready)
+		assert.Equal(t, domain.HealthStatusDown, res.Status)
+		assert.Equal(t, domain.HealthStatusDown, res.Checks["redis"].Status)
+	})
+
+	t.Run("optional dependency down", func(t *testing.T) {
+		svc := NewHealthService([]HealthCheck{
+			{Name: "redis", Check: up},
+			{Name: "fidibo", Optional: true, Check: down},
+		}, time.Second, slog.Default())
+
+		res, ready := svc.Ready(context.TODO())
+		assert.True(t, ready)
+		assert.Equal(t, domain.HealthStatusUp, res.Status)
+		assert.Equal(t, domain.HealthStatusDown, res.Checks["fidibo"].Status)
+		assert.True(t, res.Checks["fidibo"].Optional)
+	})
+
+	t.Run("check times out", func(t *testing.T) {
+		slow := func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		svc := NewHealthService([]HealthCheck{{Name: "redis", Check: slow}}, 10*time.Millisecond, slog.Default())
+
+		res, ready := svc.Ready(context.TODO())
+		assert.False(t, ready)
+		assert.Equal(t, domain.HealthStatusDown, res.Checks["redis"].Status)
+	})
+
+	t.Run("draining", func(t *testing.T) {
+		svc := NewHealthService([]HealthCheck{{Name: "redis", Check: up}}, time.Second, slog.Default())
+		svc.Drain()
+
+		res, ready := svc.Ready(context.TODO())
+		assert.False(t, ready)
+		assert.Equal(t, domain.HealthStatusDraining, res.Status)
+		assert.Equal(t, domain.HealthStatusUp, res.Checks["redis"].Status)
+	})
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/repository"
+)
+
+type HistoryService interface {
+	List(ctx context.Context, username string) (domain.SearchHistory, error)
+	Clear(ctx context.Context, username string) error
+	SetEnabled(ctx context.Context, username string, enabled bool) error
+}
+
+type historyService struct {
+	repo   repository.HistoryRepository
+	logger *slog.Logger
+}
+
+func (h *historyService) List(ctx context.Context, username string) (domain.SearchHistory, error) {
+	enabled, err := h.repo.Enabled(ctx, username)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "could not get preference", "error", err)
+		return domain.SearchHistory{}, err
+	}
+
+	entries, err := h.repo.List(ctx, username)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "could not get history", "error", err)
+		return domain.SearchHistory{}, err
+	}
+
+	return domain.SearchHistory{Enabled: enabled, Entries: entries}, nil
+}
+
+func (h *historyService) Clear(ctx context.Context, username string) error {
+	err := h.repo.Clear(ctx, username)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "could not clear history", "error", err)
+	}
+	return err
+}
+
+// SetEnabled stores the preference and, when a user opts out, also forgets what was recorded so far.
+func (h *historyService) SetEnabled(ctx context.Context, username string, enabled bool) error {
+	err := h.repo.SetEnabled(ctx, username, enabled)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "could not store preference", "error", err)
+		return err
+	}
+
+	if enabled {
+		return nil
+	}
+	return h.Clear(ctx, username)
+}
+
+func NewHistoryService(repo repository.HistoryRepository, logger *slog.Logger) HistoryService {
+	return &historyService{
+		repo:   repo,
+		logger: logger.With("component", "history"),
+	}
+}
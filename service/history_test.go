@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	repositoryMock "github.com/kavehjamshidi/fidibo-challenge/repository/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListHistory(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		entries := []domain.SearchHistoryEntry{{Query: "test", ResultCount: 1, SearchedAt: time.Now().UTC()}}
+
+		repo := &repositoryMock.HistoryRepository{}
+		repo.On("Enabled", context.TODO(), "test").Return(true, nil)
+		repo.On("List", context.TODO(), "test").Return(entries, nil)
+
+		svc := NewHistoryService(repo, slog.Default())
+		history, err := svc.List(context.TODO(), "test")
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.SearchHistory{Enabled: true, Entries: entries}, history)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		errorMsg := "redis error"
+
+		repo := &repositoryMock.HistoryRepository{}
+		repo.On("Enabled", context.TODO(), "test").Return(false, errors.New(errorMsg))
+
+		svc := NewHistoryService(repo, slog.Default())
+		_, err := svc.List(context.TODO(), "test")
+
+		assert.ErrorContains(t, err, errorMsg)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestSetHistoryEnabled(t *testing.T) {
+	t.Run("opting out clears the history", func(t *testing.T) {
+		repo := &repositoryMock.HistoryRepository{}
+		repo.On("SetEnabled", context.TODO(), "test", false).Return(nil)
+		repo.On("Clear", context.TODO(), "test").Return(nil)
+
+		svc := NewHistoryService(repo, slog.Default())
+		err := svc.SetEnabled(context.TODO(), "test", false)
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("opting in", func(t *testing.T) {
+		repo := &repositoryMock.HistoryRepository{}
+		repo.On("SetEnabled", context.TODO(), "test", true).Return(nil)
+
+		svc := NewHistoryService(repo, slog.Default())
+		err := svc.SetEnabled(context.TODO(), "test", true)
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+}
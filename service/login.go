@@ -1,51 +1,86 @@
 package service
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"time"
 
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/metrics"
 	"github.com/kavehjamshidi/fidibo-challenge/internal/token"
+	"github.com/kavehjamshidi/fidibo-challenge/repository"
 )
 
 type LoginService interface {
-	Login(credentials domain.LoginRequest) (domain.LoginResponse, error)
+	Login(ctx context.Context, credentials domain.LoginRequest) (domain.LoginResponse, error)
 }
 
 type loginService struct {
 	accessTokenExpiry  time.Duration
-	accessTokenSecret  string
+	accessTokenKeys    token.Keyring
 	refreshTokenExpiry time.Duration
-	refreshTokenSecret string
+	refreshTokenKeys   token.Keyring
+	users              repository.UserRepository
+	publisher          WebhookPublisher
+	logger             *slog.Logger
 }
 
-func (l *loginService) Login(credentials domain.LoginRequest) (domain.LoginResponse, error) {
-	accessToken, err := token.GenerateJWT(credentials.Username, l.accessTokenSecret, l.accessTokenExpiry)
+func (l *loginService) Login(ctx context.Context, credentials domain.LoginRequest) (domain.LoginResponse, error) {
+	accessToken, err := token.GenerateJWT(credentials.Username, l.accessTokenKeys.Secret(), l.accessTokenExpiry)
 	if err != nil {
-		log.Printf("Login Service - could not generate access token: %v", err)
+		l.logger.ErrorContext(ctx, "could not generate access token", "error", err)
+		metrics.Tokens.WithLabelValues(metrics.TokenLogin, metrics.ResultFailure).Inc()
 		return domain.LoginResponse{}, err
 	}
 
-	refreshToken, err := token.GenerateJWT(credentials.Username, l.refreshTokenSecret, l.refreshTokenExpiry)
+	refreshToken, err := token.GenerateJWT(credentials.Username, l.refreshTokenKeys.Secret(), l.refreshTokenExpiry)
 	if err != nil {
-		log.Printf("Login Service - could not generate refresh token: %v", err)
+		l.logger.ErrorContext(ctx, "could not generate refresh token", "error", err)
+		metrics.Tokens.WithLabelValues(metrics.TokenLogin, metrics.ResultFailure).Inc()
 		return domain.LoginResponse{}, err
 	}
 
+	metrics.Tokens.WithLabelValues(metrics.TokenLogin, metrics.ResultSuccess).Inc()
+	l.register(ctx, credentials.Username)
+
 	return domain.LoginResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	}, nil
 }
 
+// register publishes a user registered event the first time a username logs in. Failures are only logged so
+// that they never keep a user from logging in.
+func (l *loginService) register(ctx context.Context, username string) {
+	created, err := l.users.Register(ctx, username)
+	if err != nil {
+		l.logger.WarnContext(ctx, "could not register user", "error", err)
+		return
+	}
+	if !created {
+		return
+	}
+
+	err = l.publisher.Publish(ctx, domain.EventUserRegistered, username, domain.UserRegisteredEvent{Username: username})
+	if err != nil {
+		l.logger.WarnContext(ctx, "could not publish user registered event", "error", err)
+	}
+}
+
 func NewLoginService(accessTokenExpiry time.Duration,
-	accessTokenSecret string,
+	accessTokenKeys token.Keyring,
 	refreshTokenExpiry time.Duration,
-	refreshTokenSecret string) LoginService {
+	refreshTokenKeys token.Keyring,
+	users repository.UserRepository,
+	publisher WebhookPublisher,
+	logger *slog.Logger) LoginService {
 	return &loginService{
 		accessTokenExpiry:  accessTokenExpiry,
-		accessTokenSecret:  accessTokenSecret,
+		accessTokenKeys:    accessTokenKeys,
 		refreshTokenExpiry: refreshTokenExpiry,
-		refreshTokenSecret: refreshTokenSecret,
+		refreshTokenKeys:   refreshTokenKeys,
+		users:              users,
+		publisher:          publisher,
+		logger:             logger.With("component", "login"),
 	}
 }
@@ -1,26 +1,76 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"log/slog"
 	"testing"
 	"time"
 
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/metrics"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/token"
+	repositoryMock "github.com/kavehjamshidi/fidibo-challenge/repository/mocks"
+	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestLogin(t *testing.T) {
 	expiry := 10 * time.Minute
-	secret := "test secret"
+	keys := token.NewKeyring("test secret", time.Minute)
 
 	credentials := domain.LoginRequest{
 		Username: "test",
 		Password: "test",
 	}
 
-	svc := NewLoginService(expiry, secret, expiry, secret)
+	t.Run("first login publishes user registered", func(t *testing.T) {
+		users := &repositoryMock.UserRepository{}
+		publisher := &mocks.WebhookPublisher{}
 
-	result, err := svc.Login(credentials)
-	assert.NoError(t, err)
-	assert.NotEmpty(t, result.AccessToken)
-	assert.NotEmpty(t, result.RefreshToken)
+		users.On("Register", context.TODO(), "test").Return(true, nil)
+		publisher.On("Publish", context.TODO(), domain.EventUserRegistered, "test", domain.UserRegisteredEvent{Username: "test"}).Return(nil)
+
+		issued := metrics.Tokens.WithLabelValues(metrics.TokenLogin, metrics.ResultSuccess)
+		before := testutil.ToFloat64(issued)
+
+		svc := NewLoginService(expiry, keys, expiry, keys, users, publisher, slog.Default())
+
+		result, err := svc.Login(context.TODO(), credentials)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.AccessToken)
+		assert.NotEmpty(t, result.RefreshToken)
+		assert.Equal(t, before+1, testutil.ToFloat64(issued))
+		users.AssertExpectations(t)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("known user", func(t *testing.T) {
+		users := &repositoryMock.UserRepository{}
+		publisher := &mocks.WebhookPublisher{}
+
+		users.On("Register", context.TODO(), "test").Return(false, nil)
+
+		svc := NewLoginService(expiry, keys, expiry, keys, users, publisher, slog.Default())
+
+		_, err := svc.Login(context.TODO(), credentials)
+		assert.NoError(t, err)
+		users.AssertExpectations(t)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("registration errors do not fail the login", func(t *testing.T) {
+		users := &repositoryMock.UserRepository{}
+		publisher := &mocks.WebhookPublisher{}
+
+		users.On("Register", context.TODO(), "test").Return(false, errors.New("redis error"))
+
+		svc := NewLoginService(expiry, keys, expiry, keys, users, publisher, slog.Default())
+
+		result, err := svc.Login(context.TODO(), credentials)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.AccessToken)
+		users.AssertExpectations(t)
+	})
 }
@@ -0,0 +1,54 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// BookService is an autogenerated mock type for the BookService type
+type BookService struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: ctx, lookup
+func (_m *BookService) Get(ctx context.Context, lookup domain.BookLookup) (domain.Book, error) {
+	ret := _m.Called(ctx, lookup)
+
+	var r0 domain.Book
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BookLookup) (domain.Book, error)); ok {
+		return rf(ctx, lookup)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BookLookup) domain.Book); ok {
+		r0 = rf(ctx, lookup)
+	} else {
+		r0 = ret.Get(0).(domain.Book)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.BookLookup) error); ok {
+		r1 = rf(ctx, lookup)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewBookService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewBookService creates a new instance of BookService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewBookService(t mockConstructorTestingTNewBookService) *BookService {
+	mock := &BookService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
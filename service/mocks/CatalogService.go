@@ -0,0 +1,78 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CatalogService is an autogenerated mock type for the CatalogService type
+type CatalogService struct {
+	mock.Mock
+}
+
+// AuthorBooks provides a mock function with given fields: ctx, req
+func (_m *CatalogService) AuthorBooks(ctx context.Context, req domain.CatalogRequest) (domain.SearchResult, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 domain.SearchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CatalogRequest) (domain.SearchResult, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CatalogRequest) domain.SearchResult); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(domain.SearchResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.CatalogRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PublisherBooks provides a mock function with given fields: ctx, req
+func (_m *CatalogService) PublisherBooks(ctx context.Context, req domain.CatalogRequest) (domain.SearchResult, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 domain.SearchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CatalogRequest) (domain.SearchResult, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CatalogRequest) domain.SearchResult); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(domain.SearchResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.CatalogRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewCatalogService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewCatalogService creates a new instance of CatalogService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewCatalogService(t mockConstructorTestingTNewCatalogService) *CatalogService {
+	mock := &CatalogService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
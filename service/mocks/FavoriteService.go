@@ -0,0 +1,92 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// FavoriteService is an autogenerated mock type for the FavoriteService type
+type FavoriteService struct {
+	mock.Mock
+}
+
+// Add provides a mock function with given fields: ctx, username, bookID
+func (_m *FavoriteService) Add(ctx context.Context, username string, bookID string) (domain.Favorite, error) {
+	ret := _m.Called(ctx, username, bookID)
+
+	var r0 domain.Favorite
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (domain.Favorite, error)); ok {
+		return rf(ctx, username, bookID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) domain.Favorite); ok {
+		r0 = rf(ctx, username, bookID)
+	} else {
+		r0 = ret.Get(0).(domain.Favorite)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, username, bookID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, username
+func (_m *FavoriteService) List(ctx context.Context, username string) (domain.FavoritesResponse, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 domain.FavoritesResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.FavoritesResponse, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.FavoritesResponse); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(domain.FavoritesResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Remove provides a mock function with given fields: ctx, username, bookID
+func (_m *FavoriteService) Remove(ctx context.Context, username string, bookID string) error {
+	ret := _m.Called(ctx, username, bookID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, username, bookID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewFavoriteService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewFavoriteService creates a new instance of FavoriteService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewFavoriteService(t mockConstructorTestingTNewFavoriteService) *FavoriteService {
+	mock := &FavoriteService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,73 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// HealthService is an autogenerated mock type for the HealthService type
+type HealthService struct {
+	mock.Mock
+}
+
+// Drain provides a mock function with given fields:
+func (_m *HealthService) Drain() {
+	_m.Called()
+}
+
+// Live provides a mock function with given fields: ctx
+func (_m *HealthService) Live(ctx context.Context) domain.HealthResponse {
+	ret := _m.Called(ctx)
+
+	var r0 domain.HealthResponse
+	if rf, ok := ret.Get(0).(func(context.Context) domain.HealthResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(domain.HealthResponse)
+	}
+
+	return r0
+}
+
+// Ready provides a mock function with given fields: ctx
+func (_m *HealthService) Ready(ctx context.Context) (domain.HealthResponse, bool) {
+	ret := _m.Called(ctx)
+
+	var r0 domain.HealthResponse
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(context.Context) (domain.HealthResponse, bool)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) domain.HealthResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(domain.HealthResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) bool); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewHealthService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewHealthService creates a new instance of HealthService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewHealthService(t mockConstructorTestingTNewHealthService) *HealthService {
+	mock := &HealthService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,82 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// HistoryService is an autogenerated mock type for the HistoryService type
+type HistoryService struct {
+	mock.Mock
+}
+
+// Clear provides a mock function with given fields: ctx, username
+func (_m *HistoryService) Clear(ctx context.Context, username string) error {
+	ret := _m.Called(ctx, username)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// List provides a mock function with given fields: ctx, username
+func (_m *HistoryService) List(ctx context.Context, username string) (domain.SearchHistory, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 domain.SearchHistory
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.SearchHistory, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.SearchHistory); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(domain.SearchHistory)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetEnabled provides a mock function with given fields: ctx, username, enabled
+func (_m *HistoryService) SetEnabled(ctx context.Context, username string, enabled bool) error {
+	ret := _m.Called(ctx, username, enabled)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) error); ok {
+		r0 = rf(ctx, username, enabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewHistoryService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewHistoryService creates a new instance of HistoryService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewHistoryService(t mockConstructorTestingTNewHistoryService) *HistoryService {
+	mock := &HistoryService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,23 +14,23 @@ type LoginService struct {
 	mock.Mock
 }
 
-// Login provides a mock function with given fields: credentials
-func (_m *LoginService) Login(credentials domain.LoginRequest) (domain.LoginResponse, error) {
-	ret := _m.Called(credentials)
+// Login provides a mock function with given fields: ctx, credentials
+func (_m *LoginService) Login(ctx context.Context, credentials domain.LoginRequest) (domain.LoginResponse, error) {
+	ret := _m.Called(ctx, credentials)
 
 	var r0 domain.LoginResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(domain.LoginRequest) (domain.LoginResponse, error)); ok {
-		return rf(credentials)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LoginRequest) (domain.LoginResponse, error)); ok {
+		return rf(ctx, credentials)
 	}
-	if rf, ok := ret.Get(0).(func(domain.LoginRequest) domain.LoginResponse); ok {
-		r0 = rf(credentials)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LoginRequest) domain.LoginResponse); ok {
+		r0 = rf(ctx, credentials)
 	} else {
 		r0 = ret.Get(0).(domain.LoginResponse)
 	}
 
-	if rf, ok := ret.Get(1).(func(domain.LoginRequest) error); ok {
-		r1 = rf(credentials)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.LoginRequest) error); ok {
+		r1 = rf(ctx, credentials)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,54 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NotificationService is an autogenerated mock type for the NotificationService type
+type NotificationService struct {
+	mock.Mock
+}
+
+// List provides a mock function with given fields: ctx, username
+func (_m *NotificationService) List(ctx context.Context, username string) (domain.NotificationsResponse, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 domain.NotificationsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.NotificationsResponse, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.NotificationsResponse); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(domain.NotificationsResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewNotificationService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewNotificationService creates a new instance of NotificationService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewNotificationService(t mockConstructorTestingTNewNotificationService) *NotificationService {
+	mock := &NotificationService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,23 +14,23 @@ type RefreshTokenService struct {
 	mock.Mock
 }
 
-// RefreshToken provides a mock function with given fields: username
-func (_m *RefreshTokenService) RefreshToken(username string) (domain.RefreshTokenResponse, error) {
-	ret := _m.Called(username)
+// RefreshToken provides a mock function with given fields: ctx, username
+func (_m *RefreshTokenService) RefreshToken(ctx context.Context, username string) (domain.RefreshTokenResponse, error) {
+	ret := _m.Called(ctx, username)
 
 	var r0 domain.RefreshTokenResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (domain.RefreshTokenResponse, error)); ok {
-		return rf(username)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.RefreshTokenResponse, error)); ok {
+		return rf(ctx, username)
 	}
-	if rf, ok := ret.Get(0).(func(string) domain.RefreshTokenResponse); ok {
-		r0 = rf(username)
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.RefreshTokenResponse); ok {
+		r0 = rf(ctx, username)
 	} else {
 		r0 = ret.Get(0).(domain.RefreshTokenResponse)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(username)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,140 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SavedSearchService is an autogenerated mock type for the SavedSearchService type
+type SavedSearchService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, username, req
+func (_m *SavedSearchService) Create(ctx context.Context, username string, req domain.SavedSearchRequest) (domain.SavedSearch, error) {
+	ret := _m.Called(ctx, username, req)
+
+	var r0 domain.SavedSearch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.SavedSearchRequest) (domain.SavedSearch, error)); ok {
+		return rf(ctx, username, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.SavedSearchRequest) domain.SavedSearch); ok {
+		r0 = rf(ctx, username, req)
+	} else {
+		r0 = ret.Get(0).(domain.SavedSearch)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.SavedSearchRequest) error); ok {
+		r1 = rf(ctx, username, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, username, id
+func (_m *SavedSearchService) Delete(ctx context.Context, username string, id string) error {
+	ret := _m.Called(ctx, username, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, username, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: ctx, username, id
+func (_m *SavedSearchService) Get(ctx context.Context, username string, id string) (domain.SavedSearch, error) {
+	ret := _m.Called(ctx, username, id)
+
+	var r0 domain.SavedSearch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (domain.SavedSearch, error)); ok {
+		return rf(ctx, username, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) domain.SavedSearch); ok {
+		r0 = rf(ctx, username, id)
+	} else {
+		r0 = ret.Get(0).(domain.SavedSearch)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, username, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, username
+func (_m *SavedSearchService) List(ctx context.Context, username string) (domain.SavedSearchesResponse, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 domain.SavedSearchesResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.SavedSearchesResponse, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.SavedSearchesResponse); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(domain.SavedSearchesResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, username, id, req
+func (_m *SavedSearchService) Update(ctx context.Context, username string, id string, req domain.SavedSearchRequest) (domain.SavedSearch, error) {
+	ret := _m.Called(ctx, username, id, req)
+
+	var r0 domain.SavedSearch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, domain.SavedSearchRequest) (domain.SavedSearch, error)); ok {
+		return rf(ctx, username, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, domain.SavedSearchRequest) domain.SavedSearch); ok {
+		r0 = rf(ctx, username, id, req)
+	} else {
+		r0 = ret.Get(0).(domain.SavedSearch)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, domain.SavedSearchRequest) error); ok {
+		r1 = rf(ctx, username, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewSavedSearchService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSavedSearchService creates a new instance of SavedSearchService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSavedSearchService(t mockConstructorTestingTNewSavedSearchService) *SavedSearchService {
+	mock := &SavedSearchService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -14,23 +14,23 @@ type SearchService struct {
 	mock.Mock
 }
 
-// Search provides a mock function with given fields: ctx, query
-func (_m *SearchService) Search(ctx context.Context, query string) (domain.SearchResult, error) {
-	ret := _m.Called(ctx, query)
+// Search provides a mock function with given fields: ctx, req
+func (_m *SearchService) Search(ctx context.Context, req domain.SearchRequest) (domain.SearchResult, error) {
+	ret := _m.Called(ctx, req)
 
 	var r0 domain.SearchResult
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.SearchResult, error)); ok {
-		return rf(ctx, query)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SearchRequest) (domain.SearchResult, error)); ok {
+		return rf(ctx, req)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) domain.SearchResult); ok {
-		r0 = rf(ctx, query)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SearchRequest) domain.SearchResult); ok {
+		r0 = rf(ctx, req)
 	} else {
 		r0 = ret.Get(0).(domain.SearchResult)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, query)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SearchRequest) error); ok {
+		r1 = rf(ctx, req)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,54 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SuggestService is an autogenerated mock type for the SuggestService type
+type SuggestService struct {
+	mock.Mock
+}
+
+// Suggest provides a mock function with given fields: ctx, req
+func (_m *SuggestService) Suggest(ctx context.Context, req domain.SuggestRequest) (domain.SuggestResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 domain.SuggestResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SuggestRequest) (domain.SuggestResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SuggestRequest) domain.SuggestResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(domain.SuggestResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SuggestRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewSuggestService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSuggestService creates a new instance of SuggestService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSuggestService(t mockConstructorTestingTNewSuggestService) *SuggestService {
+	mock := &SuggestService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
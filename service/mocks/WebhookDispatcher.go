@@ -0,0 +1,60 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// WebhookDispatcher is an autogenerated mock type for the WebhookDispatcher type
+type WebhookDispatcher struct {
+	mock.Mock
+}
+
+// RunOnce provides a mock function with given fields: ctx
+func (_m *WebhookDispatcher) RunOnce(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Start provides a mock function with given fields: ctx, interval
+func (_m *WebhookDispatcher) Start(ctx context.Context, interval time.Duration) {
+	_m.Called(ctx, interval)
+}
+
+type mockConstructorTestingTNewWebhookDispatcher interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWebhookDispatcher creates a new instance of WebhookDispatcher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWebhookDispatcher(t mockConstructorTestingTNewWebhookDispatcher) *WebhookDispatcher {
+	mock := &WebhookDispatcher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,43 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WebhookPublisher is an autogenerated mock type for the WebhookPublisher type
+type WebhookPublisher struct {
+	mock.Mock
+}
+
+// Publish provides a mock function with given fields: ctx, eventType, username, data
+func (_m *WebhookPublisher) Publish(ctx context.Context, eventType string, username string, data interface{}) error {
+	ret := _m.Called(ctx, eventType, username, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, interface{}) error); ok {
+		r0 = rf(ctx, eventType, username, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewWebhookPublisher interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWebhookPublisher creates a new instance of WebhookPublisher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWebhookPublisher(t mockConstructorTestingTNewWebhookPublisher) *WebhookPublisher {
+	mock := &WebhookPublisher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
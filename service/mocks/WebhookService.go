@@ -0,0 +1,188 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/kavehjamshidi/fidibo-challenge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WebhookService is an autogenerated mock type for the WebhookService type
+type WebhookService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, username, req
+func (_m *WebhookService) Create(ctx context.Context, username string, req domain.WebhookRequest) (domain.Webhook, error) {
+	ret := _m.Called(ctx, username, req)
+
+	var r0 domain.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.WebhookRequest) (domain.Webhook, error)); ok {
+		return rf(ctx, username, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.WebhookRequest) domain.Webhook); ok {
+		r0 = rf(ctx, username, req)
+	} else {
+		r0 = ret.Get(0).(domain.Webhook)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.WebhookRequest) error); ok {
+		r1 = rf(ctx, username, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, username, id
+func (_m *WebhookService) Delete(ctx context.Context, username string, id string) error {
+	ret := _m.Called(ctx, username, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, username, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Deliveries provides a mock function with given fields: ctx, username, id
+func (_m *WebhookService) Deliveries(ctx context.Context, username string, id string) (domain.WebhookDeliveriesResponse, error) {
+	ret := _m.Called(ctx, username, id)
+
+	var r0 domain.WebhookDeliveriesResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (domain.WebhookDeliveriesResponse, error)); ok {
+		return rf(ctx, username, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) domain.WebhookDeliveriesResponse); ok {
+		r0 = rf(ctx, username, id)
+	} else {
+		r0 = ret.Get(0).(domain.WebhookDeliveriesResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, username, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Get provides a mock function with given fields: ctx, username, id
+func (_m *WebhookService) Get(ctx context.Context, username string, id string) (domain.Webhook, error) {
+	ret := _m.Called(ctx, username, id)
+
+	var r0 domain.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (domain.Webhook, error)); ok {
+		return rf(ctx, username, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) domain.Webhook); ok {
+		r0 = rf(ctx, username, id)
+	} else {
+		r0 = ret.Get(0).(domain.Webhook)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, username, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, username
+func (_m *WebhookService) List(ctx context.Context, username string) (domain.WebhooksResponse, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 domain.WebhooksResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.WebhooksResponse, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.WebhooksResponse); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(domain.WebhooksResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Redeliver provides a mock function with given fields: ctx, username, id, deliveryID
+func (_m *WebhookService) Redeliver(ctx context.Context, username string, id string, deliveryID string) (domain.WebhookDelivery, error) {
+	ret := _m.Called(ctx, username, id, deliveryID)
+
+	var r0 domain.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (domain.WebhookDelivery, error)); ok {
+		return rf(ctx, username, id, deliveryID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) domain.WebhookDelivery); ok {
+		r0 = rf(ctx, username, id, deliveryID)
+	} else {
+		r0 = ret.Get(0).(domain.WebhookDelivery)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, username, id, deliveryID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, username, id, req
+func (_m *WebhookService) Update(ctx context.Context, username string, id string, req domain.WebhookRequest) (domain.Webhook, error) {
+	ret := _m.Called(ctx, username, id, req)
+
+	var r0 domain.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, domain.WebhookRequest) (domain.Webhook, error)); ok {
+		return rf(ctx, username, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, domain.WebhookRequest) domain.Webhook); ok {
+		r0 = rf(ctx, username, id, req)
+	} else {
+		r0 = ret.Get(0).(domain.Webhook)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, domain.WebhookRequest) error); ok {
+		r1 = rf(ctx, username, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewWebhookService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWebhookService creates a new instance of WebhookService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWebhookService(t mockConstructorTestingTNewWebhookService) *WebhookService {
+	mock := &WebhookService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
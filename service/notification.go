@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/repository"
+)
+
+type NotificationService interface {
+	List(ctx context.Context, username string) (domain.NotificationsResponse, error)
+}
+
+type notificationService struct {
+	repo   repository.NotificationRepository
+	logger *slog.Logger
+}
+
+func (n *notificationService) List(ctx context.Context, username string) (domain.NotificationsResponse, error) {
+	notifications, err := n.repo.List(ctx, username)
+	if err != nil {
+		n.logger.ErrorContext(ctx, "could not list notifications", "error", err)
+		return domain.NotificationsResponse{}, err
+	}
+
+	return domain.NotificationsResponse{Notifications: notifications}, nil
+}
+
+func NewNotificationService(repo repository.NotificationRepository, logger *slog.Logger) NotificationService {
+	return &notificationService{
+		repo:   repo,
+		logger: logger.With("component", "notification"),
+	}
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	repositoryMock "github.com/kavehjamshidi/fidibo-challenge/repository/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListNotifications(t *testing.T) {
+	notifications := []domain.Notification{{ID: "1", Keyword: "kafka"}}
+
+	repo := &repositoryMock.NotificationRepository{}
+	repo.On("List", context.TODO(), "test").Return(notifications, nil)
+
+	svc := NewNotificationService(repo, slog.Default())
+	res, err := svc.List(context.TODO(), "test")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.NotificationsResponse{Notifications: notifications}, res)
+	repo.AssertExpectations(t)
+}
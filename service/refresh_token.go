@@ -1,37 +1,43 @@
 package service
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"time"
 
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/metrics"
 	"github.com/kavehjamshidi/fidibo-challenge/internal/token"
 )
 
 type RefreshTokenService interface {
-	RefreshToken(username string) (domain.RefreshTokenResponse, error)
+	RefreshToken(ctx context.Context, username string) (domain.RefreshTokenResponse, error)
 }
 
 type refreshTokenService struct {
 	accessTokenExpiry  time.Duration
-	accessTokenSecret  string
+	accessTokenKeys    token.Keyring
 	refreshTokenExpiry time.Duration
-	refreshTokenSecret string
+	refreshTokenKeys   token.Keyring
+	logger             *slog.Logger
 }
 
-func (l *refreshTokenService) RefreshToken(username string) (domain.RefreshTokenResponse, error) {
-	accessToken, err := token.GenerateJWT(username, l.accessTokenSecret, l.accessTokenExpiry)
+func (l *refreshTokenService) RefreshToken(ctx context.Context, username string) (domain.RefreshTokenResponse, error) {
+	accessToken, err := token.GenerateJWT(username, l.accessTokenKeys.Secret(), l.accessTokenExpiry)
 	if err != nil {
-		log.Printf("RefreshToken Service - could not generate access token: %v", err)
+		l.logger.ErrorContext(ctx, "could not generate access token", "error", err)
+		metrics.Tokens.WithLabelValues(metrics.TokenRefresh, metrics.ResultFailure).Inc()
 		return domain.RefreshTokenResponse{}, err
 	}
 
-	refreshToken, err := token.GenerateJWT(username, l.refreshTokenSecret, l.refreshTokenExpiry)
+	refreshToken, err := token.GenerateJWT(username, l.refreshTokenKeys.Secret(), l.refreshTokenExpiry)
 	if err != nil {
-		log.Printf("RefreshToken Service - could not generate refresh token: %v", err)
+		l.logger.ErrorContext(ctx, "could not generate refresh token", "error", err)
+		metrics.Tokens.WithLabelValues(metrics.TokenRefresh, metrics.ResultFailure).Inc()
 		return domain.RefreshTokenResponse{}, err
 	}
 
+	metrics.Tokens.WithLabelValues(metrics.TokenRefresh, metrics.ResultSuccess).Inc()
 	return domain.RefreshTokenResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -39,13 +45,15 @@ func (l *refreshTokenService) RefreshToken(username string) (domain.RefreshToken
 }
 
 func NewRefreshTokenService(accessTokenExpiry time.Duration,
-	accessTokenSecret string,
+	accessTokenKeys token.Keyring,
 	refreshTokenExpiry time.Duration,
-	refreshTokenSecret string) RefreshTokenService {
+	refreshTokenKeys token.Keyring,
+	logger *slog.Logger) RefreshTokenService {
 	return &refreshTokenService{
 		accessTokenExpiry:  accessTokenExpiry,
-		accessTokenSecret:  accessTokenSecret,
+		accessTokenKeys:    accessTokenKeys,
 		refreshTokenExpiry: refreshTokenExpiry,
-		refreshTokenSecret: refreshTokenSecret,
+		refreshTokenKeys:   refreshTokenKeys,
+		logger:             logger.With("component", "refresh_token"),
 	}
 }
@@ -1,20 +1,23 @@
 package service
 
 import (
+	"context"
+	"log/slog"
 	"testing"
 	"time"
 
+	"github.com/kavehjamshidi/fidibo-challenge/internal/token"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestRefreshToken(t *testing.T) {
 	expiry := 10 * time.Minute
-	secret := "test secret"
+	keys := token.NewKeyring("test secret", time.Minute)
 	username := "test"
 
-	svc := NewRefreshTokenService(expiry, secret, expiry, secret)
+	svc := NewRefreshTokenService(expiry, keys, expiry, keys, slog.Default())
 
-	result, err := svc.RefreshToken(username)
+	result, err := svc.RefreshToken(context.TODO(), username)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, result.AccessToken)
 	assert.NotEmpty(t, result.RefreshToken)
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/webhook"
+	"github.com/kavehjamshidi/fidibo-challenge/repository"
+)
+
+type SavedSearchService interface {
+	Create(ctx context.Context, username string, req domain.SavedSearchRequest) (domain.SavedSearch, error)
+	Get(ctx context.Context, username string, id string) (domain.SavedSearch, error)
+	List(ctx context.Context, username string) (domain.SavedSearchesResponse, error)
+	Update(ctx context.Context, username string, id string, req domain.SavedSearchRequest) (domain.SavedSearch, error)
+	Delete(ctx context.Context, username string, id string) error
+}
+
+type savedSearchService struct {
+	repo     repository.SavedSearchRepository
+	webhooks repository.WebhookRepository
+	guard    webhook.Guard
+	logger   *slog.Logger
+}
+
+// Create stores a saved search. When the request carries a webhook URL, a webhook that receives the new results
+// of the search is created for it, and its signing secret is returned with the saved search only this once.
+func (s *savedSearchService) Create(ctx context.Context, username string, req domain.SavedSearchRequest) (domain.SavedSearch, error) {
+	id, err := newID()
+	if err != nil {
+		return domain.SavedSearch{}, err
+	}
+
+	search := domain.SavedSearch{
+		ID:        id,
+		Username:  username,
+		CreatedAt: time.Now().UTC(),
+	}
+	applySavedSearchRequest(&search, req)
+
+	secret, err := s.linkWebhook(ctx, &search, req.WebhookURL)
+	if err != nil {
+		return domain.SavedSearch{}, err
+	}
+
+	err = s.repo.Create(ctx, search)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "could not create saved search", "error", err)
+		s.deleteWebhook(ctx, search.Username, search.WebhookID)
+		return domain.SavedSearch{}, err
+	}
+
+	search.WebhookSecret = secret
+	return search, nil
+}
+
+func (s *savedSearchService) Get(ctx context.Context, username string, id string) (domain.SavedSearch, error) {
+	search, err := s.repo.Get(ctx, username, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "could not get saved search", "saved_search_id", id, "error", err)
+	}
+	return search, err
+}
+
+func (s *savedSearchService) List(ctx context.Context, username string) (domain.SavedSearchesResponse, error) {
+	searches, err := s.repo.List(ctx, username)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "could not list saved searches", "error", err)
+		return domain.SavedSearchesResponse{}, err
+	}
+
+	return domain.SavedSearchesResponse{SavedSearches: searches}, nil
+}
+
+func (s *savedSearchService) Update(ctx context.Context, username string, id string, req domain.SavedSearchRequest) (domain.SavedSearch, error) {
+	search, err := s.repo.Get(ctx, username, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "could not get saved search", "saved_search_id", id, "error", err)
+		return domain.SavedSearch{}, err
+	}
+	applySavedSearchRequest(&search, req)
+
+	secret, err := s.linkWebhook(ctx, &search, req.WebhookURL)
+	if err != nil {
+		return domain.SavedSearch{}, err
+	}
+
+	err = s.repo.Update(ctx, search)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "could not update saved search", "saved_search_id", id, "error", err)
+		return domain.SavedSearch{}, err
+	}
+
+	search.WebhookSecret = secret
+	return search, nil
+}
+
+func (s *savedSearchService) Delete(ctx context.Context, username string, id string) error {
+	search, err := s.repo.Get(ctx, username, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "could not get saved search", "saved_search_id", id, "error", err)
+		return err
+	}
+
+	err = s.repo.Delete(ctx, username, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "could not delete saved search", "saved_search_id", id, "error", err)
+		return err
+	}
+
+	s.deleteWebhook(ctx, username, search.WebhookID)
+	return nil
+}
+
+// linkWebhook points the webhook of search at rawURL. The webhook is created when the search has none, or when
+// its owner has deleted it, and removed when rawURL is empty. The secret of a created webhook is returned.
+func (s *savedSearchService) linkWebhook(ctx context.Context, search *domain.SavedSearch, rawURL string) (string, error) {
+	if rawURL == search.WebhookURL {
+		return "", nil
+	}
+	if rawURL == "" {
+		s.deleteWebhook(ctx, search.Username, search.WebhookID)
+		search.WebhookURL, search.WebhookID = "", ""
+		return "", nil
+	}
+
+	err := checkWebhookURL(ctx, s.guard, s.logger, rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	if search.WebhookID != "" {
+		hook, err := s.webhooks.Get(ctx, search.WebhookID)
+		if err == nil {
+			hook.URL = rawURL
+			err = s.webhooks.Update(ctx, hook)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "could not update webhook", "webhook_id", hook.ID, "error", err)
+				return "", err
+			}
+			search.WebhookURL = rawURL
+			return "", nil
+		}
+		if !errors.Is(err, domain.ErrWebhookNotFound) {
+			s.logger.ErrorContext(ctx, "could not get webhook", "webhook_id", search.WebhookID, "error", err)
+			return "", err
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return "", err
+	}
+
+	hook := domain.Webhook{
+		ID:            id,
+		URL:           rawURL,
+		Events:        []string{domain.EventSavedSearchNewResults},
+		Secret:        secret,
+		Active:        true,
+		CreatedBy:     search.Username,
+		SavedSearchID: search.ID,
+		CreatedAt:     time.Now().UTC(),
+	}
+	err = s.webhooks.Create(ctx, hook)
+	if errors.Is(err, domain.ErrWebhooksLimitReached) {
+		return "", err
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "could not create webhook", "error", err)
+		return "", err
+	}
+
+	search.WebhookURL, search.WebhookID = rawURL, hook.ID
+	return secret, nil
+}
+
+// deleteWebhook removes the webhook of a saved search. A failure is only logged: a webhook left behind is bound
+// to a search that no longer publishes results.
+func (s *savedSearchService) deleteWebhook(ctx context.Context, username string, id string) {
+	if id == "" {
+		return
+	}
+
+	err := s.webhooks.Delete(ctx, username, id)
+	if err != nil && !errors.Is(err, domain.ErrWebhookNotFound) {
+		s.logger.ErrorContext(ctx, "could not delete webhook", "webhook_id", id, "error", err)
+	}
+}
+
+func applySavedSearchRequest(search *domain.SavedSearch, req domain.SavedSearchRequest) {
+	search.Name = req.Name
+	if search.Name == "" {
+		search.Name = req.Keyword
+	}
+	search.Keyword = req.Keyword
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func NewSavedSearchService(repo repository.SavedSearchRepository,
+	webhooks repository.WebhookRepository,
+	guard webhook.Guard,
+	logger *slog.Logger) SavedSearchService {
+	return &savedSearchService{
+		repo:     repo,
+		webhooks: webhooks,
+		guard:    guard,
+		logger:   logger.With("component", "saved_search"),
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch"
+	"github.com/kavehjamshidi/fidibo-challenge/repository"
+)
+
+const savedSearchResultSize = 50
+
+type SavedSearchRunner interface {
+	Start(ctx context.Context, interval time.Duration)
+	RunOnce(ctx context.Context) error
+}
+
+type savedSearchRunner struct {
+	repo          repository.SavedSearchRepository
+	notifications repository.NotificationRepository
+	fidiboSearch  fidibosearch.FidiboSearcher
+	publisher     WebhookPublisher
+	logger        *slog.Logger
+}
+
+// Start runs the saved searches every interval until ctx is done. Only the instance that takes the run lock
+// runs them in an interval, so running several instances does not multiply upstream calls.
+func (r *savedSearchRunner) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lockTTL := interval * 9 / 10
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		token, err := newID()
+		if err != nil {
+			r.logger.ErrorContext(ctx, "could not create run lock token", "error", err)
+			continue
+		}
+		acquired, err := r.repo.AcquireRunLock(ctx, token, lockTTL)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "could not acquire run lock", "error", err)
+			continue
+		}
+		if !acquired {
+			continue
+		}
+
+		err = r.runLocked(ctx, token, lockTTL)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "run failed", "error", err)
+		}
+	}
+}
+
+// runLocked runs the saved searches and keeps renewing the run lock until they are done, so that a run that
+// takes longer than the interval does not overlap with one on another instance. The run stops if the lock is
+// lost.
+func (r *savedSearchRunner) runLocked(ctx context.Context, token string, ttl time.Duration) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	renewing := make(chan struct{})
+	go func() {
+		defer close(renewing)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			renewed, err := r.repo.RenewRunLock(runCtx, token, ttl)
+			if err != nil {
+				if runCtx.Err() == nil {
+					r.logger.WarnContext(ctx, "could not renew run lock", "error", err)
+				}
+				continue
+			}
+			if !renewed {
+				r.logger.WarnContext(ctx, "run lock lost, stopping the run")
+				cancel()
+				return
+			}
+		}
+	}()
+
+	err := r.RunOnce(runCtx)
+	cancel()
+	<-renewing
+	return err
+}
+
+func (r *savedSearchRunner) RunOnce(ctx context.Context) error {
+	searches, err := r.repo.All(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, search := range searches {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err := r.check(ctx, search)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "could not check saved search", "saved_search_id", search.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// check re-runs a saved search and records the books it has not found recently. Books are told apart by their
+// IDs rather than their place in the results, so a book whose date moves it in and out of the first page is not
+// reported again. The first run only records the books, since every book would be new. The search has
+// background priority so that it does not take the upstream quota from users.
+func (r *savedSearchRunner) check(ctx context.Context, search domain.SavedSearch) error {
+	res, err := r.fidiboSearch.Search(fidibosearch.WithPriority(ctx, fidibosearch.PriorityBackground), domain.SearchRequest{
+		Keyword: search.Keyword,
+		Page:    1,
+		Size:    savedSearchResultSize,
+		Sort:    domain.SortDate,
+	})
+	if err != nil {
+		return err
+	}
+
+	previous, ok, err := r.repo.SeenBooks(ctx, search)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(previous))
+	for _, id := range previous {
+		seen[id] = true
+	}
+
+	ids := make([]string, 0, len(res.Books))
+	newBooks := []domain.Book{}
+	for _, book := range res.Books {
+		ids = append(ids, book.ID)
+		if ok && !seen[book.ID] {
+			newBooks = append(newBooks, book)
+		}
+	}
+
+	if len(newBooks) > 0 {
+		err := r.notify(ctx, search, newBooks)
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.repo.AddSeenBooks(ctx, search, ids, time.Now())
+}
+
+func (r *savedSearchRunner) notify(ctx context.Context, search domain.SavedSearch, books []domain.Book) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	notification := domain.Notification{
+		ID:            id,
+		SavedSearchID: search.ID,
+		Name:          search.Name,
+		Keyword:       search.Keyword,
+		NewBooks:      books,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	err = r.notifications.Add(ctx, search.Username, notification)
+	if err != nil {
+		return err
+	}
+
+	err = r.publisher.Publish(ctx, domain.EventSavedSearchNewResults, search.Username, domain.SavedSearchResultsEvent{
+		Username:     search.Username,
+		Notification: notification,
+	})
+	if err != nil {
+		r.logger.WarnContext(ctx, "could not publish saved search results", "saved_search_id", search.ID, "error", err)
+	}
+
+	return nil
+}
+
+func NewSavedSearchRunner(repo repository.SavedSearchRepository,
+	notifications repository.NotificationRepository,
+	fidiboSearch fidibosearch.FidiboSearcher,
+	publisher WebhookPublisher,
+	logger *slog.Logger) SavedSearchRunner {
+	return &savedSearchRunner{
+		repo:          repo,
+		notifications: notifications,
+		fidiboSearch:  fidiboSearch,
+		publisher:     publisher,
+		logger:        logger.With("component", "saved_search_runner"),
+	}
+}
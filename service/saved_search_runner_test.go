@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch"
+	fidiboMock "github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch/mocks"
+	repositoryMock "github.com/kavehjamshidi/fidibo-challenge/repository/mocks"
+	"github.com/kavehjamshidi/fidibo-challenge/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSavedSearchRunner(t *testing.T) {
+	search := domain.SavedSearch{ID: "abc", Username: "test", Name: "Kafka", Keyword: "kafka"}
+	req := domain.SearchRequest{Keyword: "kafka", Page: 1, Size: 50, Sort: domain.SortDate}
+	result := domain.SearchResult{Books: []domain.Book{{ID: "3"}, {ID: "2"}, {ID: "1"}}}
+	background := fidibosearch.WithPriority(context.TODO(), fidibosearch.PriorityBackground)
+
+	t.Run("records and publishes new books", func(t *testing.T) {
+		repo := &repositoryMock.SavedSearchRepository{}
+		notifications := &repositoryMock.NotificationRepository{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		publisher := &mocks.WebhookPublisher{}
+
+		isExpected := mock.MatchedBy(func(n domain.Notification) bool {
+			return n.SavedSearchID == "abc" && n.Name == "Kafka" && assert.ObjectsAreEqual([]domain.Book{{ID: "3"}}, n.NewBooks)
+		})
+
+		repo.On("All", context.TODO()).Return([]domain.SavedSearch{search}, nil)
+		fidiboClient.On("Search", background, req).Return(result, nil)
+		repo.On("SeenBooks", context.TODO(), search).Return([]string{"1", "2"}, true, nil)
+		notifications.On("Add", context.TODO(), "test", isExpected).Return(nil)
+		publisher.On("Publish", context.TODO(), domain.EventSavedSearchNewResults, "test", mock.MatchedBy(func(event domain.SavedSearchResultsEvent) bool {
+			return event.Username == "test" && event.Notification.SavedSearchID == "abc"
+		})).Return(errors.New("redis error"))
+		repo.On("AddSeenBooks", context.TODO(), search, []string{"3", "2", "1"}, mock.Anything).Return(nil)
+
+		runner := NewSavedSearchRunner(repo, notifications, fidiboClient, publisher, slog.Default())
+		err := runner.RunOnce(context.TODO())
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		notifications.AssertExpectations(t)
+		fidiboClient.AssertExpectations(t)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("first run only records the books", func(t *testing.T) {
+		repo := &repositoryMock.SavedSearchRepository{}
+		notifications := &repositoryMock.NotificationRepository{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		publisher := &mocks.WebhookPublisher{}
+
+		repo.On("All", context.TODO()).Return([]domain.SavedSearch{search}, nil)
+		fidiboClient.On("Search", background, req).Return(result, nil)
+		repo.On("SeenBooks", context.TODO(), search).Return([]string{}, false, nil)
+		repo.On("AddSeenBooks", context.TODO(), search, []string{"3", "2", "1"}, mock.Anything).Return(nil)
+
+		runner := NewSavedSearchRunner(repo, notifications, fidiboClient, publisher, slog.Default())
+		err := runner.RunOnce(context.TODO())
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		notifications.AssertExpectations(t)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("upstream errors skip the search", func(t *testing.T) {
+		other := domain.SavedSearch{ID: "def", Username: "test", Keyword: "camus"}
+		otherReq := domain.SearchRequest{Keyword: "camus", Page: 1, Size: 50, Sort: domain.SortDate}
+
+		repo := &repositoryMock.SavedSearchRepository{}
+		notifications := &repositoryMock.NotificationRepository{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		publisher := &mocks.WebhookPublisher{}
+
+		repo.On("All", context.TODO()).Return([]domain.SavedSearch{search, other}, nil)
+		fidiboClient.On("Search", background, req).Return(domain.SearchResult{}, errors.New("upstream error"))
+		fidiboClient.On("Search", background, otherReq).Return(domain.SearchResult{}, nil)
+		repo.On("SeenBooks", context.TODO(), other).Return([]string{"1"}, true, nil)
+		repo.On("AddSeenBooks", context.TODO(), other, []string{}, mock.Anything).Return(nil)
+
+		runner := NewSavedSearchRunner(repo, notifications, fidiboClient, publisher, slog.Default())
+		err := runner.RunOnce(context.TODO())
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		fidiboClient.AssertExpectations(t)
+	})
+}
+
+func TestSavedSearchRunnerLock(t *testing.T) {
+	search := domain.SavedSearch{ID: "abc", Username: "test", Keyword: "kafka"}
+	req := domain.SearchRequest{Keyword: "kafka", Page: 1, Size: 50, Sort: domain.SortDate}
+
+	t.Run("renews the run lock while running", func(t *testing.T) {
+		repo := &repositoryMock.SavedSearchRepository{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+
+		repo.On("All", mock.Anything).Return([]domain.SavedSearch{search}, nil)
+		repo.On("RenewRunLock", mock.Anything, "token", 30*time.Millisecond).Return(true, nil)
+		fidiboClient.On("Search", mock.Anything, req).Run(func(args mock.Arguments) {
+			time.Sleep(50 * time.Millisecond)
+		}).Return(domain.SearchResult{}, errors.New("upstream error"))
+
+		runner := NewSavedSearchRunner(repo, &repositoryMock.NotificationRepository{}, fidiboClient, &mocks.WebhookPublisher{}, slog.Default())
+		err := runner.(*savedSearchRunner).runLocked(context.TODO(), "token", 30*time.Millisecond)
+
+		assert.NoError(t, err)
+		repo.AssertCalled(t, "RenewRunLock", mock.Anything, "token", 30*time.Millisecond)
+	})
+
+	t.Run("a lost run lock stops the run", func(t *testing.T) {
+		repo := &repositoryMock.SavedSearchRepository{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+
+		repo.On("All", mock.Anything).Return([]domain.SavedSearch{search, search}, nil)
+		repo.On("RenewRunLock", mock.Anything, "token", 30*time.Millisecond).Return(false, nil)
+		fidiboClient.On("Search", mock.Anything, req).Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).Return(domain.SearchResult{}, context.Canceled).Once()
+
+		runner := NewSavedSearchRunner(repo, &repositoryMock.NotificationRepository{}, fidiboClient, &mocks.WebhookPublisher{}, slog.Default())
+		err := runner.(*savedSearchRunner).runLocked(context.TODO(), "token", 30*time.Millisecond)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		fidiboClient.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/webhook"
+	webhookMock "github.com/kavehjamshidi/fidibo-challenge/pkg/webhook/mocks"
+	repositoryMock "github.com/kavehjamshidi/fidibo-challenge/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateSavedSearch(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := &repositoryMock.SavedSearchRepository{}
+		repo.On("Create", context.TODO(), mock.MatchedBy(func(search domain.SavedSearch) bool {
+			return len(search.ID) == 16 && search.Username == "test" && search.Name == "kafka" && search.Keyword == "kafka"
+		})).Return(nil)
+
+		svc := NewSavedSearchService(repo, &repositoryMock.WebhookRepository{}, allowAll(), slog.Default())
+		search, err := svc.Create(context.TODO(), "test", domain.SavedSearchRequest{Keyword: "kafka"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "kafka", search.Name)
+		assert.False(t, search.CreatedAt.IsZero())
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("limit reached", func(t *testing.T) {
+		repo := &repositoryMock.SavedSearchRepository{}
+		repo.On("Create", context.TODO(), mock.Anything).Return(domain.ErrSavedSearchLimitReached)
+
+		svc := NewSavedSearchService(repo, &repositoryMock.WebhookRepository{}, allowAll(), slog.Default())
+		_, err := svc.Create(context.TODO(), "test", domain.SavedSearchRequest{Keyword: "kafka"})
+
+		assert.ErrorIs(t, err, domain.ErrSavedSearchLimitReached)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestUpdateSavedSearch(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		existing := domain.SavedSearch{ID: "abc", Username: "test", Name: "kafka", Keyword: "kafka"}
+		expected := domain.SavedSearch{ID: "abc", Username: "test", Name: "Camus", Keyword: "camus"}
+
+		repo := &repositoryMock.SavedSearchRepository{}
+		repo.On("Get", context.TODO(), "test", "abc").Return(existing, nil)
+		repo.On("Update", context.TODO(), expected).Return(nil)
+
+		svc := NewSavedSearchService(repo, &repositoryMock.WebhookRepository{}, allowAll(), slog.Default())
+		search, err := svc.Update(context.TODO(), "test", "abc", domain.SavedSearchRequest{Name: "Camus", Keyword: "camus"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, search)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repo := &repositoryMock.SavedSearchRepository{}
+		repo.On("Get", context.TODO(), "test", "abc").Return(domain.SavedSearch{}, domain.ErrSavedSearchNotFound)
+
+		svc := NewSavedSearchService(repo, &repositoryMock.WebhookRepository{}, allowAll(), slog.Default())
+		_, err := svc.Update(context.TODO(), "test", "abc", domain.SavedSearchRequest{Keyword: "camus"})
+
+		assert.ErrorIs(t, err, domain.ErrSavedSearchNotFound)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestListAndDeleteSavedSearches(t *testing.T) {
+	searches := []domain.SavedSearch{{ID: "abc", Keyword: "kafka"}}
+
+	repo := &repositoryMock.SavedSearchRepository{}
+	repo.On("List", context.TODO(), "test").Return(searches, nil)
+	repo.On("Get", context.TODO(), "test", "abc").Return(searches[0], nil)
+	repo.On("Delete", context.TODO(), "test", "abc").Return(nil)
+
+	svc := NewSavedSearchService(repo, &repositoryMock.WebhookRepository{}, allowAll(), slog.Default())
+
+	res, err := svc.List(context.TODO(), "test")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.SavedSearchesResponse{SavedSearches: searches}, res)
+
+	err = svc.Delete(context.TODO(), "test", "abc")
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestSavedSearchWebhook(t *testing.T) {
+	t.Run("create links a webhook", func(t *testing.T) {
+		repo := &repositoryMock.SavedSearchRepository{}
+		webhooks := &repositoryMock.WebhookRepository{}
+
+		var hook domain.Webhook
+		webhooks.On("Create", context.TODO(), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			hook = args.Get(1).(domain.Webhook)
+		})
+		repo.On("Create", context.TODO(), mock.MatchedBy(func(search domain.SavedSearch) bool {
+			return search.WebhookURL == "https://example.com/hook" && search.WebhookID != "" && search.WebhookSecret == ""
+		})).Return(nil)
+
+		svc := NewSavedSearchService(repo, webhooks, allowAll(), slog.Default())
+		search, err := svc.Create(context.TODO(), "test", domain.SavedSearchRequest{Keyword: "kafka", WebhookURL: "https://example.com/hook"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, hook.ID, search.WebhookID)
+		assert.Equal(t, hook.Secret, search.WebhookSecret)
+		assert.Len(t, hook.Secret, 2*webhookSecretSize)
+		assert.Equal(t, search.ID, hook.SavedSearchID)
+		assert.Equal(t, "test", hook.CreatedBy)
+		assert.Equal(t, []string{domain.EventSavedSearchNewResults}, hook.Events)
+		repo.AssertExpectations(t)
+		webhooks.AssertExpectations(t)
+	})
+
+	t.Run("create removes the webhook when the search is not stored", func(t *testing.T) {
+		repo := &repositoryMock.SavedSearchRepository{}
+		webhooks := &repositoryMock.WebhookRepository{}
+
+		webhooks.On("Create", context.TODO(), mock.Anything).Return(nil)
+		webhooks.On("Delete", context.TODO(), "test", mock.Anything).Return(nil)
+		repo.On("Create", context.TODO(), mock.Anything).Return(domain.ErrSavedSearchLimitReached)
+
+		svc := NewSavedSearchService(repo, webhooks, allowAll(), slog.Default())
+		_, err := svc.Create(context.TODO(), "test", domain.SavedSearchRequest{Keyword: "kafka", WebhookURL: "https://example.com/hook"})
+
+		assert.ErrorIs(t, err, domain.ErrSavedSearchLimitReached)
+		webhooks.AssertExpectations(t)
+	})
+
+	t.Run("URL not allowed", func(t *testing.T) {
+		repo := &repositoryMock.SavedSearchRepository{}
+		webhooks := &repositoryMock.WebhookRepository{}
+		guard := &webhookMock.Guard{}
+		guard.On("CheckURL", context.TODO(), "http://127.0.0.1/hook").Return(webhook.ErrAddressNotAllowed)
+
+		svc := NewSavedSearchService(repo, webhooks, guard, slog.Default())
+		_, err := svc.Create(context.TODO(), "test", domain.SavedSearchRequest{Keyword: "kafka", WebhookURL: "http://127.0.0.1/hook"})
+
+		assert.ErrorIs(t, err, domain.ErrWebhookURLNotAllowed)
+		repo.AssertExpectations(t)
+		webhooks.AssertExpectations(t)
+	})
+
+	t.Run("update changes the webhook URL", func(t *testing.T) {
+		existing := domain.SavedSearch{ID: "abc", Username: "test", Name: "kafka", Keyword: "kafka", WebhookURL: "https://example.com/old", WebhookID: "hook"}
+
+		repo := &repositoryMock.SavedSearchRepository{}
+		webhooks := &repositoryMock.WebhookRepository{}
+		repo.On("Get", context.TODO(), "test", "abc").Return(existing, nil)
+		webhooks.On("Get", context.TODO(), "hook").Return(domain.Webhook{ID: "hook", URL: "https://example.com/old", SavedSearchID: "abc"}, nil)
+		webhooks.On("Update", context.TODO(), domain.Webhook{ID: "hook", URL: "https://example.com/new", SavedSearchID: "abc"}).Return(nil)
+		repo.On("Update", context.TODO(), mock.MatchedBy(func(search domain.SavedSearch) bool {
+			return search.WebhookURL == "https://example.com/new" && search.WebhookID == "hook"
+		})).Return(nil)
+
+		svc := NewSavedSearchService(repo, webhooks, allowAll(), slog.Default())
+		search, err := svc.Update(context.TODO(), "test", "abc", domain.SavedSearchRequest{Keyword: "kafka", WebhookURL: "https://example.com/new"})
+
+		assert.NoError(t, err)
+		assert.Empty(t, search.WebhookSecret)
+		repo.AssertExpectations(t)
+		webhooks.AssertExpectations(t)
+	})
+
+	t.Run("update without a URL removes the webhook", func(t *testing.T) {
+		existing := domain.SavedSearch{ID: "abc", Username: "test", Name: "kafka", Keyword: "kafka", WebhookURL: "https://example.com/old", WebhookID: "hook"}
+
+		repo := &repositoryMock.SavedSearchRepository{}
+		webhooks := &repositoryMock.WebhookRepository{}
+		repo.On("Get", context.TODO(), "test", "abc").Return(existing, nil)
+		webhooks.On("Delete", context.TODO(), "test", "hook").Return(nil)
+		repo.On("Update", context.TODO(), domain.SavedSearch{ID: "abc", Username: "test", Name: "kafka", Keyword: "kafka"}).Return(nil)
+
+		svc := NewSavedSearchService(repo, webhooks, allowAll(), slog.Default())
+		_, err := svc.Update(context.TODO(), "test", "abc", domain.SavedSearchRequest{Keyword: "kafka"})
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		webhooks.AssertExpectations(t)
+	})
+
+	t.Run("delete removes the webhook", func(t *testing.T) {
+		repo := &repositoryMock.SavedSearchRepository{}
+		webhooks := &repositoryMock.WebhookRepository{}
+		repo.On("Get", context.TODO(), "test", "abc").Return(domain.SavedSearch{ID: "abc", WebhookID: "hook"}, nil)
+		repo.On("Delete", context.TODO(), "test", "abc").Return(nil)
+		webhooks.On("Delete", context.TODO(), "test", "hook").Return(domain.ErrWebhookNotFound)
+
+		svc := NewSavedSearchService(repo, webhooks, allowAll(), slog.Default())
+		err := svc.Delete(context.TODO(), "test", "abc")
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		webhooks.AssertExpectations(t)
+	})
+}
@@ -3,47 +3,279 @@ package service
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/kavehjamshidi/fidibo-challenge/cache"
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/spell"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/tracing"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/user"
 	"github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch"
+	"github.com/kavehjamshidi/fidibo-challenge/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	searchCacheKeyPrefix   = "search:"
+	filteredCacheKeyPrefix = "search:filtered:"
+
+	maxFilteredPages = 5
+	filteredPageSize = 50
 )
 
 type SearchService interface {
-	Search(ctx context.Context, query string) (domain.SearchResult, error)
+	Search(ctx context.Context, req domain.SearchRequest) (domain.SearchResult, error)
 }
 
 type searchService struct {
 	cache        cache.Cacher
 	fidiboSearch fidibosearch.FidiboSearcher
+	suggestions  repository.SuggestionRepository
+	books        cache.BookCacher
+	history      repository.HistoryRepository
+	logger       *slog.Logger
+}
+
+func (s *searchService) Search(ctx context.Context, req domain.SearchRequest) (domain.SearchResult, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SearchService.Search", trace.WithAttributes(
+		attribute.String("search.keyword", req.Keyword),
+		attribute.Int("search.page", req.Page),
+		attribute.Int("search.size", req.Size),
+	))
+
+	res, err := s.search(ctx, req)
+	if err != nil {
+		tracing.End(span, err)
+		return domain.SearchResult{}, err
+	}
+
+	s.recordHistory(ctx, req, res)
+	span.End()
+
+	return res, nil
 }
 
-func (s *searchService) Search(ctx context.Context, query string) (domain.SearchResult, error) {
-	cachedRes, err := s.cache.Get(ctx, query)
+func (s *searchService) search(ctx context.Context, req domain.SearchRequest) (domain.SearchResult, error) {
+	if hasLocalFilters(req) {
+		return s.searchFiltered(ctx, req)
+	}
+
+	key := searchCacheKey(req)
+
+	cachedRes, err := s.cache.Get(ctx, key)
 	if err == nil {
 		return cachedRes, nil
 	} else {
-		log.Printf("Fidibo Search Cache Retreival Error: %v\n", err)
+		s.logger.DebugContext(ctx, "search cache miss", "error", err)
 	}
 
-	fidiboRes, err := s.fidiboSearch.Search(ctx, query)
+	fidiboRes, err := s.fidiboSearch.Search(ctx, req)
 	if err != nil {
-		log.Printf("Fidibo Search Error: %v\n", err)
-		return domain.SearchResult{}, fmt.Errorf("service unavailable: %v", err)
+		s.logger.ErrorContext(ctx, "fidibo search failed", "error", err)
+		return domain.SearchResult{}, fmt.Errorf("service unavailable: %w", err)
 	}
 
-	err = s.cache.Store(ctx, query, fidiboRes)
+	err = s.suggestions.Index(ctx, fidiboRes.Books)
 	if err != nil {
-		log.Printf("Fidibo Search Cache Store Error: %v\n", err)
+		s.logger.WarnContext(ctx, "could not index suggestions", "error", err)
+	}
+
+	if len(fidiboRes.Books) == 0 && fidiboRes.Total == 0 && req.Keyword != "" {
+		fidiboRes = s.retryWithCorrection(ctx, req, fidiboRes)
+	}
+
+	err = s.books.Store(ctx, fidiboRes.Books)
+	if err != nil {
+		s.logger.WarnContext(ctx, "could not store books in cache", "error", err)
+	}
+
+	sortBooks(req.Sort, fidiboRes.Books)
+
+	err = s.cache.Store(ctx, key, fidiboRes)
+	if err != nil {
+		s.logger.WarnContext(ctx, "could not store search result in cache", "error", err)
 	}
 
 	return fidiboRes, nil
 }
 
-func NewSearchService(cache cache.Cacher, fidiboSearch fidibosearch.FidiboSearcher) SearchService {
+// searchFiltered serves a search with filters Fidibo cannot apply. The first pages of the keyword are collected
+// and filtered once and the pages are served from the filtered list, so that total and has_more count the books
+// that match and pages stay stable between requests.
+func (s *searchService) searchFiltered(ctx context.Context, req domain.SearchRequest) (domain.SearchResult, error) {
+	key := filteredCacheKey(req)
+
+	all, err := s.cache.Get(ctx, key)
+	if err != nil {
+		s.logger.DebugContext(ctx, "filtered search cache miss", "error", err)
+
+		all, err = s.collectFiltered(ctx, req)
+		if err != nil {
+			return domain.SearchResult{}, err
+		}
+
+		err = s.cache.Store(ctx, key, all)
+		if err != nil {
+			s.logger.WarnContext(ctx, "could not store filtered search result in cache", "error", err)
+		}
+	}
+
+	return paginate(all, req.Page, req.Size), nil
+}
+
+func (s *searchService) collectFiltered(ctx context.Context, req domain.SearchRequest) (domain.SearchResult, error) {
+	all := domain.SearchResult{Books: []domain.Book{}}
+	seen := map[string]bool{}
+
+	for page := 1; page <= maxFilteredPages; page++ {
+		pageReq := req
+		pageReq.Page, pageReq.Size = page, filteredPageSize
+
+		res, err := s.fidiboSearch.Search(ctx, pageReq)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "fidibo search failed", "page", page, "error", err)
+			return domain.SearchResult{}, fmt.Errorf("service unavailable: %w", err)
+		}
+
+		err = s.suggestions.Index(ctx, res.Books)
+		if err != nil {
+			s.logger.WarnContext(ctx, "could not index suggestions", "error", err)
+		}
+		err = s.books.Store(ctx, res.Books)
+		if err != nil {
+			s.logger.WarnContext(ctx, "could not store books in cache", "error", err)
+		}
+
+		for _, book := range filterBooks(req, res.Books) {
+			if seen[book.ID] {
+				continue
+			}
+			seen[book.ID] = true
+			all.Books = append(all.Books, book)
+		}
+		all.FetchedAt = res.FetchedAt
+
+		if !res.HasMore {
+			break
+		}
+	}
+
+	sortBooks(req.Sort, all.Books)
+	all.Total = int64(len(all.Books))
+	return all, nil
+}
+
+func (s *searchService) recordHistory(ctx context.Context, req domain.SearchRequest, res domain.SearchResult) {
+	username, ok := user.Username(ctx)
+	if !ok {
+		return
+	}
+
+	enabled, err := s.history.Enabled(ctx, username)
+	if err != nil {
+		s.logger.WarnContext(ctx, "could not get search history preference", "error", err)
+		return
+	}
+	if !enabled {
+		return
+	}
+
+	err = s.history.Add(ctx, username, domain.SearchHistoryEntry{
+		Query:       req.Keyword,
+		ResultCount: res.Total,
+		SearchedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "could not store search history", "error", err)
+	}
+}
+
+// retryWithCorrection searches again with the most likely intended keyword when the original one found
+// nothing. The original result is kept unless the corrected keyword finds books.
+func (s *searchService) retryWithCorrection(ctx context.Context, req domain.SearchRequest, res domain.SearchResult) domain.SearchResult {
+	vocabulary, err := s.suggestions.Vocabulary(ctx)
+	if err != nil {
+		s.logger.WarnContext(ctx, "could not get suggestion vocabulary", "error", err)
+		return res
+	}
+
+	corrected, ok := spell.NewCorrector(vocabulary).Correct(req.Keyword)
+	if !ok {
+		return res
+	}
+
+	correctedReq := req
+	correctedReq.Keyword = corrected
+	correctedRes, err := s.fidiboSearch.Search(ctx, correctedReq)
+	if err != nil {
+		s.logger.WarnContext(ctx, "fidibo search with corrected keyword failed", "keyword", corrected, "error", err)
+		return res
+	}
+	if len(correctedRes.Books) == 0 {
+		return res
+	}
+
+	correctedRes.DidYouMean = corrected
+	return correctedRes
+}
+
+// hasLocalFilters reports whether req filters on fields that are not forwarded to Fidibo.
+func hasLocalFilters(req domain.SearchRequest) bool {
+	return req.Author != "" || req.Publisher != "" || req.Language != "" || req.MinPrice != nil || req.MaxPrice != nil
+}
+
+func searchCacheKey(req domain.SearchRequest) string {
+	values := searchQuery(req)
+	values.Set("page", strconv.Itoa(req.Page))
+	values.Set("size", strconv.Itoa(req.Size))
+	return searchCacheKeyPrefix + values.Encode()
+}
+
+func filteredCacheKey(req domain.SearchRequest) string {
+	return filteredCacheKeyPrefix + searchQuery(req).Encode()
+}
+
+func searchQuery(req domain.SearchRequest) url.Values {
+	values := url.Values{}
+	values.Set("keyword", req.Keyword)
+	setIfNotEmpty(values, "author", req.Author)
+	setIfNotEmpty(values, "publisher", req.Publisher)
+	setIfNotEmpty(values, "format", req.Format)
+	setIfNotEmpty(values, "language", req.Language)
+	if req.Sort != domain.SortRelevance {
+		setIfNotEmpty(values, "sort", req.Sort)
+	}
+	if req.MinPrice != nil {
+		values.Set("min_price", strconv.FormatFloat(*req.MinPrice, 'f', -1, 64))
+	}
+	if req.MaxPrice != nil {
+		values.Set("max_price", strconv.FormatFloat(*req.MaxPrice, 'f', -1, 64))
+	}
+	return values
+}
+
+func setIfNotEmpty(values url.Values, key, value string) {
+	if value != "" {
+		values.Set(key, value)
+	}
+}
+
+func NewSearchService(cache cache.Cacher,
+	fidiboSearch fidibosearch.FidiboSearcher,
+	suggestions repository.SuggestionRepository,
+	books cache.BookCacher,
+	history repository.HistoryRepository,
+	logger *slog.Logger) SearchService {
 	return &searchService{
 		cache:        cache,
 		fidiboSearch: fidiboSearch,
+		suggestions:  suggestions,
+		books:        books,
+		history:      history,
+		logger:       logger.With("component", "search"),
 	}
 }
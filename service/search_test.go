@@ -3,21 +3,29 @@ package service
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"testing"
 
 	cacheMock "github.com/kavehjamshidi/fidibo-challenge/cache/mocks"
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/user"
 	fidiboMock "github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch/mocks"
+	repositoryMock "github.com/kavehjamshidi/fidibo-challenge/repository/mocks"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestSearch(t *testing.T) {
 	t.Run("successful cache hit", func(t *testing.T) {
-		query := "test"
+		req := domain.SearchRequest{Keyword: "test", Page: 1, Size: 20}
+		key := "search:keyword=test&page=1&size=20"
 
 		cache := &cacheMock.Cacher{}
 		fidiboClient := &fidiboMock.FidiboSearcher{}
+		suggestions := &repositoryMock.SuggestionRepository{}
+		books := &cacheMock.BookCacher{}
+		history := &repositoryMock.HistoryRepository{}
 
 		expectedResult := domain.SearchResult{
 			Books: []domain.Book{
@@ -37,10 +45,10 @@ func TestSearch(t *testing.T) {
 			},
 		}
 
-		cache.On("Get", context.TODO(), query).Return(expectedResult, nil)
+		cache.On("Get", mock.Anything, key).Return(expectedResult, nil)
 
-		svc := NewSearchService(cache, fidiboClient)
-		result, err := svc.Search(context.TODO(), query)
+		svc := NewSearchService(cache, fidiboClient, suggestions, books, history, slog.Default())
+		result, err := svc.Search(context.TODO(), req)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedResult, result)
@@ -50,10 +58,14 @@ func TestSearch(t *testing.T) {
 	})
 
 	t.Run("cache miss, get response from http client and stored on cache", func(t *testing.T) {
-		query := "test"
+		req := domain.SearchRequest{Keyword: "test", Page: 1, Size: 20}
+		key := "search:keyword=test&page=1&size=20"
 
 		cache := &cacheMock.Cacher{}
 		fidiboClient := &fidiboMock.FidiboSearcher{}
+		suggestions := &repositoryMock.SuggestionRepository{}
+		books := &cacheMock.BookCacher{}
+		history := &repositoryMock.HistoryRepository{}
 
 		expectedResult := domain.SearchResult{
 			Books: []domain.Book{
@@ -73,39 +85,273 @@ func TestSearch(t *testing.T) {
 			},
 		}
 
-		cache.On("Get", context.TODO(), query).Return(domain.SearchResult{}, redis.Nil)
-		fidiboClient.On("Search", context.TODO(), query).Return(expectedResult, nil)
-		cache.On("Store", context.TODO(), query, expectedResult).Return(nil)
+		cache.On("Get", mock.Anything, key).Return(domain.SearchResult{}, redis.Nil)
+		fidiboClient.On("Search", mock.Anything, req).Return(expectedResult, nil)
+		suggestions.On("Index", mock.Anything, expectedResult.Books).Return(nil)
+		books.On("Store", mock.Anything, expectedResult.Books).Return(nil)
+		cache.On("Store", mock.Anything, key, expectedResult).Return(nil)
 
-		svc := NewSearchService(cache, fidiboClient)
-		result, err := svc.Search(context.TODO(), query)
+		svc := NewSearchService(cache, fidiboClient, suggestions, books, history, slog.Default())
+		result, err := svc.Search(context.TODO(), req)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedResult, result)
 
 		cache.AssertExpectations(t)
 		fidiboClient.AssertExpectations(t)
+		suggestions.AssertExpectations(t)
+		books.AssertExpectations(t)
 	})
 
 	t.Run("cache miss, http client error", func(t *testing.T) {
-		query := "test"
+		req := domain.SearchRequest{Keyword: "test", Page: 1, Size: 20}
+		key := "search:keyword=test&page=1&size=20"
 
 		cache := &cacheMock.Cacher{}
 		fidiboClient := &fidiboMock.FidiboSearcher{}
+		suggestions := &repositoryMock.SuggestionRepository{}
+		books := &cacheMock.BookCacher{}
+		history := &repositoryMock.HistoryRepository{}
 
 		expectedResult := domain.SearchResult{}
 		errorMsg := "internal server error"
 
-		cache.On("Get", context.TODO(), query).Return(domain.SearchResult{}, redis.Nil)
-		fidiboClient.On("Search", context.TODO(), query).Return(domain.SearchResult{}, errors.New(errorMsg))
+		cache.On("Get", mock.Anything, key).Return(domain.SearchResult{}, redis.Nil)
+		fidiboClient.On("Search", mock.Anything, req).Return(domain.SearchResult{}, errors.New(errorMsg))
 
-		svc := NewSearchService(cache, fidiboClient)
-		result, err := svc.Search(context.TODO(), query)
+		svc := NewSearchService(cache, fidiboClient, suggestions, books, history, slog.Default())
+		result, err := svc.Search(context.TODO(), req)
 
 		assert.Error(t, err)
 		assert.ErrorContains(t, err, errorMsg)
 		assert.Equal(t, expectedResult, result)
 		cache.AssertExpectations(t)
 		fidiboClient.AssertExpectations(t)
+		suggestions.AssertExpectations(t)
+		books.AssertExpectations(t)
+	})
+	t.Run("filters are applied to the collected results", func(t *testing.T) {
+		maxPrice := 100000.0
+		req := domain.SearchRequest{Keyword: "test", Page: 1, Size: 1, Author: "kafka", MaxPrice: &maxPrice, Sort: domain.SortTitle}
+		key := "search:filtered:author=kafka&keyword=test&max_price=100000&sort=title"
+
+		cache := &cacheMock.Cacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		suggestions := &repositoryMock.SuggestionRepository{}
+		books := &cacheMock.BookCacher{}
+		history := &repositoryMock.HistoryRepository{}
+
+		cheap, expensive := 50000.0, 150000.0
+		firstPage := domain.SearchResult{
+			Books: []domain.Book{
+				{ID: "1", Title: "The Trial", Price: &cheap, Authors: []domain.Author{{Name: "Franz Kafka"}}},
+				{ID: "2", Title: "The Castle", Price: &expensive, Authors: []domain.Author{{Name: "Franz Kafka"}}},
+			},
+			Total:   4,
+			HasMore: true,
+		}
+		secondPage := domain.SearchResult{
+			Books: []domain.Book{
+				{ID: "3", Title: "The Stranger", Price: &cheap, Authors: []domain.Author{{Name: "Albert Camus"}}},
+				{ID: "4", Title: "Amerika", Price: &cheap, Authors: []domain.Author{{Name: "Franz Kafka"}}},
+			},
+			Total: 4,
+		}
+		collected := domain.SearchResult{
+			Books: []domain.Book{secondPage.Books[1], firstPage.Books[0]},
+			Total: 2,
+		}
+		expectedResult := domain.SearchResult{
+			Books:   []domain.Book{secondPage.Books[1]},
+			Total:   2,
+			Page:    1,
+			Size:    1,
+			HasMore: true,
+		}
+
+		firstReq, secondReq := req, req
+		firstReq.Page, firstReq.Size = 1, filteredPageSize
+		secondReq.Page, secondReq.Size = 2, filteredPageSize
+
+		cache.On("Get", mock.Anything, key).Return(domain.SearchResult{}, redis.Nil)
+		fidiboClient.On("Search", mock.Anything, firstReq).Return(firstPage, nil)
+		fidiboClient.On("Search", mock.Anything, secondReq).Return(secondPage, nil)
+		suggestions.On("Index", mock.Anything, mock.Anything).Return(errors.New("redis error"))
+		books.On("Store", mock.Anything, mock.Anything).Return(errors.New("redis error"))
+		cache.On("Store", mock.Anything, key, collected).Return(nil)
+
+		svc := NewSearchService(cache, fidiboClient, suggestions, books, history, slog.Default())
+		result, err := svc.Search(context.TODO(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedResult, result)
+		cache.AssertExpectations(t)
+		fidiboClient.AssertExpectations(t)
+	})
+	t.Run("filtered pages are served from the cached results", func(t *testing.T) {
+		req := domain.SearchRequest{Keyword: "test", Page: 2, Size: 1, Language: "fa"}
+		key := "search:filtered:keyword=test&language=fa"
+
+		cache := &cacheMock.Cacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		suggestions := &repositoryMock.SuggestionRepository{}
+		books := &cacheMock.BookCacher{}
+		history := &repositoryMock.HistoryRepository{}
+
+		collected := domain.SearchResult{
+			Books: []domain.Book{{ID: "1", Language: "fa"}, {ID: "2", Language: "fa"}},
+			Total: 2,
+		}
+		cache.On("Get", mock.Anything, key).Return(collected, nil)
+
+		svc := NewSearchService(cache, fidiboClient, suggestions, books, history, slog.Default())
+		result, err := svc.Search(context.TODO(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.SearchResult{Books: []domain.Book{{ID: "2", Language: "fa"}}, Total: 2, Page: 2, Size: 1}, result)
+		fidiboClient.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+	t.Run("no results, retried with corrected keyword", func(t *testing.T) {
+		req := domain.SearchRequest{Keyword: "بوف کوز", Page: 1, Size: 20}
+		correctedReq := domain.SearchRequest{Keyword: "بوف کور", Page: 1, Size: 20}
+		key := searchCacheKey(req)
+
+		cache := &cacheMock.Cacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		suggestions := &repositoryMock.SuggestionRepository{}
+		books := &cacheMock.BookCacher{}
+		history := &repositoryMock.HistoryRepository{}
+
+		emptyResult := domain.SearchResult{Books: []domain.Book{}, Page: 1, Size: 20}
+		correctedResult := domain.SearchResult{
+			Books: []domain.Book{{ID: "1", Title: "بوف کور"}},
+			Total: 1,
+			Page:  1,
+			Size:  20,
+		}
+		expectedResult := correctedResult
+		expectedResult.DidYouMean = "بوف کور"
+
+		cache.On("Get", mock.Anything, key).Return(domain.SearchResult{}, redis.Nil)
+		fidiboClient.On("Search", mock.Anything, req).Return(emptyResult, nil)
+		suggestions.On("Index", mock.Anything, emptyResult.Books).Return(nil)
+		suggestions.On("Vocabulary", mock.Anything).Return(map[string]float64{"بوف": 1, "کور": 1}, nil)
+		fidiboClient.On("Search", mock.Anything, correctedReq).Return(correctedResult, nil)
+		books.On("Store", mock.Anything, correctedResult.Books).Return(nil)
+		cache.On("Store", mock.Anything, key, expectedResult).Return(nil)
+
+		svc := NewSearchService(cache, fidiboClient, suggestions, books, history, slog.Default())
+		result, err := svc.Search(context.TODO(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedResult, result)
+		cache.AssertExpectations(t)
+		fidiboClient.AssertExpectations(t)
+		suggestions.AssertExpectations(t)
+		books.AssertExpectations(t)
+	})
+
+	t.Run("no results and no correction", func(t *testing.T) {
+		req := domain.SearchRequest{Keyword: "xyzxyz", Page: 1, Size: 20}
+		key := searchCacheKey(req)
+
+		cache := &cacheMock.Cacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		suggestions := &repositoryMock.SuggestionRepository{}
+		books := &cacheMock.BookCacher{}
+		history := &repositoryMock.HistoryRepository{}
+
+		emptyResult := domain.SearchResult{Books: []domain.Book{}, Page: 1, Size: 20}
+
+		cache.On("Get", mock.Anything, key).Return(domain.SearchResult{}, redis.Nil)
+		fidiboClient.On("Search", mock.Anything, req).Return(emptyResult, nil).Once()
+		suggestions.On("Index", mock.Anything, emptyResult.Books).Return(nil)
+		suggestions.On("Vocabulary", mock.Anything).Return(map[string]float64{"بوف": 1}, nil)
+		books.On("Store", mock.Anything, emptyResult.Books).Return(nil)
+		cache.On("Store", mock.Anything, key, emptyResult).Return(nil)
+
+		svc := NewSearchService(cache, fidiboClient, suggestions, books, history, slog.Default())
+		result, err := svc.Search(context.TODO(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, emptyResult, result)
+		cache.AssertExpectations(t)
+		fidiboClient.AssertExpectations(t)
+		suggestions.AssertExpectations(t)
+		books.AssertExpectations(t)
+	})
+	t.Run("page past the last result is not corrected", func(t *testing.T) {
+		req := domain.SearchRequest{Keyword: "kafka", Page: 9, Size: 20}
+		key := searchCacheKey(req)
+
+		cache := &cacheMock.Cacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		suggestions := &repositoryMock.SuggestionRepository{}
+		books := &cacheMock.BookCacher{}
+		history := &repositoryMock.HistoryRepository{}
+
+		emptyPage := domain.SearchResult{Books: []domain.Book{}, Total: 30, Page: 9, Size: 20}
+
+		cache.On("Get", mock.Anything, key).Return(domain.SearchResult{}, redis.Nil)
+		fidiboClient.On("Search", mock.Anything, req).Return(emptyPage, nil).Once()
+		suggestions.On("Index", mock.Anything, emptyPage.Books).Return(nil)
+		books.On("Store", mock.Anything, emptyPage.Books).Return(nil)
+		cache.On("Store", mock.Anything, key, emptyPage).Return(nil)
+
+		svc := NewSearchService(cache, fidiboClient, suggestions, books, history, slog.Default())
+		result, err := svc.Search(context.TODO(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, emptyPage, result)
+		fidiboClient.AssertExpectations(t)
+		suggestions.AssertExpectations(t)
+	})
+	t.Run("search is recorded in the user's history", func(t *testing.T) {
+		req := domain.SearchRequest{Keyword: "test", Page: 1, Size: 20}
+		key := "search:keyword=test&page=1&size=20"
+		ctx := user.WithUsername(context.TODO(), "test user")
+
+		cache := &cacheMock.Cacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		suggestions := &repositoryMock.SuggestionRepository{}
+		books := &cacheMock.BookCacher{}
+		history := &repositoryMock.HistoryRepository{}
+
+		cachedResult := domain.SearchResult{Books: []domain.Book{{ID: "1"}}, Total: 7}
+
+		cache.On("Get", mock.Anything, key).Return(cachedResult, nil)
+		history.On("Enabled", mock.Anything, "test user").Return(true, nil)
+		history.On("Add", mock.Anything, "test user", mock.MatchedBy(func(entry domain.SearchHistoryEntry) bool {
+			return entry.Query == "test" && entry.ResultCount == 7 && !entry.SearchedAt.IsZero()
+		})).Return(errors.New("redis error"))
+
+		svc := NewSearchService(cache, fidiboClient, suggestions, books, history, slog.Default())
+		result, err := svc.Search(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, cachedResult, result)
+		cache.AssertExpectations(t)
+		history.AssertExpectations(t)
+	})
+
+	t.Run("search is not recorded when the user opted out", func(t *testing.T) {
+		req := domain.SearchRequest{Keyword: "test", Page: 1, Size: 20}
+		key := "search:keyword=test&page=1&size=20"
+		ctx := user.WithUsername(context.TODO(), "test user")
+
+		cache := &cacheMock.Cacher{}
+		fidiboClient := &fidiboMock.FidiboSearcher{}
+		suggestions := &repositoryMock.SuggestionRepository{}
+		books := &cacheMock.BookCacher{}
+		history := &repositoryMock.HistoryRepository{}
+
+		cache.On("Get", mock.Anything, key).Return(domain.SearchResult{Total: 7}, nil)
+		history.On("Enabled", mock.Anything, "test user").Return(false, nil)
+
+		svc := NewSearchService(cache, fidiboClient, suggestions, books, history, slog.Default())
+		_, err := svc.Search(ctx, req)
+
+		assert.NoError(t, err)
+		history.AssertExpectations(t)
 	})
 }
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/normalize"
+	"github.com/kavehjamshidi/fidibo-challenge/repository"
+)
+
+const maxCachedPrefixes = 10000
+
+type SuggestService interface {
+	Suggest(ctx context.Context, req domain.SuggestRequest) (domain.SuggestResponse, error)
+}
+
+type cachedSuggestions struct {
+	suggestions []domain.Suggestion
+	expiresAt   time.Time
+}
+
+type suggestService struct {
+	repo     repository.SuggestionRepository
+	budget   time.Duration
+	cacheTTL *live.Duration
+	now      func() time.Time
+
+	mu     sync.RWMutex
+	cache  map[string]cachedSuggestions
+	logger *slog.Logger
+}
+
+func (s *suggestService) Suggest(ctx context.Context, req domain.SuggestRequest) (domain.SuggestResponse, error) {
+	key := strings.ToLower(normalize.Query(req.Prefix)) + "|" + strconv.Itoa(req.Limit)
+
+	if suggestions, ok := s.cached(key); ok {
+		return domain.SuggestResponse{Suggestions: suggestions}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.budget)
+	defer cancel()
+
+	suggestions, err := s.repo.Suggest(ctx, req.Prefix, req.Limit)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			s.logger.WarnContext(ctx, "suggestion budget exceeded", "budget", s.budget, "prefix", req.Prefix)
+			return domain.SuggestResponse{Suggestions: []domain.Suggestion{}}, nil
+		}
+		s.logger.ErrorContext(ctx, "could not get suggestions", "error", err)
+		return domain.SuggestResponse{}, err
+	}
+
+	s.store(key, suggestions)
+
+	return domain.SuggestResponse{Suggestions: suggestions}, nil
+}
+
+func (s *suggestService) cached(key string) ([]domain.Suggestion, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.cache[key]
+	if !ok || s.now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.suggestions, true
+}
+
+func (s *suggestService) store(key string, suggestions []domain.Suggestion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.cache) >= maxCachedPrefixes {
+		s.cache = make(map[string]cachedSuggestions, maxCachedPrefixes)
+	}
+	s.cache[key] = cachedSuggestions{
+		suggestions: suggestions,
+		expiresAt:   s.now().Add(s.cacheTTL.Load()),
+	}
+}
+
+func NewSuggestService(repo repository.SuggestionRepository, budget time.Duration, cacheTTL *live.Duration, logger *slog.Logger) SuggestService {
+	return &suggestService{
+		repo:     repo,
+		budget:   budget,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+		cache:    make(map[string]cachedSuggestions),
+		logger:   logger.With("component", "suggest"),
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	repositoryMock "github.com/kavehjamshidi/fidibo-challenge/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSuggest(t *testing.T) {
+	t.Run("success and per-prefix cache", func(t *testing.T) {
+		req := domain.SuggestRequest{Prefix: "کاف", Limit: 10}
+		expected := []domain.Suggestion{{Text: "کافکا", Type: domain.SuggestionTypeAuthor}}
+
+		repo := &repositoryMock.SuggestionRepository{}
+		repo.On("Suggest", mock.Anything, req.Prefix, req.Limit).Return(expected, nil).Once()
+
+		svc := NewSuggestService(repo, 10*time.Millisecond, live.NewDuration(time.Minute), slog.Default())
+
+		res, err := svc.Suggest(context.TODO(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res.Suggestions)
+
+		res, err = svc.Suggest(context.TODO(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res.Suggestions)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("expired cache entry is refreshed", func(t *testing.T) {
+		req := domain.SuggestRequest{Prefix: "ka", Limit: 10}
+		now := time.Now()
+
+		repo := &repositoryMock.SuggestionRepository{}
+		repo.On("Suggest", mock.Anything, req.Prefix, req.Limit).Return([]domain.Suggestion{}, nil).Twice()
+
+		svc := NewSuggestService(repo, 10*time.Millisecond, live.NewDuration(time.Minute), slog.Default()).(*suggestService)
+		svc.now = func() time.Time { return now }
+
+		_, err := svc.Suggest(context.TODO(), req)
+		assert.NoError(t, err)
+
+		now = now.Add(2 * time.Minute)
+		_, err = svc.Suggest(context.TODO(), req)
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("budget exceeded returns no suggestions", func(t *testing.T) {
+		req := domain.SuggestRequest{Prefix: "ka", Limit: 10}
+
+		repo := &repositoryMock.SuggestionRepository{}
+		repo.On("Suggest", mock.Anything, req.Prefix, req.Limit).Return(nil, context.DeadlineExceeded)
+
+		svc := NewSuggestService(repo, time.Millisecond, live.NewDuration(time.Minute), slog.Default())
+
+		res, err := svc.Suggest(context.TODO(), req)
+		assert.NoError(t, err)
+		assert.Empty(t, res.Suggestions)
+		assert.NotNil(t, res.Suggestions)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		req := domain.SuggestRequest{Prefix: "ka", Limit: 10}
+		errorMsg := "redis error"
+
+		repo := &repositoryMock.SuggestionRepository{}
+		repo.On("Suggest", mock.Anything, req.Prefix, req.Limit).Return(nil, errors.New(errorMsg))
+
+		svc := NewSuggestService(repo, 10*time.Millisecond, live.NewDuration(time.Minute), slog.Default())
+
+		_, err := svc.Suggest(context.TODO(), req)
+		assert.ErrorContains(t, err, errorMsg)
+		repo.AssertExpectations(t)
+	})
+}
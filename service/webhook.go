@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/webhook"
+	"github.com/kavehjamshidi/fidibo-challenge/repository"
+)
+
+const webhookSecretSize = 32
+
+type WebhookService interface {
+	Create(ctx context.Context, username string, req domain.WebhookRequest) (domain.Webhook, error)
+	Get(ctx context.Context, username string, id string) (domain.Webhook, error)
+	List(ctx context.Context, username string) (domain.WebhooksResponse, error)
+	Update(ctx context.Context, username string, id string, req domain.WebhookRequest) (domain.Webhook, error)
+	Delete(ctx context.Context, username string, id string) error
+	Deliveries(ctx context.Context, username string, id string) (domain.WebhookDeliveriesResponse, error)
+	Redeliver(ctx context.Context, username string, id string, deliveryID string) (domain.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	webhooks   repository.WebhookRepository
+	deliveries repository.WebhookDeliveryRepository
+	guard      webhook.Guard
+	logger     *slog.Logger
+}
+
+// Create stores a webhook and returns it with its signing secret, which is generated when the request does
+// not carry one. The secret is not returned by any other call.
+func (w *webhookService) Create(ctx context.Context, username string, req domain.WebhookRequest) (domain.Webhook, error) {
+	id, err := newID()
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	webhook := domain.Webhook{
+		ID:        id,
+		Active:    true,
+		CreatedBy: username,
+		CreatedAt: time.Now().UTC(),
+	}
+	err = checkWebhookURL(ctx, w.guard, w.logger, req.URL)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	err = applyWebhookRequest(&webhook, req)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	err = w.webhooks.Create(ctx, webhook)
+	if errors.Is(err, domain.ErrWebhooksLimitReached) {
+		return domain.Webhook{}, err
+	}
+	if err != nil {
+		w.logger.ErrorContext(ctx, "could not create webhook", "error", err)
+		return domain.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (w *webhookService) Get(ctx context.Context, username string, id string) (domain.Webhook, error) {
+	webhook, err := w.owned(ctx, username, id)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+func (w *webhookService) List(ctx context.Context, username string) (domain.WebhooksResponse, error) {
+	webhooks, err := w.webhooks.List(ctx, username)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "could not list webhooks", "error", err)
+		return domain.WebhooksResponse{}, err
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return domain.WebhooksResponse{Webhooks: webhooks}, nil
+}
+
+// Update replaces the URL and events of a webhook. Its secret and state are only changed when the request
+// carries them.
+func (w *webhookService) Update(ctx context.Context, username string, id string, req domain.WebhookRequest) (domain.Webhook, error) {
+	webhook, err := w.owned(ctx, username, id)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	if req.Secret == "" {
+		req.Secret = webhook.Secret
+	}
+	err = checkWebhookURL(ctx, w.guard, w.logger, req.URL)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	err = applyWebhookRequest(&webhook, req)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	err = w.webhooks.Update(ctx, webhook)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "could not update webhook", "webhook_id", id, "error", err)
+		return domain.Webhook{}, err
+	}
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+func (w *webhookService) Delete(ctx context.Context, username string, id string) error {
+	_, err := w.owned(ctx, username, id)
+	if err != nil {
+		return err
+	}
+
+	err = w.webhooks.Delete(ctx, username, id)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "could not delete webhook", "webhook_id", id, "error", err)
+	}
+	return err
+}
+
+func (w *webhookService) Deliveries(ctx context.Context, username string, id string) (domain.WebhookDeliveriesResponse, error) {
+	_, err := w.owned(ctx, username, id)
+	if err != nil {
+		return domain.WebhookDeliveriesResponse{}, err
+	}
+
+	deliveries, err := w.deliveries.Log(ctx, id)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "could not list webhook deliveries", "webhook_id", id, "error", err)
+		return domain.WebhookDeliveriesResponse{}, err
+	}
+
+	return domain.WebhookDeliveriesResponse{Deliveries: deliveries}, nil
+}
+
+// Redeliver queues a delivery again with a fresh set of attempts, whatever its outcome was.
+func (w *webhookService) Redeliver(ctx context.Context, username string, id string, deliveryID string) (domain.WebhookDelivery, error) {
+	_, err := w.owned(ctx, username, id)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	delivery, err := w.deliveries.Get(ctx, deliveryID)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "could not get webhook delivery", "webhook_id", id, "delivery_id", deliveryID, "error", err)
+		return domain.WebhookDelivery{}, err
+	}
+	if delivery.WebhookID != id {
+		return domain.WebhookDelivery{}, domain.ErrWebhookDeliveryNotFound
+	}
+
+	now := time.Now().UTC()
+	delivery.Status = domain.DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+
+	err = w.deliveries.Retry(ctx, delivery)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "could not queue webhook delivery", "webhook_id", id, "delivery_id", deliveryID, "error", err)
+		return domain.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+// owned returns the webhook if it belongs to username. Webhooks of other users are reported as not found so
+// that their IDs cannot be probed.
+func (w *webhookService) owned(ctx context.Context, username string, id string) (domain.Webhook, error) {
+	webhook, err := w.webhooks.Get(ctx, id)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "could not get webhook", "webhook_id", id, "error", err)
+		return domain.Webhook{}, err
+	}
+	if webhook.CreatedBy != username {
+		return domain.Webhook{}, domain.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// SetupSystemWebhook points the system webhook, which receives the events about the whole service, at rawURL
+// and signs its payloads with secret. An empty rawURL removes it. It belongs to no user, so it cannot be seen or
+// changed through the API.
+func SetupSystemWebhook(ctx context.Context, webhooks repository.WebhookRepository, guard webhook.Guard, rawURL string, secret string) error {
+	if rawURL == "" {
+		err := webhooks.Delete(ctx, "", domain.SystemWebhookID)
+		if errors.Is(err, domain.ErrWebhookNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	err := guard.CheckURL(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+
+	return webhooks.Create(ctx, domain.Webhook{
+		ID:        domain.SystemWebhookID,
+		URL:       rawURL,
+		Events:    []string{domain.EventUserRegistered, domain.EventCircuitOpened},
+		Secret:    secret,
+		Active:    true,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+// checkWebhookURL refuses URLs that point into the service's own network. The reason is only logged, since it
+// can name internal addresses.
+func checkWebhookURL(ctx context.Context, guard webhook.Guard, logger *slog.Logger, rawURL string) error {
+	err := guard.CheckURL(ctx, rawURL)
+	if err != nil {
+		logger.WarnContext(ctx, "refused webhook URL", "url", rawURL, "error", err)
+		return domain.ErrWebhookURLNotAllowed
+	}
+	return nil
+}
+
+func applyWebhookRequest(webhook *domain.Webhook, req domain.WebhookRequest) error {
+	webhook.URL = req.URL
+	webhook.Events = req.Events
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	webhook.Secret = req.Secret
+	if webhook.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return err
+		}
+		webhook.Secret = secret
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretSize)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func NewWebhookService(webhooks repository.WebhookRepository,
+	deliveries repository.WebhookDeliveryRepository,
+	guard webhook.Guard,
+	logger *slog.Logger) WebhookService {
+	return &webhookService{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		guard:      guard,
+		logger:     logger.With("component", "webhook"),
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/webhook"
+	"github.com/kavehjamshidi/fidibo-challenge/repository"
+)
+
+const webhookUserAgent = "fidibo-challenge-webhooks"
+
+type WebhookDispatcherConfig struct {
+	Timeout     time.Duration
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	BatchSize   int
+}
+
+type WebhookDispatcher interface {
+	Start(ctx context.Context, interval time.Duration)
+	RunOnce(ctx context.Context) (int, error)
+}
+
+type webhookDispatcher struct {
+	webhooks   repository.WebhookRepository
+	deliveries repository.WebhookDeliveryRepository
+	client     *http.Client
+	cfg        WebhookDispatcherConfig
+	now        func() time.Time
+	logger     *slog.Logger
+}
+
+// Start sends the due deliveries every interval until ctx is done. A full batch is followed by the next one
+// right away, so a backlog drains without waiting for the ticker.
+func (d *webhookDispatcher) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			n, err := d.RunOnce(ctx)
+			if err != nil {
+				d.logger.ErrorContext(ctx, "run failed", "error", err)
+				break
+			}
+			if n < d.cfg.BatchSize {
+				break
+			}
+		}
+	}
+}
+
+// RunOnce claims a batch of due deliveries, sends them and returns how many it claimed. The claim lease
+// covers sending the whole batch, after which deliveries whose outcome was not recorded are sent again.
+func (d *webhookDispatcher) RunOnce(ctx context.Context) (int, error) {
+	lease := time.Duration(d.cfg.BatchSize+1) * d.cfg.Timeout
+
+	deliveries, err := d.deliveries.Claim(ctx, d.now(), lease, d.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		err := d.deliver(ctx, delivery)
+		if err != nil {
+			d.logger.ErrorContext(ctx, "could not record delivery", "delivery_id", delivery.ID, "error", err)
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+func (d *webhookDispatcher) deliver(ctx context.Context, delivery domain.WebhookDelivery) error {
+	hook, err := d.webhooks.Get(ctx, delivery.WebhookID)
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		return d.deadLetter(ctx, delivery, "webhook was deleted")
+	}
+	if err != nil {
+		return err
+	}
+	if !hook.Active {
+		return d.deadLetter(ctx, delivery, "webhook is disabled")
+	}
+
+	delivery.Attempts++
+	statusCode, err := d.send(ctx, hook, delivery)
+	delivery.LastStatusCode = statusCode
+	delivery.UpdatedAt = d.now().UTC()
+
+	if err == nil {
+		delivery.Status = domain.DeliveryStatusSucceeded
+		delivery.LastError = ""
+		return d.deliveries.Complete(ctx, delivery)
+	}
+
+	if delivery.Attempts >= d.cfg.MaxAttempts {
+		return d.deadLetter(ctx, delivery, err.Error())
+	}
+
+	delivery.LastError = err.Error()
+	delivery.NextAttemptAt = delivery.UpdatedAt.Add(d.backoff(delivery.Attempts))
+	return d.deliveries.Retry(ctx, delivery)
+}
+
+func (d *webhookDispatcher) deadLetter(ctx context.Context, delivery domain.WebhookDelivery, reason string) error {
+	d.logger.WarnContext(ctx, "giving up on delivery", "delivery_id", delivery.ID, "reason", reason)
+
+	delivery.Status = domain.DeliveryStatusDead
+	delivery.LastError = reason
+	delivery.UpdatedAt = d.now().UTC()
+	return d.deliveries.DeadLetter(ctx, delivery)
+}
+
+// send posts the payload signed with the webhook secret. Any response other than 2xx counts as a failure.
+func (d *webhookDispatcher) send(ctx context.Context, hook domain.Webhook, delivery domain.WebhookDelivery) (int, error) {
+	timestamp := d.now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set(webhook.EventHeader, delivery.EventType)
+	req.Header.Set(webhook.DeliveryHeader, delivery.ID)
+	req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(hook.Secret, timestamp, delivery.Payload))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1<<10))
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return res.StatusCode, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// guardedTransport connects through guard and never through a proxy, whose address would be checked instead
+// of the receiver's.
+func guardedTransport(guard webhook.Guard) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = guard.DialContext
+	return transport
+}
+
+// backoff doubles the wait after every failed attempt, up to MaxBackoff.
+func (d *webhookDispatcher) backoff(attempts int) time.Duration {
+	wait := d.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= d.cfg.MaxBackoff {
+			return d.cfg.MaxBackoff
+		}
+	}
+	return wait
+}
+
+func NewWebhookDispatcher(webhooks repository.WebhookRepository,
+	deliveries repository.WebhookDeliveryRepository,
+	guard webhook.Guard,
+	cfg WebhookDispatcherConfig,
+	logger *slog.Logger) WebhookDispatcher {
+	return &webhookDispatcher{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		client:     &http.Client{Timeout: cfg.Timeout, Transport: guardedTransport(guard)},
+		cfg:        cfg,
+		now:        time.Now,
+		logger:     logger.With("component", "webhook_dispatcher"),
+	}
+}
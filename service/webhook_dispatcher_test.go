@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/webhook"
+	repositoryMock "github.com/kavehjamshidi/fidibo-challenge/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWebhookDispatcher(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := WebhookDispatcherConfig{
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		BaseBackoff: 10 * time.Second,
+		MaxBackoff:  time.Minute,
+		BatchSize:   10,
+	}
+	payload := []byte(`{"id":"e1","type":"user.registered","data":{"username":"test"}}`)
+
+	newDispatcher := func(webhooks *repositoryMock.WebhookRepository, deliveries *repositoryMock.WebhookDeliveryRepository) *webhookDispatcher {
+		d := NewWebhookDispatcher(webhooks, deliveries, webhook.NewGuard(true), cfg, slog.Default()).(*webhookDispatcher)
+		d.now = func() time.Time { return now }
+		return d
+	}
+
+	t.Run("signed delivery", func(t *testing.T) {
+		var (
+			received  []byte
+			verifyErr error
+			event     string
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received, _ = io.ReadAll(r.Body)
+			event = r.Header.Get(webhook.EventHeader)
+			verifyErr = webhook.Verify("secret", r.Header.Get(webhook.TimestampHeader), r.Header.Get(webhook.SignatureHeader), received, time.Minute, now)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		delivery := domain.WebhookDelivery{ID: "d1", WebhookID: "abc", EventType: domain.EventUserRegistered, Payload: payload}
+
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		deliveries.On("Claim", context.TODO(), now, 11*time.Second, 10).Return([]domain.WebhookDelivery{delivery}, nil)
+		webhooks.On("Get", context.TODO(), "abc").Return(domain.Webhook{ID: "abc", URL: srv.URL, Secret: "secret", Active: true}, nil)
+		deliveries.On("Complete", context.TODO(), mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+			return d.Status == domain.DeliveryStatusSucceeded && d.Attempts == 1 && d.LastStatusCode == http.StatusNoContent
+		})).Return(nil)
+
+		n, err := newDispatcher(webhooks, deliveries).RunOnce(context.TODO())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.JSONEq(t, string(payload), string(received))
+		assert.NoError(t, verifyErr)
+		assert.Equal(t, domain.EventUserRegistered, event)
+		webhooks.AssertExpectations(t)
+		deliveries.AssertExpectations(t)
+	})
+
+	t.Run("failure is retried with backoff", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		delivery := domain.WebhookDelivery{ID: "d1", WebhookID: "abc", Payload: payload, Attempts: 1}
+
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		deliveries.On("Claim", context.TODO(), now, 11*time.Second, 10).Return([]domain.WebhookDelivery{delivery}, nil)
+		webhooks.On("Get", context.TODO(), "abc").Return(domain.Webhook{ID: "abc", URL: srv.URL, Secret: "secret", Active: true}, nil)
+		deliveries.On("Retry", context.TODO(), mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+			return d.Attempts == 2 && d.LastStatusCode == http.StatusInternalServerError &&
+				d.LastError != "" && d.NextAttemptAt.Equal(now.Add(20*time.Second))
+		})).Return(nil)
+
+		_, err := newDispatcher(webhooks, deliveries).RunOnce(context.TODO())
+
+		assert.NoError(t, err)
+		deliveries.AssertExpectations(t)
+	})
+
+	t.Run("last failed attempt is dead-lettered", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer srv.Close()
+
+		delivery := domain.WebhookDelivery{ID: "d1", WebhookID: "abc", Payload: payload, Attempts: 2}
+
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		deliveries.On("Claim", context.TODO(), now, 11*time.Second, 10).Return([]domain.WebhookDelivery{delivery}, nil)
+		webhooks.On("Get", context.TODO(), "abc").Return(domain.Webhook{ID: "abc", URL: srv.URL, Secret: "secret", Active: true}, nil)
+		deliveries.On("DeadLetter", context.TODO(), mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+			return d.Status == domain.DeliveryStatusDead && d.Attempts == 3 && d.LastStatusCode == http.StatusBadRequest
+		})).Return(nil)
+
+		_, err := newDispatcher(webhooks, deliveries).RunOnce(context.TODO())
+
+		assert.NoError(t, err)
+		deliveries.AssertExpectations(t)
+	})
+
+	t.Run("private address is refused when connecting", func(t *testing.T) {
+		called := false
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer srv.Close()
+
+		delivery := domain.WebhookDelivery{ID: "d1", WebhookID: "abc", Payload: payload}
+
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		deliveries.On("Claim", context.TODO(), now, 11*time.Second, 10).Return([]domain.WebhookDelivery{delivery}, nil)
+		webhooks.On("Get", context.TODO(), "abc").Return(domain.Webhook{ID: "abc", URL: srv.URL, Secret: "secret", Active: true}, nil)
+		deliveries.On("Retry", context.TODO(), mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+			return d.Attempts == 1 && d.LastStatusCode == 0 && strings.Contains(d.LastError, webhook.ErrAddressNotAllowed.Error())
+		})).Return(nil)
+
+		d := NewWebhookDispatcher(webhooks, deliveries, webhook.NewGuard(false), cfg, slog.Default()).(*webhookDispatcher)
+		d.now = func() time.Time { return now }
+		_, err := d.RunOnce(context.TODO())
+
+		assert.NoError(t, err)
+		assert.False(t, called)
+		deliveries.AssertExpectations(t)
+	})
+
+	t.Run("deleted webhook is dead-lettered", func(t *testing.T) {
+		delivery := domain.WebhookDelivery{ID: "d1", WebhookID: "abc", Payload: payload}
+
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		deliveries.On("Claim", context.TODO(), now, 11*time.Second, 10).Return([]domain.WebhookDelivery{delivery}, nil)
+		webhooks.On("Get", context.TODO(), "abc").Return(domain.Webhook{}, domain.ErrWebhookNotFound)
+		deliveries.On("DeadLetter", context.TODO(), mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+			return d.Status == domain.DeliveryStatusDead && d.Attempts == 0
+		})).Return(nil)
+
+		_, err := newDispatcher(webhooks, deliveries).RunOnce(context.TODO())
+
+		assert.NoError(t, err)
+		deliveries.AssertExpectations(t)
+	})
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	d := &webhookDispatcher{cfg: WebhookDispatcherConfig{BaseBackoff: 10 * time.Second, MaxBackoff: time.Minute}}
+
+	assert.Equal(t, 10*time.Second, d.backoff(1))
+	assert.Equal(t, 20*time.Second, d.backoff(2))
+	assert.Equal(t, 40*time.Second, d.backoff(3))
+	assert.Equal(t, time.Minute, d.backoff(4))
+	assert.Equal(t, time.Minute, d.backoff(100))
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/repository"
+)
+
+type WebhookPublisher interface {
+	Publish(ctx context.Context, eventType string, username string, data interface{}) error
+}
+
+type webhookPublisher struct {
+	webhooks   repository.WebhookRepository
+	deliveries repository.WebhookDeliveryRepository
+}
+
+// Publish queues a delivery of the event to every active webhook of username subscribed to it. System events,
+// such as user.registered and upstream.circuit_opened, only go to the system webhook configured by the operator.
+// A webhook created for a saved search only receives the results of that search. The payload is built once, so
+// every subscriber receives the same event ID.
+func (p *webhookPublisher) Publish(ctx context.Context, eventType string, username string, data interface{}) error {
+	webhooks, err := p.subscribers(ctx, eventType, username)
+	if err != nil {
+		return err
+	}
+
+	eventData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	eventID, err := newID()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	payload, err := json.Marshal(domain.WebhookEvent{
+		ID:        eventID,
+		Type:      eventType,
+		CreatedAt: now,
+		Data:      eventData,
+	})
+	if err != nil {
+		return err
+	}
+
+	deliveries := []domain.WebhookDelivery{}
+	for _, webhook := range webhooks {
+		if !webhook.Active || !webhook.Subscribed(eventType) {
+			continue
+		}
+		if webhook.SavedSearchID != "" && webhook.SavedSearchID != savedSearchOf(data) {
+			continue
+		}
+
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			ID:            id,
+			WebhookID:     webhook.ID,
+			EventID:       eventID,
+			EventType:     eventType,
+			Payload:       payload,
+			Status:        domain.DeliveryStatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return p.deliveries.Enqueue(ctx, deliveries...)
+}
+
+func (p *webhookPublisher) subscribers(ctx context.Context, eventType string, username string) ([]domain.Webhook, error) {
+	if !domain.IsSystemEvent(eventType) {
+		return p.webhooks.List(ctx, username)
+	}
+
+	webhook, err := p.webhooks.Get(ctx, domain.SystemWebhookID)
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []domain.Webhook{webhook}, nil
+}
+
+// savedSearchOf returns the ID of the saved search an event is about, or an empty string for other events.
+func savedSearchOf(data interface{}) string {
+	event, ok := data.(domain.SavedSearchResultsEvent)
+	if !ok {
+		return ""
+	}
+	return event.Notification.SavedSearchID
+}
+
+func NewWebhookPublisher(webhooks repository.WebhookRepository, deliveries repository.WebhookDeliveryRepository) WebhookPublisher {
+	return &webhookPublisher{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+	}
+}
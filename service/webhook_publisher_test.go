@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	repositoryMock "github.com/kavehjamshidi/fidibo-challenge/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPublishWebhookEvent(t *testing.T) {
+	t.Run("queues a delivery per subscriber", func(t *testing.T) {
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		webhooks.On("List", context.TODO(), "test").Return([]domain.Webhook{
+			{ID: "a", Events: []string{domain.EventSavedSearchNewResults}, Active: true, CreatedBy: "test"},
+			{ID: "b", Events: []string{domain.EventCircuitOpened}, Active: true, CreatedBy: "test"},
+			{ID: "c", Events: []string{domain.EventSavedSearchNewResults}, Active: false, CreatedBy: "test"},
+			{ID: "d", Events: []string{domain.EventSavedSearchNewResults}, Active: true, CreatedBy: "test"},
+		}, nil)
+
+		var queued []domain.WebhookDelivery
+		deliveries.On("Enqueue", context.TODO(), mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			queued = []domain.WebhookDelivery{args.Get(1).(domain.WebhookDelivery), args.Get(2).(domain.WebhookDelivery)}
+		})
+
+		publisher := NewWebhookPublisher(webhooks, deliveries)
+		err := publisher.Publish(context.TODO(), domain.EventSavedSearchNewResults, "test", domain.SavedSearchResultsEvent{Username: "test"})
+
+		assert.NoError(t, err)
+		assert.Len(t, queued, 2)
+		assert.Equal(t, "a", queued[0].WebhookID)
+		assert.Equal(t, "d", queued[1].WebhookID)
+		assert.Equal(t, queued[0].EventID, queued[1].EventID)
+		assert.Equal(t, domain.DeliveryStatusPending, queued[0].Status)
+
+		event := domain.WebhookEvent{}
+		err = json.Unmarshal(queued[0].Payload, &event)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.EventSavedSearchNewResults, event.Type)
+		data := domain.SavedSearchResultsEvent{}
+		err = json.Unmarshal(event.Data, &data)
+		assert.NoError(t, err)
+		assert.Equal(t, "test", data.Username)
+		webhooks.AssertExpectations(t)
+		deliveries.AssertExpectations(t)
+	})
+
+	t.Run("system events only go to the system webhook", func(t *testing.T) {
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		webhooks.On("Get", context.TODO(), domain.SystemWebhookID).Return(domain.Webhook{
+			ID:     domain.SystemWebhookID,
+			Events: []string{domain.EventUserRegistered, domain.EventCircuitOpened},
+			Active: true,
+		}, nil)
+		deliveries.On("Enqueue", context.TODO(),
+			mock.MatchedBy(func(d domain.WebhookDelivery) bool { return d.WebhookID == domain.SystemWebhookID })).Return(nil)
+
+		publisher := NewWebhookPublisher(webhooks, deliveries)
+		err := publisher.Publish(context.TODO(), domain.EventUserRegistered, "test", domain.UserRegisteredEvent{Username: "test"})
+
+		assert.NoError(t, err)
+		webhooks.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+		webhooks.AssertExpectations(t)
+		deliveries.AssertExpectations(t)
+	})
+
+	t.Run("system events without a system webhook", func(t *testing.T) {
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		webhooks.On("Get", context.TODO(), domain.SystemWebhookID).Return(domain.Webhook{}, domain.ErrWebhookNotFound)
+
+		publisher := NewWebhookPublisher(webhooks, deliveries)
+		err := publisher.Publish(context.TODO(), domain.EventCircuitOpened, "", domain.CircuitOpenedEvent{})
+
+		assert.NoError(t, err)
+		webhooks.AssertExpectations(t)
+		deliveries.AssertExpectations(t)
+	})
+
+	t.Run("webhooks of a saved search only receive its results", func(t *testing.T) {
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		webhooks.On("List", context.TODO(), "test").Return([]domain.Webhook{
+			{ID: "a", Events: []string{domain.EventSavedSearchNewResults}, Active: true, CreatedBy: "test"},
+			{ID: "b", Events: []string{domain.EventSavedSearchNewResults}, Active: true, CreatedBy: "test", SavedSearchID: "abc"},
+			{ID: "c", Events: []string{domain.EventSavedSearchNewResults}, Active: true, CreatedBy: "test", SavedSearchID: "def"},
+		}, nil)
+		deliveries.On("Enqueue", context.TODO(),
+			mock.MatchedBy(func(d domain.WebhookDelivery) bool { return d.WebhookID == "a" }),
+			mock.MatchedBy(func(d domain.WebhookDelivery) bool { return d.WebhookID == "b" })).Return(nil)
+
+		publisher := NewWebhookPublisher(webhooks, deliveries)
+		err := publisher.Publish(context.TODO(), domain.EventSavedSearchNewResults, "test", domain.SavedSearchResultsEvent{
+			Username:     "test",
+			Notification: domain.Notification{SavedSearchID: "abc"},
+		})
+
+		assert.NoError(t, err)
+		deliveries.AssertExpectations(t)
+	})
+
+	t.Run("no subscribers", func(t *testing.T) {
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		webhooks.On("List", context.TODO(), "test").Return([]domain.Webhook{}, nil)
+
+		publisher := NewWebhookPublisher(webhooks, deliveries)
+		err := publisher.Publish(context.TODO(), domain.EventSavedSearchNewResults, "test", domain.SavedSearchResultsEvent{Username: "test"})
+
+		assert.NoError(t, err)
+		deliveries.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	webhookMock "github.com/kavehjamshidi/fidibo-challenge/pkg/webhook/mocks"
+	repositoryMock "github.com/kavehjamshidi/fidibo-challenge/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func allowAll() *webhookMock.Guard {
+	guard := &webhookMock.Guard{}
+	guard.On("CheckURL", mock.Anything, mock.Anything).Return(nil)
+	return guard
+}
+
+func TestCreateWebhook(t *testing.T) {
+	t.Run("generates a secret", func(t *testing.T) {
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		webhooks.On("Create", context.TODO(), mock.MatchedBy(func(webhook domain.Webhook) bool {
+			return len(webhook.Secret) == 2*webhookSecretSize && webhook.Active && webhook.CreatedBy == "test"
+		})).Return(nil)
+
+		svc := NewWebhookService(webhooks, deliveries, allowAll(), slog.Default())
+		webhook, err := svc.Create(context.TODO(), "test", domain.WebhookRequest{
+			URL:    "https://example.com/hook",
+			Events: []string{domain.EventSavedSearchNewResults},
+		})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, webhook.ID)
+		assert.NotEmpty(t, webhook.Secret)
+		webhooks.AssertExpectations(t)
+	})
+
+	t.Run("keeps the given secret", func(t *testing.T) {
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		active := false
+		webhooks.On("Create", context.TODO(), mock.MatchedBy(func(webhook domain.Webhook) bool {
+			return webhook.Secret == "0123456789abcdef" && !webhook.Active
+		})).Return(nil)
+
+		svc := NewWebhookService(webhooks, deliveries, allowAll(), slog.Default())
+		webhook, err := svc.Create(context.TODO(), "test", domain.WebhookRequest{
+			URL:    "https://example.com/hook",
+			Events: []string{domain.EventSavedSearchNewResults},
+			Secret: "0123456789abcdef",
+			Active: &active,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "0123456789abcdef", webhook.Secret)
+		webhooks.AssertExpectations(t)
+	})
+}
+
+func TestWebhookURLNotAllowed(t *testing.T) {
+	webhooks := &repositoryMock.WebhookRepository{}
+	deliveries := &repositoryMock.WebhookDeliveryRepository{}
+	guard := &webhookMock.Guard{}
+
+	guard.On("CheckURL", context.TODO(), "http://169.254.169.254/latest").Return(errors.New("webhook address is not allowed: 169.254.169.254"))
+	webhooks.On("Get", context.TODO(), "abc").Return(domain.Webhook{ID: "abc", CreatedBy: "test"}, nil)
+
+	svc := NewWebhookService(webhooks, deliveries, guard, slog.Default())
+	req := domain.WebhookRequest{URL: "http://169.254.169.254/latest", Events: []string{domain.EventSavedSearchNewResults}}
+
+	_, err := svc.Create(context.TODO(), "test", req)
+	assert.ErrorIs(t, err, domain.ErrWebhookURLNotAllowed)
+
+	_, err = svc.Update(context.TODO(), "test", "abc", req)
+	assert.ErrorIs(t, err, domain.ErrWebhookURLNotAllowed)
+
+	webhooks.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	webhooks.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	guard.AssertExpectations(t)
+}
+
+func TestGetAndListWebhooksHideSecrets(t *testing.T) {
+	stored := domain.Webhook{ID: "abc", Secret: "secret", CreatedBy: "test"}
+
+	webhooks := &repositoryMock.WebhookRepository{}
+	deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+	webhooks.On("Get", context.TODO(), "abc").Return(stored, nil)
+	webhooks.On("List", context.TODO(), "test").Return([]domain.Webhook{stored}, nil)
+
+	svc := NewWebhookService(webhooks, deliveries, allowAll(), slog.Default())
+
+	webhook, err := svc.Get(context.TODO(), "test", "abc")
+	assert.NoError(t, err)
+	assert.Empty(t, webhook.Secret)
+
+	res, err := svc.List(context.TODO(), "test")
+	assert.NoError(t, err)
+	assert.Empty(t, res.Webhooks[0].Secret)
+	webhooks.AssertExpectations(t)
+}
+
+func TestWebhooksOfOtherUsers(t *testing.T) {
+	stored := domain.Webhook{ID: "abc", Secret: "secret", CreatedBy: "other"}
+
+	webhooks := &repositoryMock.WebhookRepository{}
+	deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+	webhooks.On("Get", context.TODO(), "abc").Return(stored, nil)
+	webhooks.On("List", context.TODO(), "test").Return([]domain.Webhook{}, nil)
+
+	svc := NewWebhookService(webhooks, deliveries, allowAll(), slog.Default())
+
+	_, err := svc.Get(context.TODO(), "test", "abc")
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+
+	_, err = svc.Update(context.TODO(), "test", "abc", domain.WebhookRequest{URL: "https://example.com/new"})
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+
+	err = svc.Delete(context.TODO(), "test", "abc")
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+
+	_, err = svc.Deliveries(context.TODO(), "test", "abc")
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+
+	_, err = svc.Redeliver(context.TODO(), "test", "abc", "d1")
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+
+	res, err := svc.List(context.TODO(), "test")
+	assert.NoError(t, err)
+	assert.Empty(t, res.Webhooks)
+
+	webhooks.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	webhooks.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	deliveries.AssertExpectations(t)
+}
+
+func TestSetupSystemWebhook(t *testing.T) {
+	t.Run("stores the system webhook", func(t *testing.T) {
+		webhooks := &repositoryMock.WebhookRepository{}
+
+		webhooks.On("Create", context.TODO(), mock.MatchedBy(func(webhook domain.Webhook) bool {
+			return webhook.ID == domain.SystemWebhookID && webhook.URL == "https://example.com/hook" &&
+				webhook.Secret == "0123456789abcdef" && webhook.Active && webhook.CreatedBy == "" &&
+				webhook.Subscribed(domain.EventUserRegistered) && webhook.Subscribed(domain.EventCircuitOpened)
+		})).Return(nil)
+
+		err := SetupSystemWebhook(context.TODO(), webhooks, allowAll(), "https://example.com/hook", "0123456789abcdef")
+
+		assert.NoError(t, err)
+		webhooks.AssertExpectations(t)
+	})
+
+	t.Run("removes it without a URL", func(t *testing.T) {
+		webhooks := &repositoryMock.WebhookRepository{}
+
+		webhooks.On("Delete", context.TODO(), "", domain.SystemWebhookID).Return(domain.ErrWebhookNotFound)
+
+		err := SetupSystemWebhook(context.TODO(), webhooks, allowAll(), "", "")
+
+		assert.NoError(t, err)
+		webhooks.AssertExpectations(t)
+	})
+
+	t.Run("refused URL", func(t *testing.T) {
+		webhooks := &repositoryMock.WebhookRepository{}
+		guard := &webhookMock.Guard{}
+
+		guard.On("CheckURL", context.TODO(), "http://127.0.0.1/hook").Return(errors.New("loopback address"))
+
+		err := SetupSystemWebhook(context.TODO(), webhooks, guard, "http://127.0.0.1/hook", "0123456789abcdef")
+
+		assert.Error(t, err)
+		webhooks.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUpdateWebhook(t *testing.T) {
+	t.Run("keeps secret and state", func(t *testing.T) {
+		stored := domain.Webhook{ID: "abc", URL: "https://example.com/old", Events: []string{domain.EventSavedSearchNewResults}, Secret: "secret", Active: true, CreatedBy: "test"}
+		expected := domain.Webhook{ID: "abc", URL: "https://example.com/new", Events: []string{domain.EventSavedSearchNewResults}, Secret: "secret", Active: true, CreatedBy: "test"}
+
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		webhooks.On("Get", context.TODO(), "abc").Return(stored, nil)
+		webhooks.On("Update", context.TODO(), expected).Return(nil)
+
+		svc := NewWebhookService(webhooks, deliveries, allowAll(), slog.Default())
+		webhook, err := svc.Update(context.TODO(), "test", "abc", domain.WebhookRequest{
+			URL:    "https://example.com/new",
+			Events: []string{domain.EventSavedSearchNewResults},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com/new", webhook.URL)
+		assert.Empty(t, webhook.Secret)
+		webhooks.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		webhooks.On("Get", context.TODO(), "abc").Return(domain.Webhook{}, domain.ErrWebhookNotFound)
+
+		svc := NewWebhookService(webhooks, deliveries, allowAll(), slog.Default())
+		_, err := svc.Update(context.TODO(), "test", "abc", domain.WebhookRequest{})
+
+		assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+		webhooks.AssertExpectations(t)
+	})
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	t.Run("log", func(t *testing.T) {
+		log := []domain.WebhookDelivery{{ID: "d1", WebhookID: "abc"}}
+
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		webhooks.On("Get", context.TODO(), "abc").Return(domain.Webhook{ID: "abc", CreatedBy: "test"}, nil)
+		deliveries.On("Log", context.TODO(), "abc").Return(log, nil)
+
+		svc := NewWebhookService(webhooks, deliveries, allowAll(), slog.Default())
+		res, err := svc.Deliveries(context.TODO(), "test", "abc")
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.WebhookDeliveriesResponse{Deliveries: log}, res)
+		webhooks.AssertExpectations(t)
+		deliveries.AssertExpectations(t)
+	})
+
+	t.Run("redeliver", func(t *testing.T) {
+		dead := domain.WebhookDelivery{ID: "d1", WebhookID: "abc", Status: domain.DeliveryStatusDead, Attempts: 8}
+
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		webhooks.On("Get", context.TODO(), "abc").Return(domain.Webhook{ID: "abc", CreatedBy: "test"}, nil)
+		deliveries.On("Get", context.TODO(), "d1").Return(dead, nil)
+		deliveries.On("Retry", context.TODO(), mock.MatchedBy(func(delivery domain.WebhookDelivery) bool {
+			return delivery.ID == "d1" && delivery.Status == domain.DeliveryStatusPending && delivery.Attempts == 0
+		})).Return(nil)
+
+		svc := NewWebhookService(webhooks, deliveries, allowAll(), slog.Default())
+		delivery, err := svc.Redeliver(context.TODO(), "test", "abc", "d1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DeliveryStatusPending, delivery.Status)
+		deliveries.AssertExpectations(t)
+	})
+
+	t.Run("redeliver of another webhook", func(t *testing.T) {
+		webhooks := &repositoryMock.WebhookRepository{}
+		deliveries := &repositoryMock.WebhookDeliveryRepository{}
+
+		webhooks.On("Get", context.TODO(), "abc").Return(domain.Webhook{ID: "abc", CreatedBy: "test"}, nil)
+		deliveries.On("Get", context.TODO(), "d1").Return(domain.WebhookDelivery{ID: "d1", WebhookID: "other"}, nil)
+
+		svc := NewWebhookService(webhooks, deliveries, allowAll(), slog.Default())
+		_, err := svc.Redeliver(context.TODO(), "test", "abc", "d1")
+
+		assert.ErrorIs(t, err, domain.ErrWebhookDeliveryNotFound)
+		deliveries.AssertExpectations(t)
+	})
+}
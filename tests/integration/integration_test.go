@@ -6,9 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,12 +23,22 @@ import (
 	"github.com/kavehjamshidi/fidibo-challenge/bootstrap"
 	"github.com/kavehjamshidi/fidibo-challenge/cache"
 	"github.com/kavehjamshidi/fidibo-challenge/db"
+	"github.com/kavehjamshidi/fidibo-challenge/docs"
 	"github.com/kavehjamshidi/fidibo-challenge/domain"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/live"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/logging"
 	"github.com/kavehjamshidi/fidibo-challenge/internal/token"
+	"github.com/kavehjamshidi/fidibo-challenge/internal/tracing"
 	"github.com/kavehjamshidi/fidibo-challenge/pkg/fidibosearch"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/ratelimit"
+	"github.com/kavehjamshidi/fidibo-challenge/pkg/webhook"
+	"github.com/kavehjamshidi/fidibo-challenge/repository"
 	"github.com/kavehjamshidi/fidibo-challenge/service"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 const (
@@ -35,38 +50,145 @@ var (
 	router      *gin.Engine
 	env         *bootstrap.Env
 	redisClient *redis.Client
+	spans       *tracetest.InMemoryExporter
+	logger      *slog.Logger
 )
 
 func TestMain(m *testing.M) {
-	env = bootstrap.NewEnv()
+	var err error
+	env, err = bootstrap.Load([]string{"--profile", bootstrap.ProfileDev, "--webhook-allow-private-addresses", "true"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger = logging.New(os.Stderr, env.LogLevel)
+
+	spans = tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(tracing.NewTracerProvider(sdktrace.WithSyncer(spans), 1))
+	_, err = tracing.Setup(context.Background(), tracing.Config{})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	redisClient = db.NewRedisClient(context.Background(), env.TestRedisAddress)
-	cache := cache.NewCacher(redisClient)
-
-	fidiboClient := fidibosearch.NewFidiboSearcher(fidiboQueryKey, fidiboSearchURL)
+	accessTokenKeys := token.NewKeyring(env.AccessTokenSecret, env.SecretRotationGrace)
+	refreshTokenKeys := token.NewKeyring(env.RefreshTokenSecret, env.RefreshSecretRotationGrace)
+	cacheTTL := live.NewDuration(env.CacheTTL)
+	bookCacheTTL := live.NewDuration(env.BookCacheTTL)
+	bookCache := cache.NewBookCacher(redisClient, bookCacheTTL)
+	cache := cache.NewCacher(redisClient, cacheTTL)
+	suggestionRepository := repository.NewSuggestionRepository(redisClient)
+	historyRepository := repository.NewHistoryRepository(redisClient, env.SearchHistorySize)
+	favoriteRepository := repository.NewFavoriteRepository(redisClient, env.FavoritesLimit)
+	savedSearchRepository := repository.NewSavedSearchRepository(redisClient, env.SavedSearchesLimit)
+	notificationRepository := repository.NewNotificationRepository(redisClient, env.NotificationsSize)
+	userRepository := repository.NewUserRepository(redisClient)
+	webhookRepository := repository.NewWebhookRepository(redisClient, env.WebhooksLimit)
+	webhookGuard := webhook.NewGuard(env.WebhookAllowPrivate)
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(redisClient, env.WebhookDeliveryLogSize, env.WebhookDeliveryRetention)
+
+	webhookPublisher := service.NewWebhookPublisher(webhookRepository, webhookDeliveryRepository)
+
+	fidiboSearcher := fidibosearch.NewFidiboSearcher(fidiboQueryKey, fidiboSearchURL, env.UpstreamTimeout)
+	if env.UpstreamQuotaLimit > 0 {
+		fidiboSearcher = fidibosearch.NewQuota(fidiboSearcher, redisClient, fidibosearch.QuotaConfig{
+			Limit:           env.UpstreamQuotaLimit,
+			Window:          env.UpstreamQuotaWindow,
+			MaxWait:         env.UpstreamQuotaMaxWait,
+			BackgroundShare: env.UpstreamQuotaBackgroundShare,
+		})
+	}
+	fidiboClient := fidibosearch.NewCircuitBreaker(
+		fidiboSearcher,
+		fidibosearch.BreakerConfig{
+			WindowSize:            env.BreakerWindowSize,
+			MinRequests:           env.BreakerMinRequests,
+			ErrorRateThreshold:    env.BreakerErrorRate,
+			SlowCallThreshold:     env.BreakerSlowCall,
+			SlowCallRateThreshold: env.BreakerSlowCallRate,
+			OpenTimeout:           env.BreakerOpenTimeout,
+			HalfOpenMaxCalls:      env.BreakerHalfOpenCalls,
+			MaxConcurrent:         env.UpstreamMaxConcurrent,
+			MaxQueueWait:          env.UpstreamMaxQueueWait,
+			OnStateChange: func(from, to fidibosearch.BreakerState) {
+				logger.Warn("fidibo search circuit breaker state changed", "from", from.String(), "to", to.String())
+				if to != fidibosearch.StateOpen {
+					return
+				}
+				err := webhookPublisher.Publish(context.Background(), domain.EventCircuitOpened, "", domain.CircuitOpenedEvent{
+					Upstream: fidiboSearchURL,
+					From:     from.String(),
+				})
+				if err != nil {
+					logger.Error("could not publish circuit opened event", "error", err)
+				}
+			},
+		})
 
 	loginSVC := service.NewLoginService(env.AccessTokenExpiry,
-		env.AccessTokenSecret,
+		accessTokenKeys,
 		env.RefreshTokenExpiry,
-		env.RefreshTokenSecret)
+		refreshTokenKeys,
+		userRepository,
+		webhookPublisher,
+		logger)
 	refreshTokenSVC := service.NewRefreshTokenService(env.AccessTokenExpiry,
-		env.AccessTokenSecret,
+		accessTokenKeys,
 		env.RefreshTokenExpiry,
-		env.RefreshTokenSecret)
-	searchSVC := service.NewSearchService(cache, fidiboClient)
+		refreshTokenKeys,
+		logger)
+	searchSVC := service.NewSearchService(cache, fidiboClient, suggestionRepository, bookCache, historyRepository, logger)
+	suggestSVC := service.NewSuggestService(suggestionRepository, env.SuggestBudget, live.NewDuration(env.SuggestCacheTTL), logger)
+	bookSVC := service.NewBookService(bookCache, fidiboClient, logger)
+	catalogSVC := service.NewCatalogService(cache, fidiboClient, bookCache, logger)
+	historySVC := service.NewHistoryService(historyRepository, logger)
+	favoriteSVC := service.NewFavoriteService(favoriteRepository, bookSVC, logger)
+	savedSearchSVC := service.NewSavedSearchService(savedSearchRepository, webhookRepository, webhookGuard, logger)
+	notificationSVC := service.NewNotificationService(notificationRepository, logger)
+	webhookSVC := service.NewWebhookService(webhookRepository, webhookDeliveryRepository, webhookGuard, logger)
+	healthSVC := service.NewHealthService([]service.HealthCheck{{
+		Name:  "redis",
+		Check: func(ctx context.Context) error { return redisClient.Ping(ctx).Err() },
+	}}, env.HealthCheckTimeout, logger)
 
 	loginController := controllers.NewLoginController(loginSVC)
-	refreshTokenController := controllers.NewRefreshTokenController(refreshTokenSVC, env.RefreshTokenSecret)
-	searchController := controllers.NewSearchController(searchSVC)
+	refreshTokenController := controllers.NewRefreshTokenController(refreshTokenSVC, refreshTokenKeys)
+	searchController := controllers.NewSearchController(searchSVC, cacheTTL)
+	suggestController := controllers.NewSuggestController(suggestSVC)
+	bookController := controllers.NewBookController(bookSVC, bookCacheTTL)
+	catalogController := controllers.NewCatalogController(catalogSVC, cacheTTL)
+	historyController := controllers.NewHistoryController(historySVC)
+	favoriteController := controllers.NewFavoriteController(favoriteSVC)
+	savedSearchController := controllers.NewSavedSearchController(savedSearchSVC)
+	notificationController := controllers.NewNotificationController(notificationSVC)
+	webhookController := controllers.NewWebhookController(webhookSVC)
+	healthController := controllers.NewHealthController(healthSVC)
 	notFoundController := controllers.NewNotFoundController()
 
-	router = gin.Default()
+	router = gin.New()
+	err = router.SetTrustedProxies(env.TrustedProxyList())
+	if err != nil {
+		panic(err)
+	}
 
 	routes.Setup(router, routes.Controllers{
 		SearchController:       searchController,
+		SuggestController:      suggestController,
+		BookController:         bookController,
+		CatalogController:      catalogController,
+		HistoryController:      historyController,
+		FavoriteController:     favoriteController,
+		SavedSearchController:  savedSearchController,
+		NotificationController: notificationController,
+		WebhookController:      webhookController,
 		LoginController:        loginController,
 		RefreshTokenController: refreshTokenController,
-	}, env.AccessTokenSecret)
+		HealthController:       healthController,
+		DocsController:         controllers.NewDocsController(docs.OpenAPI),
+	}, accessTokenKeys, ratelimit.NewRedisLimiter(redisClient), routes.RateLimits{
+		Auth:   ratelimit.Limit{Requests: env.RateLimitAuthRequests, Window: env.RateLimitAuthWindow},
+		Search: ratelimit.Limit{Requests: env.RateLimitSearchRequests, Window: env.RateLimitSearchWindow},
+		API:    ratelimit.Limit{Requests: env.RateLimitAPIRequests, Window: env.RateLimitAPIWindow},
+	}, logger)
 
 	router.NoRoute(notFoundController.NotFound)
 
@@ -188,7 +310,7 @@ func TestSearch(t *testing.T) {
 		assert.NoError(t, err)
 
 		w := httptest.NewRecorder()
-		req, err := http.NewRequest(http.MethodPost, "/search/book?keyword="+query, nil)
+		req, err := http.NewRequest(http.MethodGet, "/search/book?keyword="+query, nil)
 		req.Header = make(http.Header)
 		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
 
@@ -214,7 +336,7 @@ func TestSearch(t *testing.T) {
 		assert.NoError(t, err)
 
 		w := httptest.NewRecorder()
-		req, err := http.NewRequest(http.MethodPost, "/search/book?keyword="+query, nil)
+		req, err := http.NewRequest(http.MethodGet, "/search/book?keyword="+query, nil)
 		req.Header = make(http.Header)
 		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
 
@@ -232,7 +354,7 @@ func TestSearch(t *testing.T) {
 		assert.NotEmpty(t, response.Books)
 
 		w = httptest.NewRecorder()
-		req, err = http.NewRequest(http.MethodPost, "/search/book?keyword="+query, nil)
+		req, err = http.NewRequest(http.MethodGet, "/search/book?keyword="+query, nil)
 		req.Header = make(http.Header)
 		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
 
@@ -250,7 +372,7 @@ func TestSearch(t *testing.T) {
 		assert.NotEmpty(t, response.Books)
 	})
 
-	t.Run("success with empty query", func(t *testing.T) {
+	t.Run("empty query", func(t *testing.T) {
 		defer redisClient.FlushAll(context.TODO())
 
 		query := ""
@@ -258,7 +380,7 @@ func TestSearch(t *testing.T) {
 		assert.NoError(t, err)
 
 		w := httptest.NewRecorder()
-		req, err := http.NewRequest(http.MethodPost, "/search/book?keyword="+query, nil)
+		req, err := http.NewRequest(http.MethodGet, "/search/book?keyword="+query, nil)
 		req.Header = make(http.Header)
 		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
 
@@ -268,22 +390,22 @@ func TestSearch(t *testing.T) {
 		res, err := io.ReadAll(w.Body)
 		assert.NoError(t, err)
 
-		response := domain.SearchResult{}
+		response := domain.ErrorResponse{}
 		err = json.Unmarshal(res, &response)
 		assert.NoError(t, err)
 
-		assert.Equal(t, http.StatusOK, w.Code)
-		assert.NotEmpty(t, response.Books)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, response.Errors, domain.FieldError{Field: "keyword", Message: "is required"})
 	})
 
-	t.Run("success with no query", func(t *testing.T) {
+	t.Run("no query", func(t *testing.T) {
 		defer redisClient.FlushAll(context.TODO())
 
 		jwt, err := token.GenerateJWT("test", env.AccessTokenSecret, env.AccessTokenExpiry)
 		assert.NoError(t, err)
 
 		w := httptest.NewRecorder()
-		req, err := http.NewRequest(http.MethodPost, "/search/book", nil)
+		req, err := http.NewRequest(http.MethodGet, "/search/book", nil)
 		req.Header = make(http.Header)
 		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
 
@@ -293,19 +415,19 @@ func TestSearch(t *testing.T) {
 		res, err := io.ReadAll(w.Body)
 		assert.NoError(t, err)
 
-		response := domain.SearchResult{}
+		response := domain.ErrorResponse{}
 		err = json.Unmarshal(res, &response)
 		assert.NoError(t, err)
 
-		assert.Equal(t, http.StatusOK, w.Code)
-		assert.NotEmpty(t, response.Books)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, response.Errors, domain.FieldError{Field: "keyword", Message: "is required"})
 	})
 
 	t.Run("test with no auth header", func(t *testing.T) {
 		query := "کافکا"
 
 		w := httptest.NewRecorder()
-		req, err := http.NewRequest(http.MethodPost, "/search/book?keyword="+query, nil)
+		req, err := http.NewRequest(http.MethodGet, "/search/book?keyword="+query, nil)
 
 		assert.NoError(t, err)
 		router.ServeHTTP(w, req)
@@ -321,11 +443,23 @@ func TestSearch(t *testing.T) {
 		assert.NotEmpty(t, response.Message)
 	})
 
-	t.Run("test with invalid JWT", func(t *testing.T) {
+	t.Run("post with no auth header", func(t *testing.T) {
 		query := "کافکا"
 
 		w := httptest.NewRecorder()
 		req, err := http.NewRequest(http.MethodPost, "/search/book?keyword="+query, nil)
+
+		assert.NoError(t, err)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("test with invalid JWT", func(t *testing.T) {
+		query := "کافکا"
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/search/book?keyword="+query, nil)
 		req.Header = make(http.Header)
 		req.Header.Add("Authorization", "Bearer invalid-token")
 
@@ -345,7 +479,7 @@ func TestSearch(t *testing.T) {
 
 	t.Run("test not found", func(t *testing.T) {
 		w := httptest.NewRecorder()
-		req, err := http.NewRequest(http.MethodPost, "/search/books", nil)
+		req, err := http.NewRequest(http.MethodGet, "/search/books", nil)
 		assert.NoError(t, err)
 		router.ServeHTTP(w, req)
 
@@ -361,3 +495,584 @@ func TestSearch(t *testing.T) {
 		assert.Equal(t, "Not Found", response.Message)
 	})
 }
+
+func TestSuggest(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		defer redisClient.FlushAll(context.TODO())
+
+		err := repository.NewSuggestionRepository(redisClient).Index(context.TODO(), []domain.Book{
+			{ID: "1", Title: "مسخ", Authors: []domain.Author{{Name: "فرانتس کافکا"}}},
+		})
+		assert.NoError(t, err)
+
+		jwt, err := token.GenerateJWT("test", env.AccessTokenSecret, env.AccessTokenExpiry)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/search/suggest?prefix="+url.QueryEscape("فران"), nil)
+		assert.NoError(t, err)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+		router.ServeHTTP(w, req)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		response := domain.SuggestResponse{}
+		err = json.Unmarshal(res, &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []domain.Suggestion{{Text: "فرانتس کافکا", Type: domain.SuggestionTypeAuthor}}, response.Suggestions)
+	})
+
+	t.Run("new titles are kept when a prefix is full", func(t *testing.T) {
+		defer redisClient.FlushAll(context.TODO())
+
+		repo := repository.NewSuggestionRepository(redisClient)
+		for i := 0; i < 60; i++ {
+			book := domain.Book{ID: strconv.Itoa(i), Title: fmt.Sprintf("x%02d", i)}
+			for j := 0; j < 2; j++ {
+				err := repo.Index(context.TODO(), []domain.Book{book})
+				assert.NoError(t, err)
+			}
+		}
+
+		err := repo.Index(context.TODO(), []domain.Book{{ID: "new", Title: "xnew"}})
+		assert.NoError(t, err)
+
+		assert.Equal(t, int64(50), redisClient.ZCard(context.TODO(), "suggest:prefix:x").Val())
+		assert.NoError(t, redisClient.ZScore(context.TODO(), "suggest:prefix:x", "title|xnew").Err())
+		assert.Greater(t, redisClient.TTL(context.TODO(), "suggest:prefix:x").Val(), time.Duration(0))
+	})
+
+	t.Run("indexing works after the script cache is flushed", func(t *testing.T) {
+		defer redisClient.FlushAll(context.TODO())
+
+		err := redisClient.ScriptFlush(context.TODO()).Err()
+		assert.NoError(t, err)
+
+		repo := repository.NewSuggestionRepository(redisClient)
+		err = repo.Index(context.TODO(), []domain.Book{{ID: "1", Title: "Kafka"}})
+		assert.NoError(t, err)
+
+		assert.Equal(t, int64(1), redisClient.ZCard(context.TODO(), "suggest:prefix:kafka").Val())
+		assert.Equal(t, float64(1), redisClient.ZScore(context.TODO(), "suggest:vocabulary", "kafka").Val())
+	})
+
+	t.Run("a full vocabulary keeps new words and drops the least recently seen", func(t *testing.T) {
+		defer redisClient.FlushAll(context.TODO())
+
+		counts := make([]redis.Z, 0, 5000)
+		seen := make([]redis.Z, 0, 5000)
+		for i := 0; i < 5000; i++ {
+			word := fmt.Sprintf("word%d", i)
+			counts = append(counts, redis.Z{Score: 100, Member: word})
+			seen = append(seen, redis.Z{Score: float64(i + 1), Member: word})
+		}
+		assert.NoError(t, redisClient.ZAdd(context.TODO(), "suggest:vocabulary", counts...).Err())
+		assert.NoError(t, redisClient.ZAdd(context.TODO(), "suggest:vocabulary:seen", seen...).Err())
+
+		repo := repository.NewSuggestionRepository(redisClient)
+		err := repo.Index(context.TODO(), []domain.Book{{ID: "1", Title: "Kafka"}})
+		assert.NoError(t, err)
+
+		assert.Equal(t, int64(5000), redisClient.ZCard(context.TODO(), "suggest:vocabulary").Val())
+		assert.Equal(t, float64(1), redisClient.ZScore(context.TODO(), "suggest:vocabulary", "kafka").Val())
+		assert.ErrorIs(t, redisClient.ZScore(context.TODO(), "suggest:vocabulary", "word0").Err(), redis.Nil)
+		assert.Equal(t, float64(100), redisClient.ZScore(context.TODO(), "suggest:vocabulary", "word1").Val())
+	})
+}
+
+func TestGetBook(t *testing.T) {
+	book := domain.Book{ID: "123", Title: "بوف کور", Slug: "the-blind-owl"}
+
+	err := cache.NewBookCacher(redisClient, live.NewDuration(env.BookCacheTTL)).Store(context.TODO(), []domain.Book{book})
+	assert.NoError(t, err)
+	defer redisClient.FlushAll(context.TODO())
+
+	jwt, err := token.GenerateJWT("test", env.AccessTokenSecret, env.AccessTokenExpiry)
+	assert.NoError(t, err)
+
+	for _, path := range []string{"/books/123", "/books/by-slug/the-blind-owl"} {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		assert.NoError(t, err)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+		router.ServeHTTP(w, req)
+
+		res, err := io.ReadAll(w.Body)
+		assert.NoError(t, err)
+
+		response := domain.Book{}
+		err = json.Unmarshal(res, &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, book, response, path)
+		assert.Equal(t, fmt.Sprintf("private, max-age=%d", int(env.BookCacheTTL.Seconds())), w.Header().Get("Cache-Control"), path)
+	}
+}
+
+func TestAuthorBooks(t *testing.T) {
+	books := []domain.Book{
+		{ID: "1", Title: "مسخ", Authors: []domain.Author{{Name: "فرانتس کافکا"}}},
+		{ID: "2", Title: "محاکمه", Authors: []domain.Author{{Name: "فرانتس کافکا"}}},
+	}
+
+	err := cache.NewCacher(redisClient, live.NewDuration(env.CacheTTL)).Store(context.TODO(), "catalog:author:فرانتس کافکا", domain.SearchResult{
+		Books: books,
+		Total: 2,
+	})
+	assert.NoError(t, err)
+	defer redisClient.FlushAll(context.TODO())
+
+	jwt, err := token.GenerateJWT("test", env.AccessTokenSecret, env.AccessTokenExpiry)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/authors/"+url.PathEscape("فرانتس کافکا")+"/books?page=2&size=1", nil)
+	assert.NoError(t, err)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+	router.ServeHTTP(w, req)
+
+	res, err := io.ReadAll(w.Body)
+	assert.NoError(t, err)
+
+	response := domain.SearchResult{}
+	err = json.Unmarshal(res, &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []domain.Book{books[1]}, response.Books)
+	assert.Equal(t, int64(2), response.Total)
+	assert.False(t, response.HasMore)
+}
+
+func TestSearchHistory(t *testing.T) {
+	defer redisClient.FlushAll(context.TODO())
+
+	err := cache.NewCacher(redisClient, live.NewDuration(env.CacheTTL)).Store(context.TODO(), "search:keyword=test&page=1&size=20", domain.SearchResult{
+		Books: []domain.Book{{ID: "1"}},
+		Total: 1,
+	})
+	assert.NoError(t, err)
+
+	jwt, err := token.GenerateJWT("test", env.AccessTokenSecret, env.AccessTokenExpiry)
+	assert.NoError(t, err)
+
+	send := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(method, path, nil)
+		assert.NoError(t, err)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send(http.MethodGet, "/search/book?keyword=test")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send(http.MethodGet, "/me/search-history")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	history := domain.SearchHistory{}
+	err = json.Unmarshal(w.Body.Bytes(), &history)
+	assert.NoError(t, err)
+	assert.True(t, history.Enabled)
+	assert.Len(t, history.Entries, 1)
+	assert.Equal(t, "test", history.Entries[0].Query)
+	assert.Equal(t, int64(1), history.Entries[0].ResultCount)
+
+	w = send(http.MethodDelete, "/me/search-history")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = send(http.MethodGet, "/me/search-history")
+	history = domain.SearchHistory{}
+	err = json.Unmarshal(w.Body.Bytes(), &history)
+	assert.NoError(t, err)
+	assert.Empty(t, history.Entries)
+}
+
+func TestFavorites(t *testing.T) {
+	defer redisClient.FlushAll(context.TODO())
+
+	err := cache.NewBookCacher(redisClient, live.NewDuration(env.BookCacheTTL)).Store(context.TODO(), []domain.Book{
+		{ID: "1", Title: "مسخ"},
+		{ID: "2", Title: "محاکمه"},
+	})
+	assert.NoError(t, err)
+
+	jwt, err := token.GenerateJWT("test", env.AccessTokenSecret, env.AccessTokenExpiry)
+	assert.NoError(t, err)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(method, path, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send(http.MethodPost, "/me/favorites", `{"book_id": "1"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = send(http.MethodPost, "/me/favorites", `{"book_id": "2"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = send(http.MethodPost, "/me/favorites", `{"book_id": "2"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = send(http.MethodDelete, "/me/favorites/1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = send(http.MethodGet, "/me/favorites", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response := domain.FavoritesResponse{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Favorites, 1)
+	assert.Equal(t, "2", response.Favorites[0].Book.ID)
+}
+
+func TestSavedSearches(t *testing.T) {
+	defer redisClient.FlushAll(context.TODO())
+
+	jwt, err := token.GenerateJWT("test", env.AccessTokenSecret, env.AccessTokenExpiry)
+	assert.NoError(t, err)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(method, path, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send(http.MethodPost, "/me/saved-searches", `{"keyword": "کافکا"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	created := domain.SavedSearch{}
+	err = json.Unmarshal(w.Body.Bytes(), &created)
+	assert.NoError(t, err)
+	assert.Equal(t, "کافکا", created.Name)
+
+	seenKey := "saved-searches:seen:test:" + created.ID
+	err = redisClient.ZAdd(context.TODO(), seenKey, redis.Z{Score: 1, Member: "1"}).Err()
+	assert.NoError(t, err)
+
+	w = send(http.MethodPut, "/me/saved-searches/"+created.ID, `{"name": "Kafka", "keyword": "کافکا"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(1), redisClient.Exists(context.TODO(), seenKey).Val())
+
+	w = send(http.MethodPut, "/me/saved-searches/"+created.ID, `{"name": "Kafka", "keyword": "kafka"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(0), redisClient.Exists(context.TODO(), seenKey).Val())
+
+	w = send(http.MethodPut, "/me/saved-searches/"+created.ID, `{"name": "Kafka", "keyword": "کافکا", "webhook_url": "http://127.0.0.1:9/hook"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	linked := domain.SavedSearch{}
+	err = json.Unmarshal(w.Body.Bytes(), &linked)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, linked.WebhookSecret)
+
+	w = send(http.MethodGet, "/webhooks/"+linked.WebhookID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	hook := domain.Webhook{}
+	err = json.Unmarshal(w.Body.Bytes(), &hook)
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, hook.SavedSearchID)
+	assert.Equal(t, "http://127.0.0.1:9/hook", hook.URL)
+
+	w = send(http.MethodGet, "/me/saved-searches", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response := domain.SavedSearchesResponse{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.SavedSearches, 1)
+	assert.Equal(t, "Kafka", response.SavedSearches[0].Name)
+
+	w = send(http.MethodDelete, "/me/saved-searches/"+created.ID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = send(http.MethodGet, "/me/saved-searches/"+created.ID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send(http.MethodGet, "/webhooks/"+linked.WebhookID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send(http.MethodGet, "/me/notifications", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSavedSearchRunLock(t *testing.T) {
+	defer redisClient.FlushAll(context.TODO())
+
+	repo := repository.NewSavedSearchRepository(redisClient, env.SavedSearchesLimit)
+
+	acquired, err := repo.AcquireRunLock(context.TODO(), "first", time.Second)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = repo.AcquireRunLock(context.TODO(), "second", time.Second)
+	assert.NoError(t, err)
+	assert.False(t, acquired)
+
+	renewed, err := repo.RenewRunLock(context.TODO(), "second", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, renewed)
+	assert.LessOrEqual(t, redisClient.PTTL(context.TODO(), "saved-searches:lock").Val(), time.Second)
+
+	renewed, err = repo.RenewRunLock(context.TODO(), "first", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, renewed)
+	assert.Greater(t, redisClient.PTTL(context.TODO(), "saved-searches:lock").Val(), time.Second)
+}
+
+func TestWebhooks(t *testing.T) {
+	defer redisClient.FlushAll(context.TODO())
+
+	received := make(chan *http.Request, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		received <- r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	jwt, err := token.GenerateJWT("new user", env.AccessTokenSecret, env.AccessTokenExpiry)
+	assert.NoError(t, err)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(method, path, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send(http.MethodPost, "/webhooks", fmt.Sprintf(`{"url": %q, "events": ["user.registered"]}`, receiver.URL))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send(http.MethodPost, "/webhooks", fmt.Sprintf(`{"url": %q, "events": ["saved_search.new_results"]}`, receiver.URL))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	created := domain.Webhook{}
+	err = json.Unmarshal(w.Body.Bytes(), &created)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, created.Secret)
+
+	webhookRepository := repository.NewWebhookRepository(redisClient, env.WebhooksLimit)
+	systemSecret := "system webhook secret"
+	err = service.SetupSystemWebhook(context.TODO(), webhookRepository, webhook.NewGuard(env.WebhookAllowPrivate), receiver.URL, systemSecret)
+	assert.NoError(t, err)
+
+	w = send(http.MethodPost, "/login", `{"username": "new user", "password": "password"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(redisClient, env.WebhookDeliveryLogSize, env.WebhookDeliveryRetention)
+	dispatcher := service.NewWebhookDispatcher(webhookRepository, webhookDeliveryRepository, webhook.NewGuard(env.WebhookAllowPrivate), service.WebhookDispatcherConfig{
+		Timeout:     time.Second,
+		MaxAttempts: env.WebhookMaxAttempts,
+		BaseBackoff: env.WebhookRetryBackoff,
+		MaxBackoff:  env.WebhookMaxRetryBackoff,
+		BatchSize:   env.WebhookBatchSize,
+	}, logger)
+
+	n, err := dispatcher.RunOnce(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	req := <-received
+	body, err := io.ReadAll(req.Body)
+	assert.NoError(t, err)
+
+	err = webhook.Verify(systemSecret, req.Header.Get(webhook.TimestampHeader), req.Header.Get(webhook.SignatureHeader), body, time.Minute, time.Now())
+	assert.NoError(t, err)
+
+	event := domain.WebhookEvent{}
+	err = json.Unmarshal(body, &event)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.EventUserRegistered, event.Type)
+	assert.JSONEq(t, `{"username": "new user"}`, string(event.Data))
+
+	systemDeliveries, err := webhookDeliveryRepository.Log(context.TODO(), domain.SystemWebhookID)
+	assert.NoError(t, err)
+	assert.Len(t, systemDeliveries, 1)
+	assert.Equal(t, domain.DeliveryStatusSucceeded, systemDeliveries[0].Status)
+
+	w = send(http.MethodGet, "/webhooks/"+created.ID+"/deliveries", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	deliveries := domain.WebhookDeliveriesResponse{}
+	err = json.Unmarshal(w.Body.Bytes(), &deliveries)
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries.Deliveries)
+
+	w = send(http.MethodGet, "/webhooks/"+domain.SystemWebhookID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send(http.MethodGet, "/webhooks/"+created.ID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), created.Secret)
+	otherJWT, err := token.GenerateJWT("other user", env.AccessTokenSecret, env.AccessTokenExpiry)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodGet, "/webhooks/"+created.ID, nil)
+	assert.NoError(t, err)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", otherJWT))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send(http.MethodDelete, "/webhooks/"+created.ID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	indexed, err := redisClient.SCard(context.TODO(), "webhooks:user:new user").Result()
+	assert.NoError(t, err)
+	assert.Zero(t, indexed)
+
+	limited := repository.NewWebhookRepository(redisClient, 1)
+	err = limited.Create(context.TODO(), domain.Webhook{ID: "first", CreatedBy: "new user"})
+	assert.NoError(t, err)
+	err = limited.Create(context.TODO(), domain.Webhook{ID: "second", CreatedBy: "new user"})
+	assert.ErrorIs(t, err, domain.ErrWebhooksLimitReached)
+
+	webhooks, err := limited.List(context.TODO(), "new user")
+	assert.NoError(t, err)
+	assert.Len(t, webhooks, 1)
+	assert.Equal(t, "first", webhooks[0].ID)
+}
+
+func TestTracing(t *testing.T) {
+	defer redisClient.FlushAll(context.TODO())
+	defer spans.Reset()
+
+	err := cache.NewCacher(redisClient, live.NewDuration(env.CacheTTL)).Store(context.TODO(), "search:keyword=kafka&page=1&size=20",
+		domain.SearchResult{Total: 1, Books: []domain.Book{{ID: "1", Title: "Kafka"}}})
+	assert.NoError(t, err)
+	spans.Reset()
+
+	jwt, err := token.GenerateJWT("test", env.AccessTokenSecret, env.AccessTokenExpiry)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/search/book?keyword=kafka", nil)
+	assert.NoError(t, err)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+	req.Header.Add("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	byName := map[string]tracetest.SpanStub{}
+	for _, span := range spans.GetSpans() {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+		byName[span.Name] = span
+	}
+
+	server, ok := byName["GET /search/book"]
+	assert.True(t, ok)
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+
+	search, ok := byName["SearchService.Search"]
+	assert.True(t, ok)
+	assert.Equal(t, server.SpanContext.SpanID(), search.Parent.SpanID())
+
+	cacheGet, ok := byName["cache.search.get"]
+	assert.True(t, ok)
+	assert.Equal(t, search.SpanContext.SpanID(), cacheGet.Parent.SpanID())
+}
+
+func TestRequestID(t *testing.T) {
+	t.Run("propagated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/me/favorites", nil)
+		assert.NoError(t, err)
+		req.Header.Add("X-Request-ID", "client-request-1")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "client-request-1", w.Header().Get("X-Request-ID"))
+
+		response := domain.ErrorResponse{}
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "client-request-1", response.RequestID)
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/unknown", nil)
+		assert.NoError(t, err)
+		req.Header.Add("X-Request-ID", "invalid id")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		id := w.Header().Get("X-Request-ID")
+		assert.Len(t, id, 32)
+
+		response := domain.ErrorResponse{}
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, id, response.RequestID)
+	})
+}
+
+func TestHealth(t *testing.T) {
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	assert.NoError(t, err)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodGet, "/readyz", nil)
+	assert.NoError(t, err)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response := domain.HealthResponse{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.HealthStatusUp, response.Status)
+	assert.Equal(t, domain.HealthStatusUp, response.Checks["redis"].Status)
+}
+
+func TestRateLimit(t *testing.T) {
+	defer redisClient.FlushAll(context.TODO())
+
+	login := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"test","password":"test"}`))
+		assert.NoError(t, err)
+		req.RemoteAddr = "203.0.113.7:1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := env.RateLimitAuthRequests - 1; i >= 0; i-- {
+		w := login()
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, strconv.Itoa(env.RateLimitAuthRequests), w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, strconv.Itoa(i), w.Header().Get("X-RateLimit-Remaining"))
+	}
+
+	w := login()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	response := domain.ErrorResponse{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Too many requests", response.Message)
+	assert.NotEmpty(t, response.RequestID)
+}